@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/chrlesur/json-ld-converter/internal/eval"
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+func newEvalCmd() *cobra.Command {
+	var dataset, resultFile, label, baseline string
+
+	cmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Score conversions against hand-written gold JSON-LD",
+		Long: `Convert every annotated document of a dataset (a directory or a .zip, .tar.gz or .tgz archive) and compare the result
+with its gold JSON-LD, stored next to the document as <name>` + eval.GoldSuffix + `; documents without one are skipped.
+The report gives the main type accuracy, conversion and validity rates, token cost, and precision, recall and F1
+per type, per property and for the mentions, locations and events lists.
+Save the result with --output and pass it as --baseline to a later run (with another --model, --engine or --instructions)
+to compare both configurations, or use "eval compare".`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := configureLLM(cmd, args); err != nil {
+				return fmt.Errorf("error configuring LLM: %w", err)
+			}
+			var base *eval.Result
+			if baseline != "" {
+				var err error
+				if base, err = eval.LoadResult(baseline); err != nil {
+					return err
+				}
+			}
+
+			cases, missing, err := eval.LoadDataset(dataset, inputFormat)
+			if err != nil {
+				return err
+			}
+			for _, path := range missing {
+				logger.Warning(fmt.Sprintf("Skipping file %s: no gold file %s", path, eval.GoldPath(path)))
+			}
+
+			converter, err := newBatchConverter()
+			if err != nil {
+				return err
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			logger.InitProgress(len(cases))
+			result := eval.Run(ctx, dataset, cases, converter.convert, eval.Options{
+				Label:    label,
+				Validate: converter.pipeline.Validate,
+				Progress: evalProgress,
+			})
+
+			if resultFile != "" {
+				if err := result.WriteJSON(resultFile); err != nil {
+					return err
+				}
+				logger.Info(fmt.Sprintf("Evaluation result written to %s", resultFile))
+			}
+			if err := result.Write(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+			if base != nil {
+				fmt.Fprintln(cmd.OutOrStdout())
+				return eval.Compare(cmd.OutOrStdout(), base, result)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&dataset, "dataset", "d", "", "Dataset directory or archive with documents and their gold JSON-LD")
+	cmd.Flags().StringVarP(&resultFile, "output", "o", "", "JSON file where the evaluation result is saved")
+	cmd.Flags().StringVar(&label, "label", "", "Name of the evaluated configuration, shown in comparisons")
+	cmd.Flags().StringVar(&baseline, "baseline", "", "Saved evaluation result to compare with")
+	cmd.Flags().StringVarP(&engine, "engine", "e", "", "LLM engine to use (overrides config)")
+	cmd.Flags().StringVarP(&model, "model", "m", "", "LLM model to use (overrides config)")
+	cmd.Flags().StringVarP(&instructions, "instructions", "n", "", "Additional instructions for LLM")
+	cmd.MarkFlagRequired("dataset")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "compare <baseline.json> <candidate.json>",
+		Short: "Compare two saved evaluation results",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			base, err := eval.LoadResult(args[0])
+			if err != nil {
+				return err
+			}
+			candidate, err := eval.LoadResult(args[1])
+			if err != nil {
+				return err
+			}
+			return eval.Compare(cmd.OutOrStdout(), base, candidate)
+		},
+	})
+
+	return cmd
+}
+
+// evalProgress affiche l'avancement d'une évaluation
+func evalProgress(done, total int, result eval.DocumentResult) {
+	logger.UpdateDocumentProgress()
+	if result.Error != "" {
+		logger.Error(fmt.Sprintf("[%d/%d] Error converting %s: %s", done, total, result.Path, result.Error))
+		return
+	}
+	logger.Info(fmt.Sprintf("[%d/%d] %s: %s (expected %s)", done, total, result.Path, result.MainType, result.GoldType))
+}
@@ -0,0 +1,510 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/chrlesur/json-ld-converter/internal/config"
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+	"github.com/chrlesur/json-ld-converter/internal/pipeline"
+	"github.com/chrlesur/json-ld-converter/pkg/tokenizer"
+	"github.com/chzyer/readline"
+	"github.com/spf13/cobra"
+)
+
+const replHelp = `Commands:
+  load <file>               Load, parse and segment a file (paths may contain spaces)
+  format [name]             Show or set the input format used by the next load (empty: detect)
+  segments                  List the segments of the loaded file
+  show <n>                  Show segment n and its JSON-LD
+  engine [name]             Show or set the LLM engine (claude, openai, ollama, aiyou)
+  model [name]              Show or set the LLM model
+  convert                   Convert the segments not converted yet
+  rerun <n> [instructions]  Convert segment n again, with optional extra instructions
+  view                      Show the resulting JSON-LD
+  edit                      Edit the resulting JSON-LD in $EDITOR
+  validate                  Check the resulting JSON-LD against Schema.org
+  save [file]               Save the resulting JSON-LD (default is inputfile.jsonld)
+  help                      Show this help
+  quit                      Leave interactive mode`
+
+func newInteractiveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "interactive",
+		Short: "Start interactive mode",
+		Long: `Start a session with line editing and history to load a file, preview its segments,
+choose the engine and model, convert, re-run single segments with extra instructions, edit, validate and save the JSON-LD.
+The Schema.org vocabulary and the LLM client stay loaded between commands.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := configureLLM(cmd, args); err != nil {
+				return fmt.Errorf("error configuring LLM: %w", err)
+			}
+			p, err := pipeline.New(config.Get(), pipeline.Options{
+				InputFormat:  inputFormat,
+				Instructions: instructions,
+				MappingFile:  mappingFile,
+			})
+			if err != nil {
+				return err
+			}
+
+			rl, err := readline.NewEx(&readline.Config{
+				Prompt:          "json-ld> ",
+				HistoryFile:     historyFile(),
+				AutoComplete:    replCompleter(),
+				InterruptPrompt: "^C",
+				EOFPrompt:       "quit",
+			})
+			if err != nil {
+				return fmt.Errorf("error starting interactive mode: %w", err)
+			}
+			defer rl.Close()
+
+			r := &repl{pipeline: p, out: rl.Stdout()}
+			fmt.Fprintln(r.out, "Welcome to interactive mode! Type 'help' for the list of commands.")
+			for {
+				line, err := rl.Readline()
+				if errors.Is(err, readline.ErrInterrupt) {
+					continue
+				}
+				if err != nil {
+					return nil
+				}
+				quit, err := r.execute(line)
+				if err != nil {
+					fmt.Fprintf(r.out, "Error: %v\n", err)
+				}
+				if quit {
+					return nil
+				}
+			}
+		},
+	}
+}
+
+// repl garde l'état du mode interactif d'une commande à l'autre : le pipeline
+// (client LLM et vocabulaire Schema.org chargés une seule fois), la session du
+// fichier chargé et le JSON-LD obtenu, éventuellement modifié à la main
+type repl struct {
+	pipeline *pipeline.Pipeline
+	session  *pipeline.Session
+	path     string
+	result   map[string]interface{}
+	edited   bool
+	out      io.Writer
+}
+
+// execute exécute une ligne de commande ; quit indique la fin de la session
+func (r *repl) execute(line string) (quit bool, err error) {
+	command, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+	arg = strings.TrimSpace(arg)
+
+	switch command {
+	case "":
+		return false, nil
+	case "help", "?":
+		fmt.Fprintln(r.out, replHelp)
+	case "quit", "exit":
+		return true, nil
+	case "load":
+		return false, r.load(arg)
+	case "format":
+		return false, r.format(arg)
+	case "segments":
+		return false, r.segments()
+	case "show":
+		return false, r.show(arg)
+	case "engine":
+		return false, r.engine(arg)
+	case "model":
+		return false, r.model(arg)
+	case "convert":
+		return false, r.convert()
+	case "rerun":
+		return false, r.rerun(arg)
+	case "view":
+		return false, r.view()
+	case "edit":
+		return false, r.edit()
+	case "validate":
+		return false, r.validate()
+	case "save":
+		return false, r.save(arg)
+	default:
+		return false, fmt.Errorf("unknown command %q (type 'help' for the list of commands)", command)
+	}
+	return false, nil
+}
+
+func (r *repl) load(arg string) error {
+	path := unquotePath(arg)
+	if path == "" {
+		return fmt.Errorf("usage: load <file>")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening input file: %w", err)
+	}
+	defer file.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	session, err := r.pipeline.NewSession(ctx, file, path)
+	if err != nil {
+		return err
+	}
+	r.session, r.path, r.result, r.edited = session, path, nil, false
+	fmt.Fprintf(r.out, "Loaded %s: %d segments\n", path, len(session.Segments))
+	return nil
+}
+
+func (r *repl) format(arg string) error {
+	opts := r.pipeline.Options()
+	if arg == "" {
+		if opts.InputFormat == "" {
+			fmt.Fprintln(r.out, "Input format: detected from content")
+		} else {
+			fmt.Fprintf(r.out, "Input format: %s\n", opts.InputFormat)
+		}
+		return nil
+	}
+	if arg == "auto" {
+		arg = ""
+	}
+	opts.InputFormat = arg
+	r.pipeline = r.pipeline.WithOptions(opts)
+	fmt.Fprintln(r.out, "Input format changed; it applies to the next load.")
+	return nil
+}
+
+func (r *repl) segments() error {
+	if r.session == nil {
+		return errNoFile
+	}
+	for i, segment := range r.session.Segments {
+		status := " "
+		if segment.Converted {
+			status = "✓"
+		}
+		label := ""
+		if chapter := segment.Metadata["chapter"]; chapter != "" {
+			label = "[" + chapter + "] "
+		} else if start := segment.Metadata["start_time"]; start != "" {
+			label = "[" + start + "] "
+		}
+		fmt.Fprintf(r.out, "%s %3d. %s%d tokens  %s\n", status, i+1, label, tokenizer.CountTokens(segment.Content), preview(segment.Content, 60))
+	}
+	return nil
+}
+
+func (r *repl) show(arg string) error {
+	i, err := r.segmentIndex(arg)
+	if err != nil {
+		return err
+	}
+	segment := r.session.Segments[i]
+	fmt.Fprintln(r.out, segment.Content)
+	if segment.Converted {
+		data, err := json.MarshalIndent(segment.Results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(r.out, "\nJSON-LD:\n%s\n", data)
+	}
+	return nil
+}
+
+func (r *repl) engine(arg string) error {
+	cfg := config.Get()
+	if arg == "" {
+		fmt.Fprintf(r.out, "Engine: %s\n", cfg.Conversion.Engine)
+		return nil
+	}
+	previous := cfg.Conversion.Engine
+	cfg.Conversion.Engine = arg
+	if err := r.reconnect(); err != nil {
+		cfg.Conversion.Engine = previous
+		return err
+	}
+	fmt.Fprintf(r.out, "Engine set to %s\n", arg)
+	return nil
+}
+
+func (r *repl) model(arg string) error {
+	cfg := config.Get()
+	if arg == "" {
+		fmt.Fprintf(r.out, "Model: %s\n", cfg.Conversion.Model)
+		return nil
+	}
+	previous := cfg.Conversion.Model
+	cfg.Conversion.Model = arg
+	if err := r.reconnect(); err != nil {
+		cfg.Conversion.Model = previous
+		return err
+	}
+	fmt.Fprintf(r.out, "Model set to %s\n", arg)
+	return nil
+}
+
+// reconnect recrée le client LLM après un changement de moteur ou de modèle ;
+// le vocabulaire Schema.org et le contexte d'analyse de la session sont conservés
+func (r *repl) reconnect() error {
+	client, err := llm.NewLLMClient(config.Get())
+	if err != nil {
+		return fmt.Errorf("error creating LLM client: %w", err)
+	}
+	r.pipeline = r.pipeline.WithClient(client)
+	if r.session != nil {
+		r.session.WithPipeline(r.pipeline)
+	}
+	return nil
+}
+
+func (r *repl) convert() error {
+	if r.session == nil {
+		return errNoFile
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var pending []int
+	for i, segment := range r.session.Segments {
+		if !segment.Converted {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		fmt.Fprintln(r.out, "All segments are already converted; use 'rerun <n>' to convert one again.")
+		return nil
+	}
+
+	logger.InitProgress(1)
+	logger.SetTotalChunks(len(pending))
+	for _, i := range pending {
+		if err := r.session.ConvertSegment(ctx, i, ""); err != nil {
+			r.updateResult()
+			return err
+		}
+		logger.UpdateChunkProgress()
+	}
+	r.updateResult()
+	fmt.Fprintf(r.out, "\nConverted %d segments.\n", len(pending))
+	return nil
+}
+
+func (r *repl) rerun(arg string) error {
+	number, extra, _ := strings.Cut(arg, " ")
+	i, err := r.segmentIndex(number)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := r.session.ConvertSegment(ctx, i, strings.TrimSpace(extra)); err != nil {
+		return err
+	}
+	r.updateResult()
+	fmt.Fprintf(r.out, "Segment %d converted again.\n", i+1)
+	return nil
+}
+
+// updateResult reconstitue le JSON-LD à partir des segments convertis ; les
+// modifications faites à la main sont alors perdues
+func (r *repl) updateResult() {
+	if r.edited {
+		fmt.Fprintln(r.out, "Warning: manual edits of the JSON-LD are replaced by the converted segments.")
+	}
+	r.result, r.edited = r.session.Result(), false
+}
+
+func (r *repl) view() error {
+	if r.result == nil {
+		return errNoResult
+	}
+	data, err := json.MarshalIndent(r.result, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(r.out, string(data))
+	return nil
+}
+
+func (r *repl) edit() error {
+	if r.result == nil {
+		return errNoResult
+	}
+	data, err := json.MarshalIndent(r.result, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp("", "json-ld-converter-*.jsonld")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	file.Close()
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.Command(editor, file.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running editor %s: %w", editor, err)
+	}
+
+	data, err = os.ReadFile(file.Name())
+	if err != nil {
+		return err
+	}
+	var edited map[string]interface{}
+	if err := json.Unmarshal(data, &edited); err != nil {
+		return fmt.Errorf("edited JSON-LD is not valid JSON, changes discarded: %w", err)
+	}
+	r.result, r.edited = edited, true
+	return r.validate()
+}
+
+func (r *repl) validate() error {
+	if r.result == nil {
+		return errNoResult
+	}
+	problems := r.pipeline.Validate(r.result)
+	if len(problems) == 0 {
+		fmt.Fprintln(r.out, "JSON-LD is valid.")
+		return nil
+	}
+	fmt.Fprintf(r.out, "%d problems found:\n", len(problems))
+	for _, problem := range problems {
+		fmt.Fprintf(r.out, "  - %s\n", problem)
+	}
+	return nil
+}
+
+func (r *repl) save(arg string) error {
+	if r.result == nil {
+		return errNoResult
+	}
+	path := unquotePath(arg)
+	if path == "" {
+		path = r.path + ".jsonld"
+	}
+	data, err := json.MarshalIndent(r.result, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+	fmt.Fprintf(r.out, "JSON-LD saved to %s\n", path)
+	return nil
+}
+
+var (
+	errNoFile   = errors.New("no file loaded (use 'load <file>')")
+	errNoResult = errors.New("no JSON-LD yet (use 'convert')")
+)
+
+// segmentIndex convertit le numéro de segment saisi (à partir de 1) en indice
+func (r *repl) segmentIndex(arg string) (int, error) {
+	if r.session == nil {
+		return 0, errNoFile
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(r.session.Segments) {
+		return 0, fmt.Errorf("invalid segment number %q (1 to %d)", arg, len(r.session.Segments))
+	}
+	return n - 1, nil
+}
+
+// unquotePath retire les guillemets d'un chemin et développe "~"
+func unquotePath(arg string) string {
+	path := strings.Trim(strings.TrimSpace(arg), `"'`)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
+// preview retourne le début d'un texte sur une seule ligne
+func preview(text string, length int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > length {
+		return string(runes[:length]) + "…"
+	}
+	return text
+}
+
+// historyFile retourne le fichier d'historique des commandes, dans le répertoire
+// personnel de l'utilisateur
+func historyFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".json-ld-converter_history")
+}
+
+// replCompleter complète les commandes, les moteurs et les chemins de fichiers
+func replCompleter() readline.AutoCompleter {
+	return readline.NewPrefixCompleter(
+		readline.PcItem("load", readline.PcItemDynamic(completePath)),
+		readline.PcItem("format"),
+		readline.PcItem("segments"),
+		readline.PcItem("show"),
+		readline.PcItem("engine",
+			readline.PcItem("claude"), readline.PcItem("openai"), readline.PcItem("ollama"), readline.PcItem("aiyou")),
+		readline.PcItem("model"),
+		readline.PcItem("convert"),
+		readline.PcItem("rerun"),
+		readline.PcItem("view"),
+		readline.PcItem("edit"),
+		readline.PcItem("validate"),
+		readline.PcItem("save", readline.PcItemDynamic(completePath)),
+		readline.PcItem("help"),
+		readline.PcItem("quit"),
+	)
+}
+
+// completePath propose les entrées du répertoire du chemin en cours de saisie
+func completePath(line string) []string {
+	_, arg, _ := strings.Cut(strings.TrimLeft(line, " "), " ")
+	dir := filepath.Dir(arg)
+	if strings.HasSuffix(arg, "/") {
+		dir = filepath.Clean(arg)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		name := filepath.Join(dir, entry.Name())
+		if dir == "." && !strings.HasPrefix(arg, "./") {
+			name = entry.Name()
+		}
+		if entry.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -4,18 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/chrlesur/json-ld-converter/internal/batch"
 	"github.com/chrlesur/json-ld-converter/internal/config"
-	"github.com/chrlesur/json-ld-converter/internal/jsonld"
 	"github.com/chrlesur/json-ld-converter/internal/llm"
 	"github.com/chrlesur/json-ld-converter/internal/logger"
 	"github.com/chrlesur/json-ld-converter/internal/parser"
-	"github.com/chrlesur/json-ld-converter/internal/schema"
-	"github.com/chrlesur/json-ld-converter/internal/segmentation"
+	"github.com/chrlesur/json-ld-converter/internal/pipeline"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 )
@@ -27,10 +29,14 @@ var (
 	engine       string
 	model        string
 	instructions string
+	contextFile  string
+	mappingFile  string
+	inputFormat  string
 	silent       bool
 	debug        bool
 	batchMode    bool
 	interactive  bool
+	jsonLines    bool
 )
 
 var rootCmd = &cobra.Command{
@@ -40,13 +46,26 @@ var rootCmd = &cobra.Command{
 }
 
 var convertCmd = &cobra.Command{
-	Use:   "convert [input file]",
+	Use:   "convert [input file|-]",
 	Short: "Convert a file to JSON-LD",
-	Args:  cobra.ExactArgs(1),
+	Long: `Convert a file to JSON-LD.
+With "-" as input file, the document is read from standard input (set --input-format when detection from content is not enough)
+and the JSON-LD is written to standard output unless --output is given; "--output -" also writes to standard output.
+Logs and progress then go to standard error. With --jsonl, each JSON-LD node is written on its own line as soon as its segment is converted.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		inputFile := args[0]
 		if *outputFile == "" {
-			*outputFile = inputFile + ".jsonld"
+			if inputFile == "-" {
+				*outputFile = "-"
+			} else {
+				*outputFile = inputFile + ".jsonld"
+			}
+		}
+		if *outputFile == "-" {
+			// Le JSON-LD occupe la sortie standard : journaux et progression
+			// passent sur la sortie d'erreur
+			logger.SetOutput(os.Stderr)
 		}
 
 		logger.Debug(fmt.Sprintf("Input file: %s", inputFile))
@@ -54,8 +73,10 @@ var convertCmd = &cobra.Command{
 		logger.Debug(fmt.Sprintf("Engine selected: %s", engine))
 
 		// Vérifiez que le fichier d'entrée existe
-		if _, err := os.Stat(inputFile); os.IsNotExist(err) {
-			return fmt.Errorf("input file does not exist: %s", inputFile)
+		if inputFile != "-" {
+			if _, err := os.Stat(inputFile); os.IsNotExist(err) {
+				return fmt.Errorf("input file does not exist: %s", inputFile)
+			}
 		}
 
 		logger.InitProgress(1) // Initialisez la progression pour un seul fichier
@@ -85,10 +106,14 @@ func init() {
 
 	outputFile = new(string)
 	// Flags pour la sous-commande "convert"
-	convertCmd.Flags().StringVarP(outputFile, "output", "o", "", "Output file for JSON-LD (default is inputfile.jsonld)")
+	convertCmd.Flags().StringVarP(outputFile, "output", "o", "", "Output file for JSON-LD, - for standard output (default is inputfile.jsonld, or standard output when reading standard input)")
 	convertCmd.Flags().StringVarP(&engine, "engine", "e", "", "LLM engine to use (overrides config)")
 	convertCmd.Flags().StringVarP(&instructions, "instructions", "n", "", "Additional instructions for LLM")
 	convertCmd.Flags().StringVarP(&model, "model", "m", "", "LLM model to use (overrides config)")
+	convertCmd.Flags().StringVar(&contextFile, "context-file", "", "File used to persist the analysis context after each segment; an interrupted conversion of the same input resumes at the next segment")
+	convertCmd.Flags().BoolVar(&jsonLines, "jsonl", false, "Write JSON Lines: one JSON-LD node per line, as soon as each segment is converted")
+	convertCmd.Flags().StringVar(&mappingFile, "mapping", "", "Column-to-property mapping for tabular inputs (created from the LLM proposal if missing)")
+	rootCmd.PersistentFlags().StringVar(&inputFormat, "input-format", "", "Input format (text, markdown, pdf, html, docx, odt, epub, srt, vtt, transcript, eml, mbox, csv, tsv, xlsx); detected from content if empty")
 
 	// Flags globaux
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yaml)")
@@ -97,8 +122,10 @@ func init() {
 
 	// Flags pour les autres sous-commandes (si nécessaire)
 	rootCmd.AddCommand(newBatchCmd())
+	rootCmd.AddCommand(newWatchCmd())
 	rootCmd.AddCommand(newConfigCmd())
 	rootCmd.AddCommand(newInteractiveCmd())
+	rootCmd.AddCommand(newEvalCmd())
 
 }
 
@@ -124,101 +151,67 @@ func initConfig() {
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
+// convert convertit un fichier, ou l'entrée standard si inputFilePath vaut "-",
+// et écrit le JSON-LD dans outputFilePath, ou sur la sortie standard s'il vaut "-"
 func convert(inputFilePath, outputFilePath string) error {
-
-	cfg := config.Get()
-
-	logger.Debug(fmt.Sprintf("Configuration loaded: %+v", cfg))
-
-	// Création du client LLM
-	client, err := llm.NewLLMClient(cfg)
-	if err != nil {
-		return fmt.Errorf("error creating LLM client: %w", err)
+	opts := pipeline.Options{
+		InputFormat:  inputFormat,
+		Instructions: instructions,
+		ContextFile:  contextFile,
+		MappingFile:  mappingFile,
 	}
-	logger.Debug("LLM client created successfully")
 
-	// Chargement du schéma Schema.org
-	schemaOrg, err := schema.LoadSchemaOrg(cfg.Schema.FilePath)
-	if err != nil {
-		return fmt.Errorf("error loading Schema.org: %w", err)
-	}
-	logger.Debug("Schema.org loaded successfully")
-
-	// Création du parseur de document
-	p, err := parser.NewParser(getFileType(inputFilePath))
-	if err != nil {
-		return fmt.Errorf("error creating parser: %w", err)
-	}
-	logger.Debug(fmt.Sprintf("Parser created for file type: %s", getFileType(inputFilePath)))
-
-	// Lecture et analyse du fichier d'entrée
-	file, err := os.Open(inputFilePath)
-	if err != nil {
-		return fmt.Errorf("error opening input file: %w", err)
+	var output io.Writer = os.Stdout
+	if outputFilePath != "-" && jsonLines {
+		file, err := os.Create(outputFilePath)
+		if err != nil {
+			return fmt.Errorf("error creating output file: %w", err)
+		}
+		defer file.Close()
+		output = file
 	}
-	defer file.Close()
-	logger.Debug("Input file opened successfully")
-
-	doc, err := p.Parse(file)
-	if err != nil {
-		return fmt.Errorf("error parsing document: %w", err)
+	if jsonLines {
+		opts.Stream = jsonLinesWriter(output)
 	}
-	logger.Debug("Document parsed successfully")
 
-	// Création du convertisseur
-	conv := jsonld.NewConverter(schemaOrg, client, cfg.Conversion.MaxTokens, instructions)
-	logger.Debug("Converter created successfully")
-
-	// Segmentation du document
-	segments, err := segmentation.SegmentDocument(doc, cfg.Conversion.MaxTokens)
+	p, err := pipeline.New(config.Get(), opts)
 	if err != nil {
-		return fmt.Errorf("error segmenting document: %w", err)
+		return err
 	}
-	logger.Debug(fmt.Sprintf("Document segmented into %d parts", len(segments)))
-
-	var allResults []map[string]interface{}
-	logger.SetTotalChunks(len(segments))
-	// Conversion de chaque segment en JSON-LD
-	for i, segment := range segments {
-		logger.Debug(fmt.Sprintf("Processing segment %d of %d", i+1, len(segments)))
 
-		segmentDoc := &parser.Document{
-			Content:  segment.Content, // Utilisez segment.Content au lieu de segment directement
-			Metadata: doc.Metadata,
-		}
-
-		ctx := context.Background()
-		jsonLD, err := conv.Convert(ctx, segmentDoc)
+	var input io.Reader = os.Stdin
+	name := ""
+	if inputFilePath != "-" {
+		file, err := os.Open(inputFilePath)
 		if err != nil {
-			return fmt.Errorf("error converting segment %d to JSON-LD: %w", i+1, err)
+			return fmt.Errorf("error opening input file: %w", err)
 		}
-
-		allResults = append(allResults, jsonLD)
+		defer file.Close()
+		input, name = file, inputFilePath
 	}
 
-	// Combinaison de tous les résultats
-	combinedResult := map[string]interface{}{
-		"@context": "https://schema.org",
-		"@graph":   allResults,
-	}
-
-	// Sérialisation du JSON-LD combiné
-	jsonString, err := json.MarshalIndent(combinedResult, "", "  ")
+	data, err := p.ConvertReader(context.Background(), input, name)
 	if err != nil {
-		return fmt.Errorf("error marshaling combined JSON-LD: %w", err)
+		return err
 	}
 
-	// Écriture du résultat dans le fichier de sortie
-	err = os.WriteFile(outputFilePath, jsonString, 0644)
-	if err != nil {
-		return fmt.Errorf("error writing output file: %w", err)
+	// En JSON Lines, les nœuds ont déjà été écrits au fil de la conversion
+	switch {
+	case jsonLines:
+	case outputFilePath == "-":
+		if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("error writing output: %w", err)
+		}
+	default:
+		if err := os.WriteFile(outputFilePath, data, 0644); err != nil {
+			return fmt.Errorf("error writing output file: %w", err)
+		}
 	}
-	logger.Debug("JSON-LD written to output file successfully")
 
 	logger.UpdateDocumentProgress()
 
@@ -227,45 +220,263 @@ func convert(inputFilePath, outputFilePath string) error {
 	return nil
 }
 
+// jsonLinesWriter écrit chaque nœud sur une ligne, avec le contexte Schema.org
+// pour que chaque ligne soit un document JSON-LD autonome
+func jsonLinesWriter(w io.Writer) func(node map[string]interface{}) error {
+	return func(node map[string]interface{}) error {
+		line := make(map[string]interface{}, len(node)+1)
+		for key, value := range node {
+			line[key] = value
+		}
+		if _, ok := line["@context"]; !ok {
+			line["@context"] = "https://schema.org"
+		}
+		data, err := json.Marshal(line)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+}
+
 func newBatchCmd() *cobra.Command {
 	var inputDir, outputDir string
+	var include, exclude []string
+	var retryFailed bool
+	var concurrency int
+	var reportFile, htmlReportFile string
+	var maxFailures int
 
 	cmd := &cobra.Command{
 		Use:   "batch",
 		Short: "Process multiple files in batch mode",
+		Long: `Convert every supported file of a directory tree, or of a .zip, .tar.gz or .tgz archive.
+The output directory mirrors the layout of the source; files without a parser are skipped and listed in the report.
+A manifest kept in the output directory lets a rerun skip files already converted with the same content and options,
+and long documents resume at the next segment after an interruption.
+With --concurrency, several files are converted at once and share the LLM rate limit (conversion.requests_per_minute).
+A JSON report (and optionally an HTML one) describes every file; the command fails when more than --max-failures files fail.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			files, err := ioutil.ReadDir(inputDir)
+			src, err := batch.Open(inputDir, batch.Options{
+				Include: include,
+				Exclude: append(exclude, outputExclusion(inputDir, outputDir)...),
+				Format:  inputFormat,
+			})
 			if err != nil {
-				return fmt.Errorf("error reading input directory: %w", err)
+				return err
 			}
+			defer src.Close()
 
-			logger.InitProgress(len(files)) // Initialisez la progression pour tous les fichiers
+			for _, skipped := range src.Skipped {
+				logger.Warning(fmt.Sprintf("Skipping file %s: %s", skipped.Path, skipped.Reason))
+			}
 
-			for _, file := range files {
-				if file.IsDir() {
-					continue
-				}
-				inputFilePath := filepath.Join(inputDir, file.Name())
-				outputFilePath := filepath.Join(outputDir, file.Name()+".jsonld")
-				logger.Info(fmt.Sprintf("Processing file: %s", inputFilePath))
-				err := convert(inputFilePath, outputFilePath)
-				if err != nil {
-					logger.Error(fmt.Sprintf("Error processing file %s: %v", inputFilePath, err))
+			logger.InitProgress(len(src.Entries)) // Initialisez la progression pour tous les fichiers
+
+			converter, err := newBatchConverter()
+			if err != nil {
+				return err
+			}
+			manifest, err := batch.LoadManifest(outputDir)
+			if err != nil {
+				return err
+			}
+			concurrency = resolveConcurrency(concurrency)
+
+			report := batch.Run(context.Background(), src, outputDir, converter.convert, batch.RunOptions{
+				Manifest:    manifest,
+				OptionsHash: converter.pipeline.OptionsHash(),
+				RetryFailed: retryFailed,
+				Concurrency: concurrency,
+				Progress:    batchProgress,
+			})
+			report.Source = inputDir
+
+			if !silent {
+				report.Write(cmd.OutOrStdout())
+			}
+			if reportFile == "" {
+				reportFile = filepath.Join(outputDir, batch.ReportName)
+			}
+			if err := report.WriteJSON(reportFile); err != nil {
+				return err
+			}
+			logger.Info(fmt.Sprintf("Batch report written to %s", reportFile))
+			if htmlReportFile != "" {
+				if err := report.WriteHTML(htmlReportFile); err != nil {
+					return err
 				}
-				// La progression est mise à jour dans la fonction Convert
+				logger.Info(fmt.Sprintf("HTML report written to %s", htmlReportFile))
+			}
+
+			if report.ExceedsFailures(maxFailures) {
+				return fmt.Errorf("%d files failed to convert (maximum allowed: %d)", len(report.Failed), maxFailures)
 			}
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&inputDir, "input-dir", "d", "", "Input directory for batch processing")
+	cmd.Flags().StringVarP(&inputDir, "input-dir", "d", "", "Input directory or .zip/.tar.gz archive for batch processing")
 	cmd.Flags().StringVarP(&outputDir, "output-dir", "o", "", "Output directory for batch processing")
+	cmd.Flags().StringSliceVar(&include, "include", nil, "Glob patterns of files to convert (e.g. '*.pdf', 'reports/**/*.md')")
+	cmd.Flags().StringSliceVar(&exclude, "exclude", nil, "Glob patterns of files and directories to skip")
+	cmd.Flags().BoolVar(&retryFailed, "retry-failed", false, "Retry files that failed in a previous run")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 0, "Number of files converted at once (default is conversion.num_threads, or 1)")
+	cmd.Flags().StringVar(&reportFile, "report", "", "JSON report file (default is report.json in the output directory)")
+	cmd.Flags().StringVar(&htmlReportFile, "html-report", "", "Static HTML report file with a summary and links to the outputs")
+	cmd.Flags().IntVar(&maxFailures, "max-failures", 0, "Number of failed files tolerated before the command exits with an error (-1 tolerates all failures)")
 	cmd.MarkFlagRequired("input-dir")
 	cmd.MarkFlagRequired("output-dir")
 
 	return cmd
 }
 
+func newWatchCmd() *cobra.Command {
+	var inputDir, outputDir string
+	var include, exclude []string
+	var retryFailed, poll bool
+	var concurrency int
+	var debounce, pollInterval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Convert files as they appear in a directory",
+		Long: `Monitor a directory tree and convert new or modified files as batch does, until interrupted.
+Files are converted once they have not changed for the debounce delay, so partial writes are not picked up.
+State is kept in the batch manifest of the output directory: on restart, only files added or modified meanwhile are converted.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			converter, err := newBatchConverter()
+			if err != nil {
+				return err
+			}
+			manifest, err := batch.LoadManifest(outputDir)
+			if err != nil {
+				return err
+			}
+			concurrency = resolveConcurrency(concurrency)
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			opts := batch.Options{
+				Include: include,
+				Exclude: append(exclude, outputExclusion(inputDir, outputDir)...),
+				Format:  inputFormat,
+			}
+			return batch.Watch(ctx, inputDir, opts, batch.WatchOptions{
+				Debounce:     debounce,
+				PollInterval: pollInterval,
+				Poll:         poll,
+			}, func(ctx context.Context, src *batch.Source) {
+				for _, skipped := range src.Skipped {
+					logger.Warning(fmt.Sprintf("Skipping file %s: %s", skipped.Path, skipped.Reason))
+				}
+				if len(src.Entries) == 0 {
+					return
+				}
+
+				logger.InitProgress(len(src.Entries))
+				report := batch.Run(ctx, src, outputDir, converter.convert, batch.RunOptions{
+					Manifest:    manifest,
+					OptionsHash: converter.pipeline.OptionsHash(),
+					RetryFailed: retryFailed,
+					Concurrency: concurrency,
+					Progress:    batchProgress,
+				})
+				logger.Info(fmt.Sprintf("Converted: %d, unchanged: %d, failed: %d", len(report.Converted), len(report.Unchanged), len(report.Failed)))
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&inputDir, "input-dir", "d", "", "Directory to watch")
+	cmd.Flags().StringVarP(&outputDir, "output-dir", "o", "", "Output directory")
+	cmd.Flags().StringSliceVar(&include, "include", nil, "Glob patterns of files to convert (e.g. '*.pdf', 'reports/**/*.md')")
+	cmd.Flags().StringSliceVar(&exclude, "exclude", nil, "Glob patterns of files and directories to skip")
+	cmd.Flags().BoolVar(&retryFailed, "retry-failed", false, "Retry files that failed before and have not changed since")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 0, "Number of files converted at once (default is conversion.num_threads, or 1)")
+	cmd.Flags().DurationVar(&debounce, "debounce", 2*time.Second, "Delay without modification before a file is converted")
+	cmd.Flags().BoolVar(&poll, "poll", false, "Poll the directory tree instead of using file system notifications")
+	cmd.Flags().DurationVar(&pollInterval, "poll-interval", 5*time.Second, "Polling period when file system notifications are unavailable")
+	cmd.MarkFlagRequired("input-dir")
+	cmd.MarkFlagRequired("output-dir")
+
+	return cmd
+}
+
+// batchConverter convertit les fichiers des commandes batch et watch : le
+// pipeline et le limiteur de débit sont partagés, chaque fichier a son propre
+// convertisseur et mesure sa consommation
+type batchConverter struct {
+	pipeline *pipeline.Pipeline
+	opts     pipeline.Options
+	client   llm.LLMClient
+}
+
+func newBatchConverter() (*batchConverter, error) {
+	opts := pipeline.Options{InputFormat: inputFormat, Instructions: instructions}
+	p, err := pipeline.New(config.Get(), opts)
+	if err != nil {
+		return nil, err
+	}
+	limiter := llm.NewRateLimiter(config.Get().Conversion.RequestsPerMinute)
+	return &batchConverter{pipeline: p, opts: opts, client: llm.WithRateLimit(p.Client(), limiter)}, nil
+}
+
+func (b *batchConverter) convert(ctx context.Context, task batch.Task) (batch.Output, error) {
+	logger.Info(fmt.Sprintf("Processing file: %s", task.Path))
+
+	r, err := task.Open()
+	if err != nil {
+		return batch.Output{}, err
+	}
+	defer r.Close()
+
+	// Le format a été détecté d'après le contenu à l'ouverture de la source (ou
+	// imposé par --input-format)
+	opts := b.opts
+	opts.InputFormat = task.Format
+	opts.CheckpointFile = task.CheckpointFile
+	opts.CheckpointKey = task.CheckpointKey
+	client := llm.NewMeteredClient(b.client)
+	conversion := b.pipeline.WithClient(client).WithOptions(opts)
+	data, err := conversion.ConvertReader(ctx, r, task.Path)
+	return batch.Output{Data: data, Usage: client.Usage(), Stats: conversion.Stats()}, err
+}
+
+// batchProgress affiche l'avancement d'un traitement par lots, dans l'ordre de la source
+func batchProgress(done, total int, result batch.FileResult) {
+	logger.UpdateDocumentProgress()
+	if result.Status == batch.StatusFailed {
+		logger.Error(fmt.Sprintf("[%d/%d] Error processing file %s: %s", done, total, result.Path, result.Error))
+		return
+	}
+	logger.Info(fmt.Sprintf("[%d/%d] %s: %s", done, total, result.Path, result.Status))
+}
+
+// resolveConcurrency retourne le nombre de fichiers convertis simultanément :
+// l'option, à défaut conversion.num_threads, à défaut 1
+func resolveConcurrency(concurrency int) int {
+	if concurrency <= 0 {
+		concurrency = config.Get().Conversion.NumThreads
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	logger.SetParallelDocuments(concurrency > 1)
+	return concurrency
+}
+
+// outputExclusion exclut le répertoire de sortie lorsqu'il se trouve dans le
+// répertoire source, pour ne pas reconvertir les résultats
+func outputExclusion(inputDir, outputDir string) []string {
+	rel, err := filepath.Rel(inputDir, outputDir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	return []string{filepath.ToSlash(rel) + "/**"}
+}
+
 func newConfigCmd() *cobra.Command {
 	var showConfig bool
 	var setKey string
@@ -300,44 +511,6 @@ func newConfigCmd() *cobra.Command {
 	return cmd
 }
 
-func newInteractiveCmd() *cobra.Command {
-	var inputDir, outputDir string
-
-	return &cobra.Command{
-		Use:   "interactive",
-		Short: "Start interactive mode",
-		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println("Welcome to interactive mode!")
-			for {
-				fmt.Print("Enter input file path (or 'quit' to exit): ")
-				var input string
-				fmt.Scanln(&input)
-				if input == "quit" {
-					break
-				}
-
-				// Demander le répertoire de sortie si ce n'est pas déjà fait
-				if outputDir == "" {
-					fmt.Print("Enter output directory: ")
-					fmt.Scanln(&outputDir)
-				}
-
-				// Utiliser filepath.Base pour obtenir le nom du fichier
-				fileName := filepath.Base(input)
-				inputFilePath := filepath.Join(inputDir, fileName)
-				outputFilePath := filepath.Join(outputDir, fileName+".jsonld")
-
-				err := convert(inputFilePath, outputFilePath)
-				if err != nil {
-					logger.Error(fmt.Sprintf("Conversion error: %v", err))
-				} else {
-					logger.Info("Conversion completed successfully.")
-				}
-			}
-		},
-	}
-}
-
 func readAndParseDocument(filePath string, p parser.Parser) (*parser.Document, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -361,22 +534,6 @@ func writeOutput(filePath string, content string) error {
 	return nil
 }
 
-func getFileType(filename string) string {
-	ext := strings.ToLower(filepath.Ext(filename))
-	switch ext {
-	case ".txt":
-		return "text"
-	case ".md":
-		return "markdown"
-	case ".pdf":
-		return "pdf"
-	case ".html", ".htm":
-		return "html"
-	default:
-		return "text" // Par défaut, on suppose que c'est un fichier texte
-	}
-}
-
 func configureLLM(cmd *cobra.Command, args []string) error {
 	cfg := config.Get()
 
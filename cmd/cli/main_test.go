@@ -71,23 +71,37 @@ func TestConfigCommand(t *testing.T) {
 	assert.Contains(t, buf.String(), "max_tokens: 5000")
 }
 
-// TestInteractiveCommand teste le mode interactif
-func TestInteractiveCommand(t *testing.T) {
-	// Simuler une entrée utilisateur
-	input := "test.txt\noutput.jsonld\nquit\n"
-	in := bytes.NewBufferString(input)
-
-	// Capturer la sortie
+// TestInteractiveCommands teste les commandes du mode interactif qui ne
+// nécessitent pas de LLM
+func TestInteractiveCommands(t *testing.T) {
 	out := new(bytes.Buffer)
+	r := &repl{out: out}
 
-	// Créer et exécuter la commande interactive
-	cmd := newInteractiveCmd()
-	cmd.SetIn(in)
-	cmd.SetOut(out)
-	err := cmd.Execute()
+	quit, err := r.execute("help")
+	assert.NoError(t, err)
+	assert.False(t, quit)
+	assert.Contains(t, out.String(), "rerun <n> [instructions]")
 
+	_, err = r.execute("segments")
+	assert.ErrorIs(t, err, errNoFile)
+	_, err = r.execute("view")
+	assert.ErrorIs(t, err, errNoResult)
+	_, err = r.execute("frobnicate")
+	assert.Error(t, err)
+
+	r.result = map[string]interface{}{"@context": "https://schema.org", "@type": "Article"}
+	output := filepath.Join(t.TempDir(), "mon article.jsonld")
+	_, err = r.execute(`save "` + output + `"`)
+	assert.NoError(t, err)
+	_, err = os.Stat(output)
 	assert.NoError(t, err)
-	assert.Contains(t, out.String(), "Welcome to interactive mode!")
-	assert.Contains(t, out.String(), "Enter input file path")
-	assert.Contains(t, out.String(), "Enter output file path")
+
+	quit, err = r.execute("quit")
+	assert.NoError(t, err)
+	assert.True(t, quit)
+}
+
+func TestPreview(t *testing.T) {
+	assert.Equal(t, "Un texte sur…", preview("Un texte\nsur plusieurs lignes", 12))
+	assert.Equal(t, "court", preview("  court ", 12))
 }
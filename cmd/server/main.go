@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+
+	"github.com/chrlesur/json-ld-converter/internal/config"
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+	"github.com/chrlesur/json-ld-converter/internal/pipeline"
+	"github.com/chrlesur/json-ld-converter/internal/review"
+)
+
+// maxUploadSize limite la taille des documents reçus
+var maxUploadSize int64 = 100 << 20
+
+// errEmptyUpload signale une requête sans document
+var errEmptyUpload = errors.New("empty document")
+
+func main() {
+	cfgFile := flag.String("config", "config.yaml", "config file")
+	debug := flag.Bool("debug", false, "Debug mode (verbose logging)")
+	flag.Parse()
+
+	loadErr := config.Load(*cfgFile)
+	cfg := config.Get()
+	cfg.OverrideFromEnv()
+
+	logLevel := logger.INFO
+	if *debug {
+		logLevel = logger.DEBUG
+	}
+	logger.Init(logLevel, cfg.Logging.File)
+	logger.SetDebugMode(*debug)
+	if loadErr != nil {
+		logger.Warning(fmt.Sprintf("Unable to load configuration, using defaults: %v", loadErr))
+	}
+
+	p, err := pipeline.New(cfg, pipeline.Options{})
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	reviewDir := cfg.Review.Dir
+	if reviewDir == "" {
+		reviewDir = "reviews"
+	}
+	store, err := review.NewStore(reviewDir)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	examplesDir := cfg.Examples.Dir
+	if examplesDir == "" {
+		examplesDir = "examples"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/convert", convertHandler(p))
+	(&reviewServer{pipeline: p, store: store, examplesDir: examplesDir}).register(mux)
+
+	port := cfg.Server.Port
+	if port == 0 {
+		port = 8080
+	}
+	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, port)
+	logger.Info(fmt.Sprintf("Server listening on %s", addr))
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		logger.Error(fmt.Sprintf("Server error: %v", err))
+		os.Exit(1)
+	}
+}
+
+// convertHandler convertit le document reçu en JSON-LD. Le document est envoyé
+// soit dans le champ "file" d'un formulaire multipart, soit brut dans le corps
+// de la requête avec son nom dans le paramètre "filename". Le paramètre
+// "format" impose le format d'entrée, détecté sinon d'après le contenu ;
+// "instructions" complète les instructions transmises au LLM.
+func convertHandler(p *pipeline.Pipeline) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, filename, closeBody, err := readUpload(w, r)
+		if err != nil {
+			uploadError(w, err)
+			return
+		}
+		defer closeBody()
+
+		conversion := p.WithOptions(pipeline.Options{
+			InputFormat:  uploadParam(r, "format"),
+			Instructions: uploadParam(r, "instructions"),
+		})
+		docs, err := conversion.Parse(body, filename)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error parsing uploaded document: %v", err))
+			uploadError(w, err)
+			return
+		}
+
+		result, err := conversion.Convert(r.Context(), docs)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Conversion error: %v", err))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/ld+json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			logger.Error(fmt.Sprintf("Error writing response: %v", err))
+		}
+	}
+}
+
+// readUpload retourne le document envoyé dans le champ "file" d'un formulaire
+// multipart ou, pour tout autre type de contenu, le corps brut de la requête
+// avec le nom du paramètre "filename" ; la fonction retournée ferme le fichier
+// du formulaire. Un document vide est refusé (errEmptyUpload).
+func readUpload(w http.ResponseWriter, r *http.Request) (io.Reader, string, func(), error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	var body io.Reader = r.Body
+	filename, closeBody := r.URL.Query().Get("filename"), func() {}
+	// Seul un formulaire multipart est analysé : un corps brut envoyé avec le
+	// type par défaut de curl (application/x-www-form-urlencoded) serait
+	// consommé par l'analyse du formulaire
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, "", nil, err
+		}
+		body, filename, closeBody = file, header.Filename, func() { file.Close() }
+	}
+
+	buffered := bufio.NewReader(body)
+	if _, err := buffered.Peek(1); err != nil {
+		closeBody()
+		if err == io.EOF {
+			return nil, "", nil, errEmptyUpload
+		}
+		return nil, "", nil, err
+	}
+	return buffered, filename, closeBody, nil
+}
+
+// uploadParam retourne un paramètre de l'URL ou du formulaire multipart ; le
+// corps brut d'un autre type de contenu n'est jamais analysé comme formulaire
+func uploadParam(r *http.Request, name string) string {
+	if r.MultipartForm != nil {
+		return r.FormValue(name)
+	}
+	return r.URL.Query().Get(name)
+}
+
+// uploadError répond 413 pour un document trop volumineux, 400 sinon (document
+// vide, format inconnu ou illisible)
+func uploadError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, fmt.Sprintf("document exceeds %d bytes", maxUploadSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
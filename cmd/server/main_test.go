@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chrlesur/json-ld-converter/internal/config"
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+	"github.com/chrlesur/json-ld-converter/internal/pipeline"
+)
+
+// fakeClient répond toujours le même nœud JSON-LD et conserve les prompts reçus
+type fakeClient struct {
+	prompts []string
+}
+
+func (f *fakeClient) Analyze(ctx context.Context, content string, analysisContext *llm.AnalysisContext) (string, *llm.AnalysisContext, error) {
+	f.prompts = append(f.prompts, content)
+	return `{"@type": "WebPage", "name": "Page de test"}`, analysisContext, nil
+}
+
+func newTestPipeline(t *testing.T) (*pipeline.Pipeline, *fakeClient) {
+	cfg := &config.Config{}
+	cfg.Conversion.Engine = "ollama"
+	cfg.Conversion.MaxTokens = 1000
+	cfg.Schema.FilePath = "../../internal/schema/testdata/schema.json"
+	p, err := pipeline.New(cfg, pipeline.Options{})
+	if err != nil {
+		t.Fatalf("pipeline.New() error = %v", err)
+	}
+	client := &fakeClient{}
+	return p.WithClient(client), client
+}
+
+// multipartUpload construit un formulaire dont le champ "file" contient le document
+func multipartUpload(t *testing.T, filename, content string) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(content))
+	form.Close()
+	return &body, form.FormDataContentType()
+}
+
+func TestConvertHandler(t *testing.T) {
+	p, client := newTestPipeline(t)
+	handler := convertHandler(p)
+
+	// Une page HTML enregistrée en .txt est détectée d'après son contenu
+	body, contentType := multipartUpload(t, "page.txt", "<!DOCTYPE html><html><body><p>Bonjour le monde</p></body></html>")
+	req := httptest.NewRequest(http.MethodPost, "/convert", body)
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/ld+json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "Page de test") {
+		t.Errorf("Response should contain the converted node: %s", rec.Body.String())
+	}
+	if len(client.prompts) == 0 {
+		t.Fatal("The LLM was not called")
+	}
+	prompts := strings.Join(client.prompts, "\n")
+	if !strings.Contains(prompts, "Bonjour le monde") || strings.Contains(prompts, "<p>") {
+		t.Errorf("The page should be parsed as HTML before conversion:\n%s", prompts)
+	}
+}
+
+func TestConvertHandlerRawBody(t *testing.T) {
+	p, client := newTestPipeline(t)
+
+	// curl --data-binary envoie le corps brut avec le type par défaut
+	// application/x-www-form-urlencoded : il ne doit pas être lu comme formulaire
+	req := httptest.NewRequest(http.MethodPost, "/convert?filename=notes.txt&instructions=Soyez+bref", strings.NewReader("Marie Curie & Pierre Curie = prix Nobel de physique 1903."))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	convertHandler(p)(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+	prompts := strings.Join(client.prompts, "\n")
+	if !strings.Contains(prompts, "Marie Curie & Pierre Curie = prix Nobel") || !strings.Contains(prompts, "Soyez bref") {
+		t.Errorf("The raw body and the URL instructions should reach the LLM:\n%s", prompts)
+	}
+}
+
+func TestConvertHandlerRejectsUploads(t *testing.T) {
+	p, client := newTestPipeline(t)
+	handler := convertHandler(p)
+
+	defer func(size int64) { maxUploadSize = size }(maxUploadSize)
+	maxUploadSize = 64
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   int
+	}{
+		{"empty body", http.MethodPost, "/convert?filename=notes.txt", "", http.StatusBadRequest},
+		{"oversized body", http.MethodPost, "/convert?filename=notes.txt", strings.Repeat("Un texte trop long. ", 10), http.StatusRequestEntityTooLarge},
+		{"unknown format", http.MethodPost, "/convert?filename=notes.txt&format=foo", "Un texte court.", http.StatusBadRequest},
+		{"wrong method", http.MethodGet, "/convert", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+			if rec.Code != tt.want {
+				t.Errorf("Status = %d, want %d (body: %s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("oversized form", func(t *testing.T) {
+		body, contentType := multipartUpload(t, "notes.txt", strings.Repeat("Un texte trop long. ", 10))
+		req := httptest.NewRequest(http.MethodPost, "/convert", body)
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Status = %d, want %d (body: %s)", rec.Code, http.StatusRequestEntityTooLarge, rec.Body.String())
+		}
+	})
+
+	if len(client.prompts) != 0 {
+		t.Errorf("Rejected uploads should not reach the LLM, got %d requests", len(client.prompts))
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/chrlesur/json-ld-converter/internal/examples"
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+	"github.com/chrlesur/json-ld-converter/internal/pipeline"
+	"github.com/chrlesur/json-ld-converter/internal/review"
+)
+
+//go:embed review.html
+var reviewPage []byte
+
+// reviewServer expose les relectures : création à partir d'un document,
+// consultation, décisions des relecteurs, export des données approuvées et
+// enregistrement des corrections comme exemples
+type reviewServer struct {
+	pipeline    *pipeline.Pipeline
+	store       *review.Store
+	examplesDir string
+}
+
+// register enregistre les routes de relecture et l'interface web (/review/)
+func (s *reviewServer) register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /reviews", s.create)
+	mux.HandleFunc("GET /reviews", s.list)
+	mux.HandleFunc("GET /reviews/{id}", s.get)
+	mux.HandleFunc("POST /reviews/{id}/decisions", s.decide)
+	mux.HandleFunc("GET /reviews/{id}/export", s.export)
+	mux.HandleFunc("POST /reviews/{id}/examples", s.saveExamples)
+	mux.HandleFunc("GET /review/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(reviewPage)
+	})
+}
+
+// create convertit le document reçu (comme /convert) segment par segment et
+// enregistre le résultat comme relecture, chaque nœud étant rattaché à son segment
+func (s *reviewServer) create(w http.ResponseWriter, r *http.Request) {
+	body, filename, closeBody, err := readUpload(w, r)
+	if err != nil {
+		uploadError(w, err)
+		return
+	}
+	defer closeBody()
+
+	conversion := s.pipeline.WithOptions(pipeline.Options{
+		InputFormat:  uploadParam(r, "format"),
+		Instructions: uploadParam(r, "instructions"),
+	})
+	session, err := conversion.NewSession(r.Context(), body, filename)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error parsing uploaded document: %v", err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	segments := make([]review.Segment, len(session.Segments))
+	results := make([][]map[string]interface{}, len(session.Segments))
+	for i, segment := range session.Segments {
+		if err := session.ConvertSegment(r.Context(), i, ""); err != nil {
+			logger.Error(fmt.Sprintf("Conversion error: %v", err))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		segments[i] = review.Segment{
+			Number:    i + 1,
+			Content:   segment.Content,
+			Language:  session.Metadata(i)["language"],
+			Chapter:   segment.Metadata["chapter"],
+			StartTime: segment.Metadata["start_time"],
+		}
+		results[i] = segment.Results
+	}
+
+	rv := review.New(filename, segments, results)
+	if err := s.store.Save(rv); err != nil {
+		logger.Error(fmt.Sprintf("Error saving review: %v", err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	logger.Info(fmt.Sprintf("Review %s created with %d nodes", rv.ID, len(rv.Nodes)))
+	writeJSON(w, http.StatusCreated, rv)
+}
+
+func (s *reviewServer) list(w http.ResponseWriter, r *http.Request) {
+	summaries, err := s.store.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+func (s *reviewServer) get(w http.ResponseWriter, r *http.Request) {
+	rv, err := s.store.Load(r.PathValue("id"))
+	if err != nil {
+		reviewError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rv)
+}
+
+// decide applique une décision, ou une liste de décisions, à une relecture. Le
+// relecteur peut être indiqué par l'en-tête X-Reviewer. Aucune décision n'est
+// enregistrée si l'une d'elles est invalide.
+func (s *reviewServer) decide(w http.ResponseWriter, r *http.Request) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUploadSize)).Decode(&raw); err != nil {
+		http.Error(w, fmt.Sprintf("invalid decision: %v", err), http.StatusBadRequest)
+		return
+	}
+	var decisions []review.Decision
+	if err := json.Unmarshal(raw, &decisions); err != nil {
+		var decision review.Decision
+		if err := json.Unmarshal(raw, &decision); err != nil {
+			http.Error(w, fmt.Sprintf("invalid decision: %v", err), http.StatusBadRequest)
+			return
+		}
+		decisions = []review.Decision{decision}
+	}
+
+	rv, err := s.store.Update(r.PathValue("id"), func(rv *review.Review) error {
+		for _, d := range decisions {
+			if d.Reviewer == "" {
+				d.Reviewer = r.Header.Get("X-Reviewer")
+			}
+			if err := rv.Apply(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		reviewError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rv)
+}
+
+// export retourne le JSON-LD des seules données approuvées
+func (s *reviewServer) export(w http.ResponseWriter, r *http.Request) {
+	rv, err := s.store.Load(r.PathValue("id"))
+	if err != nil {
+		reviewError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/ld+json")
+	if r.URL.Query().Get("download") != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", rv.ID+".jsonld"))
+	}
+	writeJSON(w, http.StatusOK, rv.Export())
+}
+
+// saveExamples enregistre les nœuds corrigés et approuvés comme exemples pour
+// les conversions suivantes
+func (s *reviewServer) saveExamples(w http.ResponseWriter, r *http.Request) {
+	rv, err := s.store.Load(r.PathValue("id"))
+	if err != nil {
+		reviewError(w, err)
+		return
+	}
+	saved := []string{}
+	for _, example := range rv.Examples() {
+		path, err := examples.Save(s.examplesDir, example)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error saving example: %v", err))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		saved = append(saved, path)
+		if lib := s.pipeline.Examples(); lib != nil {
+			lib.Add(example)
+		}
+	}
+	logger.Info(fmt.Sprintf("Saved %d examples from review %s", len(saved), rv.ID))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"saved": saved})
+}
+
+// reviewError répond 404 pour une relecture inconnue, 400 sinon (décision invalide)
+func reviewError(w http.ResponseWriter, err error) {
+	if errors.Is(err, review.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		logger.Error(fmt.Sprintf("Error writing response: %v", err))
+	}
+}
@@ -3,6 +3,8 @@ module github.com/chrlesur/json-ld-converter
 go 1.22.0
 
 require (
+	github.com/chzyer/readline v1.5.1
+	github.com/fsnotify/fsnotify v1.7.0
 	github.com/ledongthuc/pdf v0.0.0-20240201131950-da5b75280b06
 	github.com/piprate/json-gold v0.5.0
 	github.com/sashabaranov/go-openai v1.32.0
@@ -11,6 +13,7 @@ require (
 	github.com/stretchr/testify v1.9.0
 	github.com/yuin/goldmark v1.7.6
 	golang.org/x/net v0.30.0
+	golang.org/x/text v0.19.0
 	gopkg.in/yaml.v2 v2.4.0
 )
 
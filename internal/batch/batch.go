@@ -0,0 +1,228 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/chrlesur/json-ld-converter/internal/jsonld"
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+)
+
+// checkpointDir est le répertoire des points de reprise par segment, dans le
+// répertoire de sortie
+const checkpointDir = ".checkpoints"
+
+// StatusUnchanged désigne un fichier déjà converti avec le même contenu et les
+// mêmes options lors d'une exécution précédente
+const StatusUnchanged = "unchanged"
+
+// Task est un fichier à convertir, avec le point de reprise qui permet de
+// reprendre sa conversion au segment suivant après une interruption
+type Task struct {
+	Entry
+	CheckpointFile string // fichier de reprise des résultats par segment
+	CheckpointKey  string // empreinte du contenu et des options de conversion
+}
+
+// Output est le résultat de la conversion d'un fichier ; en cas d'échec, la
+// consommation et les statistiques restent renseignées
+type Output struct {
+	Data  []byte       // JSON-LD produit
+	Usage llm.Usage    // consommation du LLM
+	Stats jsonld.Stats // déroulement de la conversion
+}
+
+// ConvertFunc convertit un fichier de la source. Elle est appelée
+// simultanément par plusieurs workers lorsque Concurrency dépasse 1.
+type ConvertFunc func(ctx context.Context, task Task) (Output, error)
+
+// RunOptions paramètre l'exécution d'un traitement par lots
+type RunOptions struct {
+	Manifest    *Manifest // état des exécutions précédentes ; nil pour tout convertir
+	OptionsHash string    // empreinte des options de conversion
+	RetryFailed bool      // reconvertit les fichiers en échec lors d'une exécution précédente
+	Concurrency int       // nombre de fichiers convertis simultanément (1 par défaut)
+
+	// Progress est appelée pour chaque fichier traité, dans l'ordre de la
+	// source quel que soit l'ordre d'achèvement des workers
+	Progress func(done, total int, result FileResult)
+}
+
+// OutputPath retourne le fichier de sortie d'un fichier de la source : le
+// répertoire de sortie reproduit l'arborescence de la source
+func OutputPath(outputDir, rel string) string {
+	return filepath.Join(outputDir, filepath.FromSlash(rel)+".jsonld")
+}
+
+// CheckpointPath retourne le point de reprise d'un fichier de la source
+func CheckpointPath(outputDir, rel string) string {
+	return filepath.Join(outputDir, checkpointDir, filepath.FromSlash(rel)+".json")
+}
+
+// Run convertit les fichiers de la source avec un pool de workers et écrit
+// chaque résultat dans le répertoire de sortie. L'échec d'un fichier
+// n'interrompt pas le traitement ; il est consigné dans le rapport. Avec un
+// manifeste, les fichiers déjà convertis avec un contenu et des options
+// identiques sont ignorés, de même que les échecs précédents sauf si
+// RetryFailed est demandé.
+func Run(ctx context.Context, src *Source, outputDir string, convert ConvertFunc, opts RunOptions) *Report {
+	start := time.Now()
+	report := &Report{
+		OutputDir: outputDir,
+		StartedAt: start,
+		Skipped:   append([]Skipped(nil), src.Skipped...),
+	}
+
+	workers := opts.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	total := len(src.Entries)
+	results := make([]FileResult, total)
+	jobs := make(chan int)
+	done := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = process(ctx, src.Entries[i], outputDir, convert, opts)
+				done <- i
+			}
+		}()
+	}
+	go func() {
+		for i := range src.Entries {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+
+	// Les résultats sont restitués dans l'ordre de la source
+	ready := make([]bool, total)
+	next := 0
+	for i := range done {
+		ready[i] = true
+		for next < total && ready[next] {
+			report.add(results[next])
+			if opts.Progress != nil {
+				opts.Progress(next+1, total, results[next])
+			}
+			next++
+		}
+	}
+
+	report.Duration = time.Since(start)
+	return report
+}
+
+// process traite un fichier et met à jour le manifeste
+func process(ctx context.Context, entry Entry, outputDir string, convert ConvertFunc, opts RunOptions) FileResult {
+	result := FileResult{Path: entry.Path}
+	if ctx.Err() != nil {
+		result.Status, result.Error, result.Stage = StatusFailed, ctx.Err().Error(), "interruption"
+		return result
+	}
+
+	contentHash, err := entry.Hash()
+	if err != nil {
+		result.Status, result.Error = StatusFailed, fmt.Sprintf("error reading file: %v", err)
+		return result
+	}
+	outputPath := OutputPath(outputDir, entry.Path)
+
+	if opts.Manifest != nil {
+		if prev, ok := opts.Manifest.Get(entry.Path); ok && prev.ContentHash == contentHash && prev.OptionsHash == opts.OptionsHash {
+			if prev.Status == StatusConverted && fileExists(outputPath) {
+				result.Status, result.Output = StatusUnchanged, outputPath
+				return result
+			}
+			if prev.Status == StatusFailed && !opts.RetryFailed {
+				result.Status, result.Stage = StatusFailed, prev.Stage
+				result.Error = prev.Error + " (previous run, use --retry-failed to retry)"
+				return result
+			}
+		}
+	}
+
+	task := Task{
+		Entry:          entry,
+		CheckpointFile: CheckpointPath(outputDir, entry.Path),
+		CheckpointKey:  contentHash + ":" + opts.OptionsHash,
+	}
+	start := time.Now()
+	output, err := convertTask(ctx, task, outputPath, convert)
+	result.Duration = time.Since(start)
+	result.Usage = output.Usage
+	result.Stats = output.Stats
+	if err != nil {
+		result.Status, result.Error, result.Stage = StatusFailed, err.Error(), errorStage(err)
+	} else {
+		result.Status, result.Output = StatusConverted, outputPath
+	}
+
+	// Un fichier interrompu n'est pas consigné : il reste à convertir, et sa
+	// conversion reprendra au segment suivant lors de la prochaine exécution
+	if opts.Manifest != nil && ctx.Err() == nil {
+		err := opts.Manifest.Record(ManifestEntry{
+			Path:        entry.Path,
+			ContentHash: contentHash,
+			OptionsHash: opts.OptionsHash,
+			Status:      result.Status,
+			Output:      result.Output,
+			Error:       result.Error,
+			Stage:       result.Stage,
+		})
+		if err != nil {
+			logger.Warning(fmt.Sprintf("Unable to update batch manifest: %v", err))
+		}
+	}
+	return result
+}
+
+func convertTask(ctx context.Context, task Task, outputPath string, convert ConvertFunc) (Output, error) {
+	output, err := convert(ctx, task)
+	if err != nil {
+		return output, err
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return output, fmt.Errorf("error creating output directory: %w", err)
+	}
+	if err := os.WriteFile(outputPath, output.Data, 0644); err != nil {
+		return output, fmt.Errorf("error writing output file: %w", err)
+	}
+	return output, nil
+}
+
+// errorStage retourne l'étape de la conversion où une erreur s'est produite
+func errorStage(err error) string {
+	var conversionErr *jsonld.ConversionError
+	var tokenErr *jsonld.TokenLimitError
+	var overflowErr *llm.ContextOverflowError
+	switch {
+	case errors.As(err, &conversionErr):
+		return conversionErr.Stage
+	case errors.As(err, &tokenErr):
+		return "limite de tokens"
+	case errors.As(err, &overflowErr):
+		return "fenêtre de contexte"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "interruption"
+	}
+	return ""
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
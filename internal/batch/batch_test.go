@@ -0,0 +1,413 @@
+package batch
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chrlesur/json-ld-converter/internal/jsonld"
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+)
+
+var testFiles = map[string]string{
+	"readme.md":            "# Titre\n",
+	"notes":                "texte sans extension",
+	"docs/guide.txt":       "guide",
+	"docs/drafts/todo.txt": "brouillon",
+	"docs/image.png":       "\x89PNG\r\n\x1a\n\x00\x00",
+	"data/raw.bin":         "\x00\x01",
+	".git/config":          "[core]",
+}
+
+func writeTree(t *testing.T) string {
+	dir := t.TempDir()
+	for name, content := range testFiles {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func entryPaths(src *Source) []string {
+	var paths []string
+	for _, e := range src.Entries {
+		paths = append(paths, e.Path+":"+e.Format)
+	}
+	return paths
+}
+
+func TestOpenDirectory(t *testing.T) {
+	src, err := Open(writeTree(t), Options{Exclude: []string{"drafts"}})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer src.Close()
+
+	want := []string{"docs/guide.txt:text", "notes:text", "readme.md:markdown"}
+	if got := entryPaths(src); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+	wantSkipped := []Skipped{
+		{Path: "data/raw.bin", Reason: "no parser for .bin files"},
+		{Path: "docs/image.png", Reason: "no parser for .png files"},
+	}
+	if !reflect.DeepEqual(src.Skipped, wantSkipped) {
+		t.Errorf("skipped = %v, want %v", src.Skipped, wantSkipped)
+	}
+}
+
+func TestOpenDetectsMislabeledFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"page.txt":    "<!DOCTYPE html>\n<html><head><title>Page</title></head><body><p>Texte</p></body></html>",
+		"scan.dat":    "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n1 0 obj\n",
+		"courrier.md": "From: alice@example.com\nTo: bob@example.com\nSubject: Bonjour\nDate: Mon, 1 Jan 2024 10:00:00 +0000\n\nCorps du message",
+		// Conteneurs zip dont la partie caractéristique se trouve au-delà des
+		// premiers kilo-octets
+		"rapport":     officeArchive(t, "word/document.xml"),
+		"tableau.dat": officeArchive(t, "xl/workbook.xml"),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer src.Close()
+	want := []string{"courrier.md:eml", "page.txt:html", "rapport:docx", "scan.dat:pdf", "tableau.dat:xlsx"}
+	if got := entryPaths(src); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+	if len(src.Skipped) != 0 {
+		t.Errorf("skipped = %v, want none", src.Skipped)
+	}
+}
+
+// officeArchive construit un conteneur zip dont la partie part suit un média
+// non compressé de 16 Ko
+func officeArchive(t *testing.T, part string) string {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	media, err := zw.CreateHeader(&zip.FileHeader{Name: "media/image1.png", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	media.Write(bytes.Repeat([]byte{0x89, 0x00}, 8192))
+	w, err := zw.Create(part)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("<document/>"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestOpenInclude(t *testing.T) {
+	src, err := Open(writeTree(t), Options{Include: []string{"docs/**/*.txt"}})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	want := []string{"docs/drafts/todo.txt:text", "docs/guide.txt:text"}
+	if got := entryPaths(src); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+}
+
+func TestOpenArchives(t *testing.T) {
+	dir := t.TempDir()
+
+	zipPath := filepath.Join(dir, "corpus.zip")
+	zf, _ := os.Create(zipPath)
+	zw := zip.NewWriter(zf)
+	for _, name := range []string{"a/un.txt", "b.md", "../evasion.txt"} {
+		w, _ := zw.Create(name)
+		io.WriteString(w, "contenu")
+	}
+	zw.Close()
+	zf.Close()
+
+	tarPath := filepath.Join(dir, "corpus.tar.gz")
+	tf, _ := os.Create(tarPath)
+	gw := gzip.NewWriter(tf)
+	tw := tar.NewWriter(gw)
+	for _, name := range []string{"a/un.txt", "b.md"} {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len("contenu")), Typeflag: tar.TypeReg})
+		io.WriteString(tw, "contenu")
+	}
+	tw.Close()
+	gw.Close()
+	tf.Close()
+
+	for _, archive := range []string{zipPath, tarPath} {
+		src, err := Open(archive, Options{})
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", archive, err)
+		}
+		want := []string{"a/un.txt:text", "b.md:markdown"}
+		if got := entryPaths(src); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: entries = %v, want %v", archive, got, want)
+		}
+		r, err := src.Entries[0].Open()
+		if err != nil {
+			t.Fatalf("%s: Entry.Open() error = %v", archive, err)
+		}
+		data, _ := io.ReadAll(r)
+		r.Close()
+		if string(data) != "contenu" {
+			t.Errorf("%s: content = %q", archive, data)
+		}
+		src.Close()
+	}
+}
+
+func TestRun(t *testing.T) {
+	src, err := Open(writeTree(t), Options{Include: []string{"*.txt", "*.md"}})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	outputDir := t.TempDir()
+
+	var progress []string
+	report := Run(context.Background(), src, outputDir, func(ctx context.Context, task Task) (Output, error) {
+		// Le premier fichier se termine en dernier
+		if task.Path == "docs/drafts/todo.txt" {
+			time.Sleep(50 * time.Millisecond)
+			return Output{Usage: llm.Usage{Requests: 1, PromptTokens: 10}}, &jsonld.ConversionError{Stage: "enrichissement", Err: errors.New("échec")}
+		}
+		return Output{
+			Data:  []byte("{}"),
+			Usage: llm.Usage{Requests: 2, PromptTokens: 100, CompletionTokens: 20},
+			Stats: jsonld.Stats{Segments: 2, Types: map[string]int{"Article": 2}, ThingFallbacks: 1},
+		}, nil
+	}, RunOptions{
+		Concurrency: 3,
+		Progress: func(done, total int, result FileResult) {
+			progress = append(progress, fmt.Sprintf("%d/%d %s", done, total, result.Path))
+		},
+	})
+
+	wantProgress := []string{"1/3 docs/drafts/todo.txt", "2/3 docs/guide.txt", "3/3 readme.md"}
+	if !reflect.DeepEqual(progress, wantProgress) {
+		t.Errorf("progress = %v, want %v", progress, wantProgress)
+	}
+	if report.Usage.Requests != 5 || report.Usage.Tokens() != 250 {
+		t.Errorf("usage = %+v", report.Usage)
+	}
+	if report.Stats.Segments != 4 || report.Stats.Types["Article"] != 4 || report.Stats.ThingFallbacks != 2 {
+		t.Errorf("stats = %+v", report.Stats)
+	}
+	if report.Files[0].Stage != "enrichissement" {
+		t.Errorf("failure stage = %q", report.Files[0].Stage)
+	}
+	if !report.ExceedsFailures(0) || report.ExceedsFailures(1) || report.ExceedsFailures(-1) {
+		t.Error("ExceedsFailures() does not honour the threshold")
+	}
+
+	if !reflect.DeepEqual(report.Converted, []string{"docs/guide.txt", "readme.md"}) {
+		t.Errorf("converted = %v", report.Converted)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].Path != "docs/drafts/todo.txt" {
+		t.Errorf("failed = %v", report.Failed)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "docs", "guide.txt.jsonld")); err != nil {
+		t.Errorf("mirrored output missing: %v", err)
+	}
+
+	var out strings.Builder
+	report.Write(&out)
+	if !strings.Contains(out.String(), "docs/drafts/todo.txt  failed") || !strings.Contains(out.String(), "TOTAL") {
+		t.Errorf("report = %q", out.String())
+	}
+}
+
+func TestRunManifest(t *testing.T) {
+	sourceDir := writeTree(t)
+	outputDir := t.TempDir()
+	opts := Options{Include: []string{"*.txt", "*.md"}}
+
+	var converted []string
+	fail := true
+	convert := func(ctx context.Context, task Task) (Output, error) {
+		converted = append(converted, task.Path)
+		if task.CheckpointKey == "" || task.CheckpointFile != CheckpointPath(outputDir, task.Path) {
+			t.Errorf("task %s has no checkpoint", task.Path)
+		}
+		if fail && task.Path == "docs/guide.txt" {
+			return Output{}, errors.New("quota dépassé")
+		}
+		return Output{Data: []byte("{}")}, nil
+	}
+	run := func(optionsHash string, retry bool) *Report {
+		converted = nil
+		manifest, err := LoadManifest(outputDir)
+		if err != nil {
+			t.Fatalf("LoadManifest() error = %v", err)
+		}
+		src, err := Open(sourceDir, opts)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer src.Close()
+		return Run(context.Background(), src, outputDir, convert, RunOptions{Manifest: manifest, OptionsHash: optionsHash, RetryFailed: retry})
+	}
+
+	run("a", false)
+	if len(converted) != 3 {
+		t.Fatalf("first run converted %v", converted)
+	}
+
+	// Les fichiers inchangés ne sont pas reconvertis, ni l'échec sans --retry-failed
+	os.WriteFile(filepath.Join(sourceDir, "readme.md"), []byte("# Nouveau titre\n"), 0644)
+	report := run("a", false)
+	if !reflect.DeepEqual(converted, []string{"readme.md"}) {
+		t.Errorf("second run converted %v, want only the modified file", converted)
+	}
+	if !reflect.DeepEqual(report.Unchanged, []string{"docs/drafts/todo.txt"}) || len(report.Failed) != 1 {
+		t.Errorf("second run report = %+v", report)
+	}
+
+	fail = false
+	run("a", true)
+	if !reflect.DeepEqual(converted, []string{"docs/guide.txt"}) {
+		t.Errorf("retry run converted %v, want only the failed file", converted)
+	}
+
+	// Un changement d'options reconvertit tout
+	run("b", false)
+	if len(converted) != 3 {
+		t.Errorf("run with new options converted %v", converted)
+	}
+
+	manifest, _ := LoadManifest(outputDir)
+	if e, ok := manifest.Get("docs/guide.txt"); !ok || e.Status != StatusConverted || e.OptionsHash != "b" {
+		t.Errorf("manifest entry = %+v", e)
+	}
+}
+
+func TestRunInterrupted(t *testing.T) {
+	sourceDir := writeTree(t)
+	outputDir := t.TempDir()
+	opts := Options{Include: []string{"docs/guide.txt"}}
+
+	var converted []string
+	run := func(interrupt bool) *Report {
+		manifest, err := LoadManifest(outputDir)
+		if err != nil {
+			t.Fatalf("LoadManifest() error = %v", err)
+		}
+		src, err := Open(sourceDir, opts)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer src.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		convert := func(ctx context.Context, task Task) (Output, error) {
+			converted = append(converted, task.Path)
+			if interrupt {
+				// Ctrl-C pendant la conversion
+				cancel()
+				return Output{}, ctx.Err()
+			}
+			return Output{Data: []byte("{}")}, nil
+		}
+		return Run(ctx, src, outputDir, convert, RunOptions{Manifest: manifest, OptionsHash: "a"})
+	}
+
+	if report := run(true); len(report.Failed) != 1 || report.Files[0].Stage != "interruption" {
+		t.Fatalf("interrupted run report = %+v", report)
+	}
+	manifest, _ := LoadManifest(outputDir)
+	if e, ok := manifest.Get("docs/guide.txt"); ok {
+		t.Errorf("An interrupted file should not be recorded in the manifest, got %+v", e)
+	}
+
+	// L'exécution suivante convertit le fichier interrompu sans --retry-failed
+	converted = nil
+	run(false)
+	if !reflect.DeepEqual(converted, []string{"docs/guide.txt"}) {
+		t.Errorf("next run converted %v, want the interrupted file", converted)
+	}
+}
+
+func TestReportFiles(t *testing.T) {
+	dir := t.TempDir()
+	report := &Report{
+		Source:    "corpus.zip",
+		OutputDir: dir,
+		Skipped:   []Skipped{{Path: "image.png", Reason: "no parser for .png files"}},
+		Files: []FileResult{
+			{Path: "a/un.md", Status: StatusConverted, Output: filepath.Join(dir, "a", "un.md.jsonld"), Stats: jsonld.Stats{Segments: 3, Types: map[string]int{"Article": 3}}},
+			{Path: "deux.txt", Status: StatusFailed, Stage: "extraction des propriétés", Error: "réponse <invalide>"},
+		},
+	}
+
+	jsonPath := filepath.Join(dir, ReportName)
+	if err := report.WriteJSON(jsonPath); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	data, _ := os.ReadFile(jsonPath)
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+	first := decoded["files"].([]interface{})[0].(map[string]interface{})
+	if first["segments"] != 3.0 || first["types"].(map[string]interface{})["Article"] != 3.0 {
+		t.Errorf("file entry = %v", first)
+	}
+
+	htmlPath := filepath.Join(dir, "report.html")
+	if err := report.WriteHTML(htmlPath); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	page, _ := os.ReadFile(htmlPath)
+	for _, want := range []string{`<a href="a/un.md.jsonld">a/un.md</a>`, "Article (3)", "réponse &lt;invalide&gt;", "no parser for .png files"} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("HTML report does not contain %q", want)
+		}
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*.pdf", "a/b/rapport.pdf", true},
+		{"*.pdf", "a/b/rapport.md", false},
+		{"docs/*.md", "docs/a.md", true},
+		{"docs/*.md", "docs/sub/a.md", false},
+		{"docs/**/*.md", "docs/a.md", true},
+		{"docs/**/*.md", "docs/sub/deep/a.md", true},
+		{"**/tmp", "a/tmp", true},
+		{"drafts/**", "drafts", true},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ManifestName est le nom du manifeste enregistré dans le répertoire de sortie
+const ManifestName = ".jsonld-manifest.json"
+
+// Statuts d'un fichier dans le manifeste
+const (
+	StatusConverted = "converted"
+	StatusFailed    = "failed"
+)
+
+// ManifestEntry décrit le dernier traitement d'un fichier de la source
+type ManifestEntry struct {
+	Path        string    `json:"path"`
+	ContentHash string    `json:"content_hash"`
+	OptionsHash string    `json:"options_hash"`
+	Status      string    `json:"status"`
+	Output      string    `json:"output,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	Stage       string    `json:"stage,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Manifest consigne l'état de chaque fichier d'un traitement par lots pour
+// qu'une nouvelle exécution ne reconvertisse que ce qui a changé
+type Manifest struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]ManifestEntry
+}
+
+// LoadManifest lit le manifeste du répertoire de sortie ; il est vide si le
+// répertoire n'en contient pas encore
+func LoadManifest(outputDir string) (*Manifest, error) {
+	m := &Manifest{
+		path:    filepath.Join(outputDir, ManifestName),
+		entries: make(map[string]ManifestEntry),
+	}
+	data, err := os.ReadFile(m.path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest: %w", err)
+	}
+
+	var entries []ManifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing manifest %s: %w", m.path, err)
+	}
+	for _, e := range entries {
+		m.entries[e.Path] = e
+	}
+	return m, nil
+}
+
+// Get retourne l'état enregistré d'un fichier
+func (m *Manifest) Get(path string) (ManifestEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[path]
+	return e, ok
+}
+
+// Record enregistre l'état d'un fichier et réécrit aussitôt le manifeste, afin
+// qu'une interruption ne perde que le fichier en cours
+func (m *Manifest) Record(e ManifestEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e.UpdatedAt.IsZero() {
+		e.UpdatedAt = time.Now().UTC()
+	}
+	m.entries[e.Path] = e
+	return m.save()
+}
+
+func (m *Manifest) save() error {
+	entries := make([]ManifestEntry, 0, len(m.entries))
+	for _, e := range m.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling manifest: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return fmt.Errorf("error creating output directory: %w", err)
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("error writing manifest: %w", err)
+	}
+	return os.Rename(tmp, m.path)
+}
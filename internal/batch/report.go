@@ -0,0 +1,236 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chrlesur/json-ld-converter/internal/jsonld"
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+)
+
+// ReportName est le nom du rapport JSON enregistré par défaut dans le
+// répertoire de sortie
+const ReportName = "report.json"
+
+// FileResult décrit le traitement d'un fichier
+type FileResult struct {
+	Path     string        `json:"path"`
+	Status   string        `json:"status"`
+	Output   string        `json:"output,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Stage    string        `json:"stage,omitempty"` // étape de la conversion en échec
+	Duration time.Duration `json:"duration_ns"`
+	Usage    llm.Usage     `json:"usage"`
+	jsonld.Stats
+}
+
+// Failure est un fichier dont la conversion a échoué
+type Failure struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// Report récapitule un traitement par lots
+type Report struct {
+	Source    string        `json:"source,omitempty"`
+	OutputDir string        `json:"output_dir"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration_ns"`
+	Converted []string      `json:"converted"`
+	Unchanged []string      `json:"unchanged"`
+	Skipped   []Skipped     `json:"skipped"`
+	Failed    []Failure     `json:"failed"`
+	Files     []FileResult  `json:"files"`
+	Usage     llm.Usage     `json:"usage"`
+	Stats     jsonld.Stats  `json:"stats"`
+}
+
+func (r *Report) add(result FileResult) {
+	switch result.Status {
+	case StatusConverted:
+		r.Converted = append(r.Converted, result.Path)
+	case StatusUnchanged:
+		r.Unchanged = append(r.Unchanged, result.Path)
+	default:
+		r.Failed = append(r.Failed, Failure{Path: result.Path, Error: result.Error})
+	}
+	r.Files = append(r.Files, result)
+	r.Usage.Add(result.Usage)
+	r.Stats.Add(result.Stats)
+}
+
+// Write affiche le tableau récapitulatif : statut, durée, requêtes et tokens de
+// chaque fichier, les fichiers ignorés avec leur motif, puis les totaux
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tSTATUS\tDURATION\tREQUESTS\tTOKENS\tDETAIL")
+	for _, f := range r.Files {
+		detail := f.Error
+		if f.Stage != "" {
+			detail = f.Stage + ": " + detail
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%s\n", f.Path, f.Status, formatDuration(f.Duration), f.Usage.Requests, f.Usage.Tokens(), detail)
+	}
+	for _, s := range r.Skipped {
+		fmt.Fprintf(tw, "%s\tskipped\t-\t-\t-\t%s\n", s.Path, s.Reason)
+	}
+	fmt.Fprintf(tw, "TOTAL\t%d converted, %d unchanged, %d skipped, %d failed\t%s\t%d\t%d\t\n",
+		len(r.Converted), len(r.Unchanged), len(r.Skipped), len(r.Failed),
+		formatDuration(r.Duration), r.Usage.Requests, r.Usage.Tokens())
+	return tw.Flush()
+}
+
+// WriteJSON enregistre le rapport au format JSON
+func (r *Report) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling batch report: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating report directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing batch report: %w", err)
+	}
+	return nil
+}
+
+// WriteHTML enregistre une page HTML statique présentant le résumé du
+// traitement et le détail de chaque fichier, avec un lien vers sa sortie
+func (r *Report) WriteHTML(path string) error {
+	page := htmlReport{Report: r}
+	for _, f := range r.Files {
+		row := htmlRow{FileResult: f, Types: formatTypes(f.Types)}
+		if f.Output != "" {
+			if rel, err := filepath.Rel(filepath.Dir(path), f.Output); err == nil {
+				row.Link = filepath.ToSlash(rel)
+			}
+		}
+		page.Rows = append(page.Rows, row)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating report directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating HTML report: %w", err)
+	}
+	defer file.Close()
+	if err := htmlReportTemplate.Execute(file, page); err != nil {
+		return fmt.Errorf("error writing HTML report: %w", err)
+	}
+	return nil
+}
+
+// ExceedsFailures indique si le nombre d'échecs dépasse le seuil toléré ; un
+// seuil négatif tolère tous les échecs
+func (r *Report) ExceedsFailures(maxFailures int) bool {
+	return maxFailures >= 0 && len(r.Failed) > maxFailures
+}
+
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// formatTypes présente les types détectés par nombre de nœuds décroissant
+func formatTypes(types map[string]int) string {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if types[names[i]] != types[names[j]] {
+			return types[names[i]] > types[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s (%d)", name, types[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+type htmlReport struct {
+	*Report
+	Rows []htmlRow
+}
+
+type htmlRow struct {
+	FileResult
+	Types string
+	Link  string
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"duration": formatDuration,
+	"types":    formatTypes,
+}).Parse(`<!DOCTYPE html>
+<html lang="fr">
+<head>
+<meta charset="utf-8">
+<title>Rapport de conversion JSON-LD</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+.converted { color: #1a7f37; }
+.unchanged { color: #57606a; }
+.failed { color: #cf222e; }
+.summary td:first-child { font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Rapport de conversion JSON-LD</h1>
+<table class="summary">
+{{if .Source}}<tr><td>Source</td><td>{{.Source}}</td></tr>{{end}}
+<tr><td>Début</td><td>{{.StartedAt.Format "2006-01-02 15:04:05"}}</td></tr>
+<tr><td>Durée</td><td>{{duration .Duration}}</td></tr>
+<tr><td>Convertis</td><td class="converted">{{len .Converted}}</td></tr>
+<tr><td>Inchangés</td><td class="unchanged">{{len .Unchanged}}</td></tr>
+<tr><td>Ignorés</td><td>{{len .Skipped}}</td></tr>
+<tr><td>En échec</td><td class="failed">{{len .Failed}}</td></tr>
+<tr><td>Segments</td><td>{{.Stats.Segments}}</td></tr>
+<tr><td>Types détectés</td><td>{{types .Stats.Types}}</td></tr>
+<tr><td>Replis sur Thing</td><td>{{.Stats.ThingFallbacks}}</td></tr>
+<tr><td>Réparations</td><td>{{.Stats.Repairs}}</td></tr>
+<tr><td>Requêtes LLM</td><td>{{.Usage.Requests}}</td></tr>
+<tr><td>Tokens (estimation)</td><td>{{.Usage.Tokens}}</td></tr>
+</table>
+<h2>Fichiers</h2>
+<table>
+<tr><th>Fichier</th><th>Statut</th><th>Étape</th><th>Erreur</th><th>Segments</th><th>Types</th><th>Replis sur Thing</th><th>Réparations</th><th>Tokens</th><th>Durée</th></tr>
+{{range .Rows}}<tr>
+<td>{{if .Link}}<a href="{{.Link}}">{{.Path}}</a>{{else}}{{.Path}}{{end}}</td>
+<td class="{{.Status}}">{{.Status}}</td>
+<td>{{.Stage}}</td>
+<td>{{.Error}}</td>
+<td>{{.Segments}}</td>
+<td>{{.Types}}</td>
+<td>{{.ThingFallbacks}}</td>
+<td>{{.Repairs}}</td>
+<td>{{.Usage.Tokens}}</td>
+<td>{{duration .Duration}}</td>
+</tr>
+{{end}}</table>
+{{if .Skipped}}<h2>Fichiers ignorés</h2>
+<table>
+<tr><th>Fichier</th><th>Motif</th></tr>
+{{range .Skipped}}<tr><td>{{.Path}}</td><td>{{.Reason}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,297 @@
+package batch
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chrlesur/json-ld-converter/internal/parser"
+)
+
+// sniffLen est le nombre d'octets examinés pour distinguer un contenu texte
+// d'un contenu binaire
+const sniffLen = 8192
+
+// Options sélectionne les fichiers d'une source
+type Options struct {
+	Include []string // motifs des fichiers à convertir (tous si vide)
+	Exclude []string // motifs des fichiers et répertoires ignorés
+	Format  string   // format imposé à tous les fichiers ; détecté si vide
+}
+
+// Entry est un fichier à convertir
+type Entry struct {
+	Path   string // chemin relatif à la source, séparé par des "/"
+	Format string // format détecté d'après le contenu et le nom, ou imposé par Options.Format
+	open   func() (io.ReadCloser, error)
+}
+
+// Open ouvre le contenu du fichier
+func (e Entry) Open() (io.ReadCloser, error) {
+	return e.open()
+}
+
+// Hash retourne l'empreinte SHA-256 du contenu du fichier
+func (e Entry) Hash() (string, error) {
+	r, err := e.open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Skipped est un fichier écarté faute de parseur
+type Skipped struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// Source est une arborescence de fichiers à convertir : un répertoire parcouru
+// récursivement ou une archive .zip, .tar.gz ou .tgz
+type Source struct {
+	Entries []Entry
+	Skipped []Skipped
+	closer  io.Closer
+}
+
+// Open parcourt la source et retient les fichiers sélectionnés par les motifs.
+// Les fichiers et répertoires cachés (commençant par un point) sont ignorés.
+func Open(source string, opts Options) (*Source, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("error reading input source: %w", err)
+	}
+
+	s := &Source{}
+	lower := strings.ToLower(source)
+	switch {
+	case info.IsDir():
+		err = s.walkDir(source, opts)
+	case strings.HasSuffix(lower, ".zip"):
+		err = s.readZip(source, opts)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		err = s.readTarGz(source, opts)
+	default:
+		return nil, fmt.Errorf("input source must be a directory or a .zip, .tar.gz or .tgz archive: %s", source)
+	}
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	sort.Slice(s.Entries, func(i, j int) bool { return s.Entries[i].Path < s.Entries[j].Path })
+	sort.Slice(s.Skipped, func(i, j int) bool { return s.Skipped[i].Path < s.Skipped[j].Path })
+	return s, nil
+}
+
+// Close libère l'archive ouverte par la source
+func (s *Source) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+func (s *Source) walkDir(root string, opts Options) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if isHidden(rel) || matchAny(opts.Exclude, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !selected(rel, opts) {
+			return nil
+		}
+		s.add(rel, opts, func() (io.ReadCloser, error) { return os.Open(p) })
+		return nil
+	})
+}
+
+func (s *Source) readZip(archive string, opts Options) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("error opening zip archive: %w", err)
+	}
+	s.closer = zr
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rel, ok := archivePath(f.Name)
+		if !ok {
+			s.Skipped = append(s.Skipped, Skipped{Path: f.Name, Reason: "unsafe path in archive"})
+			continue
+		}
+		if !selected(rel, opts) {
+			continue
+		}
+		s.add(rel, opts, f.Open)
+	}
+	return nil
+}
+
+// readTarGz charge en mémoire les fichiers retenus de l'archive, qui ne peut
+// être lue que séquentiellement
+func (s *Source) readTarGz(archive string, opts Options) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("error opening tar.gz archive: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("error opening tar.gz archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading tar.gz archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		rel, ok := archivePath(header.Name)
+		if !ok {
+			s.Skipped = append(s.Skipped, Skipped{Path: header.Name, Reason: "unsafe path in archive"})
+			continue
+		}
+		if !selected(rel, opts) {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("error reading %s from archive: %w", header.Name, err)
+		}
+		s.add(rel, opts, func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil })
+	}
+}
+
+// add retient un fichier si un parseur peut le lire, et le signale sinon
+func (s *Source) add(rel string, opts Options, open func() (io.ReadCloser, error)) {
+	format := opts.Format
+	if format == "" {
+		var reason string
+		format, reason = entryFormat(rel, open)
+		if format == "" {
+			s.Skipped = append(s.Skipped, Skipped{Path: rel, Reason: reason})
+			return
+		}
+	}
+	s.Entries = append(s.Entries, Entry{Path: rel, Format: format, open: open})
+}
+
+// entryFormat retourne le format d'un fichier d'après son contenu et son nom,
+// comme pour la conversion d'un fichier isolé (voir parser.Detect), ou, s'il
+// doit être ignoré, la raison de son rejet. Un fichier d'extension inconnue
+// n'est retenu que si son contenu a une signature reconnue (un PDF enregistré
+// en .dat ou un DOCX sans extension par exemple).
+func entryFormat(rel string, open func() (io.ReadCloser, error)) (string, string) {
+	r, err := open()
+	if err != nil {
+		return "", fmt.Sprintf("cannot read file: %v", err)
+	}
+	defer r.Close()
+	format, content, err := parser.Detect(r, rel)
+	if err != nil {
+		return "", fmt.Sprintf("cannot read file: %v", err)
+	}
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Sprintf("cannot read file: %v", err)
+	}
+	head = head[:n]
+
+	ext := path.Ext(rel)
+	switch {
+	case parser.FormatForExtension(rel) != "":
+		return format, ""
+	case ext != "" && format == "text":
+		return "", fmt.Sprintf("no parser for %s files", strings.ToLower(ext))
+	case format != "text" || isText(head):
+		return format, ""
+	}
+	return "", "no parser for binary content"
+}
+
+// isText indique si un contenu est du texte : sans octet nul, sauf en UTF-16
+func isText(head []byte) bool {
+	if bytes.HasPrefix(head, []byte{0xFF, 0xFE}) || bytes.HasPrefix(head, []byte{0xFE, 0xFF}) {
+		return true
+	}
+	return !bytes.Contains(head, []byte{0})
+}
+
+// archivePath normalise le chemin d'un fichier d'archive et refuse ceux qui
+// sortiraient de l'arborescence
+func archivePath(name string) (string, bool) {
+	rel := path.Clean(strings.TrimPrefix(strings.ReplaceAll(name, "\\", "/"), "./"))
+	if rel == "." || path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
+
+// selected indique si un fichier est retenu par les motifs d'inclusion et
+// d'exclusion ; un fichier dont un répertoire parent est exclu ou caché est
+// écarté
+func selected(rel string, opts Options) bool {
+	if isHidden(rel) {
+		return false
+	}
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		if matchAny(opts.Exclude, dir) {
+			return false
+		}
+	}
+	if matchAny(opts.Exclude, rel) {
+		return false
+	}
+	return len(opts.Include) == 0 || matchAny(opts.Include, rel)
+}
+
+// isHidden indique si un élément du chemin commence par un point
+func isHidden(rel string) bool {
+	for _, part := range strings.Split(rel, "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,261 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchOptions paramètre la surveillance d'une arborescence
+type WatchOptions struct {
+	Debounce     time.Duration // délai sans modification avant qu'un fichier soit converti (2 s par défaut)
+	PollInterval time.Duration // période de scrutation sans notifications du système (5 s par défaut)
+	Poll         bool          // scrute l'arborescence au lieu d'utiliser les notifications du système
+}
+
+// WatchFunc traite les fichiers nouveaux ou modifiés d'une arborescence
+type WatchFunc func(ctx context.Context, src *Source)
+
+// fileState est l'état observé d'un fichier en attente de conversion
+type fileState struct {
+	size    int64
+	modTime time.Time
+	since   time.Time // date de la dernière modification observée
+}
+
+// Watch surveille l'arborescence root jusqu'à l'annulation du contexte. Les
+// fichiers déjà présents sont d'abord traités (le manifeste évite de
+// reconvertir ceux qui n'ont pas changé), puis chaque fichier créé ou modifié
+// est transmis à handle une fois qu'il n'a plus changé pendant Debounce, ce qui
+// écarte les fichiers en cours d'écriture. Les notifications du système
+// (inotify sous Linux) sont utilisées lorsqu'elles sont disponibles, sinon
+// l'arborescence est scrutée périodiquement. La surveillance commence avant le
+// traitement initial : les fichiers modifiés pendant celui-ci sont traités
+// ensuite.
+func Watch(ctx context.Context, root string, opts Options, wopts WatchOptions, handle WatchFunc) error {
+	if wopts.Debounce <= 0 {
+		wopts.Debounce = 2 * time.Second
+	}
+	if wopts.PollInterval <= 0 {
+		wopts.PollInterval = 5 * time.Second
+	}
+
+	src, err := Open(root, opts)
+	if err != nil {
+		return err
+	}
+
+	w := &watcher{
+		root:     root,
+		opts:     opts,
+		debounce: wopts.Debounce,
+		pending:  make(map[string]*fileState),
+		known:    snapshot(root, root, opts),
+	}
+
+	var notifier *fsnotify.Watcher
+	if !wopts.Poll {
+		notifier, err = w.startNotifier()
+		if err != nil {
+			logger.Warning(fmt.Sprintf("File system notifications unavailable (%v), polling every %s", err, wopts.PollInterval))
+		}
+	}
+
+	handle(ctx, src)
+	src.Close()
+	var events chan fsnotify.Event
+	var errs chan error
+	var poll <-chan time.Time
+	if notifier != nil {
+		defer notifier.Close()
+		events, errs = notifier.Events, notifier.Errors
+		logger.Info(fmt.Sprintf("Watching %s for new or modified files", root))
+	} else {
+		ticker := time.NewTicker(wopts.PollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+		logger.Info(fmt.Sprintf("Polling %s every %s for new or modified files", root, wopts.PollInterval))
+	}
+
+	tick := time.NewTicker(wopts.Debounce / 4)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-events:
+			w.handleEvent(notifier, event)
+		case err := <-errs:
+			logger.Warning(fmt.Sprintf("File system notification error: %v", err))
+		case <-poll:
+			w.poll()
+		case now := <-tick.C:
+			if ready := w.ready(now); len(ready) > 0 {
+				src := openPaths(root, ready, opts)
+				handle(ctx, src)
+				src.Close()
+			}
+		}
+	}
+}
+
+type watcher struct {
+	root     string
+	opts     Options
+	debounce time.Duration
+	pending  map[string]*fileState
+	known    map[string]fileState // état des fichiers lors de la dernière scrutation
+}
+
+// startNotifier abonne chaque répertoire de l'arborescence aux notifications
+func (w *watcher) startNotifier() (*fsnotify.Watcher, error) {
+	notifier, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.addDirs(notifier, w.root); err != nil {
+		notifier.Close()
+		return nil, err
+	}
+	return notifier, nil
+}
+
+// addDirs abonne un répertoire et ses sous-répertoires non exclus
+func (w *watcher) addDirs(notifier *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if p != w.root {
+			rel := w.rel(p)
+			if isHidden(rel) || matchAny(w.opts.Exclude, rel) {
+				return filepath.SkipDir
+			}
+		}
+		return notifier.Add(p)
+	})
+}
+
+func (w *watcher) handleEvent(notifier *fsnotify.Watcher, event fsnotify.Event) {
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) && !event.Has(fsnotify.Rename) && !event.Has(fsnotify.Chmod) {
+		return
+	}
+	info, err := os.Stat(event.Name)
+	if err != nil {
+		return // fichier supprimé ou renommé depuis
+	}
+	if info.IsDir() {
+		// Un nouveau répertoire est surveillé et les fichiers qu'il contient déjà
+		// sont pris en compte
+		if event.Has(fsnotify.Create) {
+			if err := w.addDirs(notifier, event.Name); err != nil {
+				logger.Warning(fmt.Sprintf("Unable to watch %s: %v", event.Name, err))
+			}
+			for rel, state := range snapshot(w.root, event.Name, w.opts) {
+				w.touch(rel, state)
+			}
+		}
+		return
+	}
+	rel := w.rel(event.Name)
+	if info.Mode().IsRegular() && selected(rel, w.opts) {
+		w.touch(rel, fileState{size: info.Size(), modTime: info.ModTime()})
+	}
+}
+
+// poll compare l'arborescence à la scrutation précédente
+func (w *watcher) poll() {
+	current := snapshot(w.root, w.root, w.opts)
+	for rel, state := range current {
+		if prev, ok := w.known[rel]; !ok || prev.size != state.size || !prev.modTime.Equal(state.modTime) {
+			w.touch(rel, state)
+		}
+	}
+	w.known = current
+}
+
+// touch met un fichier en attente ; le délai repart de zéro à chaque modification
+func (w *watcher) touch(rel string, state fileState) {
+	state.since = time.Now()
+	w.pending[rel] = &state
+}
+
+// ready retourne les fichiers en attente qui n'ont pas changé depuis Debounce
+func (w *watcher) ready(now time.Time) []string {
+	var ready []string
+	for rel, state := range w.pending {
+		info, err := os.Stat(filepath.Join(w.root, filepath.FromSlash(rel)))
+		if err != nil {
+			delete(w.pending, rel)
+			continue
+		}
+		if info.Size() != state.size || !info.ModTime().Equal(state.modTime) {
+			state.size, state.modTime, state.since = info.Size(), info.ModTime(), now
+			continue
+		}
+		if now.Sub(state.since) >= w.debounce {
+			ready = append(ready, rel)
+			delete(w.pending, rel)
+		}
+	}
+	sort.Strings(ready)
+	return ready
+}
+
+func (w *watcher) rel(p string) string {
+	rel, err := filepath.Rel(w.root, p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// snapshot relève la taille et la date de modification des fichiers retenus
+// du répertoire dir de l'arborescence root ; les chemins, comme les motifs
+// Include et Exclude, sont relatifs à root
+func snapshot(root, dir string, opts Options) map[string]fileState {
+	states := make(map[string]fileState)
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if isHidden(rel) || matchAny(opts.Exclude, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !selected(rel, opts) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			states[rel] = fileState{size: info.Size(), modTime: info.ModTime()}
+		}
+		return nil
+	})
+	return states
+}
+
+// openPaths construit une source limitée à quelques fichiers d'un répertoire
+func openPaths(root string, rels []string, opts Options) *Source {
+	s := &Source{}
+	for _, rel := range rels {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		s.add(rel, opts, func() (io.ReadCloser, error) { return os.Open(p) })
+	}
+	return s
+}
@@ -0,0 +1,156 @@
+package batch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	for _, poll := range []bool{false, true} {
+		root := t.TempDir()
+		os.WriteFile(filepath.Join(root, "existant.txt"), []byte("déjà là"), 0644)
+
+		var mu sync.Mutex
+		var seen [][]string
+		calls := make(chan struct{}, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- Watch(ctx, root, Options{Exclude: []string{"*.tmp"}}, WatchOptions{
+				Debounce:     100 * time.Millisecond,
+				PollInterval: 20 * time.Millisecond,
+				Poll:         poll,
+			}, func(ctx context.Context, src *Source) {
+				var paths []string
+				for _, e := range src.Entries {
+					paths = append(paths, e.Path)
+				}
+				mu.Lock()
+				seen = append(seen, paths)
+				mu.Unlock()
+				calls <- struct{}{}
+			})
+		}()
+
+		waitCall := func() {
+			select {
+			case <-calls:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("poll=%v: handler not called, seen %v", poll, seen)
+			}
+		}
+		waitCall() // traitement initial
+
+		os.MkdirAll(filepath.Join(root, "sous"), 0755)
+		time.Sleep(50 * time.Millisecond)
+		os.WriteFile(filepath.Join(root, "ignoré.tmp"), []byte("x"), 0644)
+		path := filepath.Join(root, "sous", "nouveau.md")
+		os.WriteFile(path, []byte("# Début"), 0644)
+		// Écriture en plusieurs fois : le fichier n'est converti qu'une fois stabilisé
+		for i := 0; i < 3; i++ {
+			time.Sleep(40 * time.Millisecond)
+			f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+			f.WriteString("\nsuite")
+			f.Close()
+		}
+		waitCall()
+
+		cancel()
+		if err := <-done; err != nil {
+			t.Fatalf("poll=%v: Watch() error = %v", poll, err)
+		}
+
+		mu.Lock()
+		if len(seen) != 2 || len(seen[0]) != 1 || seen[0][0] != "existant.txt" || len(seen[1]) != 1 || seen[1][0] != "sous/nouveau.md" {
+			t.Errorf("poll=%v: handled %v", poll, seen)
+		}
+		mu.Unlock()
+	}
+}
+
+// startWatch lance Watch et transmet les chemins de chaque appel du traitement ;
+// initial est appelée pendant le traitement initial
+func startWatch(t *testing.T, root string, opts Options, poll bool, initial func()) (<-chan []string, func()) {
+	calls := make(chan []string, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	first := true
+	go func() {
+		done <- Watch(ctx, root, opts, WatchOptions{
+			Debounce:     100 * time.Millisecond,
+			PollInterval: 20 * time.Millisecond,
+			Poll:         poll,
+		}, func(ctx context.Context, src *Source) {
+			if first && initial != nil {
+				initial()
+			}
+			first = false
+			var paths []string
+			for _, e := range src.Entries {
+				paths = append(paths, e.Path)
+			}
+			calls <- paths
+		})
+	}()
+	return calls, func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Watch() error = %v", err)
+		}
+	}
+}
+
+func waitPaths(t *testing.T, calls <-chan []string) []string {
+	select {
+	case paths := <-calls:
+		return paths
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler not called")
+		return nil
+	}
+}
+
+func TestWatchDuringInitialPass(t *testing.T) {
+	for _, poll := range []bool{false, true} {
+		root := t.TempDir()
+		os.WriteFile(filepath.Join(root, "existant.txt"), []byte("déjà là"), 0644)
+
+		// Un fichier déposé pendant une longue première conversion
+		calls, stop := startWatch(t, root, Options{}, poll, func() {
+			os.WriteFile(filepath.Join(root, "pendant.txt"), []byte("nouveau"), 0644)
+			time.Sleep(50 * time.Millisecond)
+		})
+		if paths := waitPaths(t, calls); !reflect.DeepEqual(paths, []string{"existant.txt"}) {
+			t.Errorf("poll=%v: initial pass handled %v", poll, paths)
+		}
+		if paths := waitPaths(t, calls); !reflect.DeepEqual(paths, []string{"pendant.txt"}) {
+			t.Errorf("poll=%v: file created during the initial pass handled as %v", poll, paths)
+		}
+		stop()
+	}
+}
+
+func TestWatchNewDirectory(t *testing.T) {
+	root := t.TempDir()
+	calls, stop := startWatch(t, root, Options{Include: []string{"rapports/**/*.md"}}, false, nil)
+	defer stop()
+	waitPaths(t, calls)
+
+	// Un répertoire déplacé dans l'arborescence avec ses fichiers : les motifs
+	// portent sur le chemin depuis la racine
+	staging := t.TempDir()
+	os.MkdirAll(filepath.Join(staging, "rapports", "2024"), 0755)
+	os.WriteFile(filepath.Join(staging, "rapports", "2024", "bilan.md"), []byte("# Bilan"), 0644)
+	os.WriteFile(filepath.Join(staging, "rapports", "notes.txt"), []byte("ignoré"), 0644)
+	if err := os.Rename(filepath.Join(staging, "rapports"), filepath.Join(root, "rapports")); err != nil {
+		t.Skipf("rename across directories unavailable: %v", err)
+	}
+	if paths := waitPaths(t, calls); !reflect.DeepEqual(paths, []string{"rapports/2024/bilan.md"}) {
+		t.Errorf("new directory handled as %v", paths)
+	}
+}
@@ -25,6 +25,11 @@ type Config struct {
 		Engine                 string `yaml:"engine"`
 		Model                  string `yaml:"model"`
 		ContextSize            int    `yaml:"context_size"`
+		MaxOutputTokens        int    `yaml:"max_output_tokens"`
+		OverflowStrategy       string `yaml:"overflow_strategy"`
+		ContextBudget          int    `yaml:"context_budget"`
+		SummaryInterval        int    `yaml:"summary_interval"`
+		RequestsPerMinute      int    `yaml:"requests_per_minute"`
 		Timeout                int    `yaml:"timeout"`
 		OllamaHost             string `yaml:"ollama_host"`
 		OllamaPort             string `yaml:"ollama_port"`
@@ -39,9 +44,21 @@ type Config struct {
 		FilePath string `yaml:"file_path"`
 	} `yaml:"schema"`
 	Segmentation struct {
-		MaxTokens       int `yaml:"max_tokens"`
-		TargetBatchSize int `yaml:"target_batch_size"`
+		Strategy            string  `yaml:"strategy"`
+		MaxTokens           int     `yaml:"max_tokens"`
+		TargetBatchSize     int     `yaml:"target_batch_size"`
+		Overlap             int     `yaml:"overlap"`
+		EmbeddingEngine     string  `yaml:"embedding_engine"`
+		EmbeddingModel      string  `yaml:"embedding_model"`
+		SimilarityThreshold float64 `yaml:"similarity_threshold"`
 	} `yaml:"segmentation"`
+	Review struct {
+		Dir string `yaml:"dir"`
+	} `yaml:"review"`
+	Examples struct {
+		Dir       string `yaml:"dir"`
+		MaxTokens int    `yaml:"max_tokens"`
+	} `yaml:"examples"`
 }
 
 var (
@@ -97,6 +114,18 @@ func (c *Config) OverrideFromEnv() {
 	if contextSize := os.Getenv("CONTEXT_SIZE"); contextSize != "" {
 		fmt.Sscanf(contextSize, "%d", &c.Conversion.ContextSize)
 	}
+	if maxOutputTokens := os.Getenv("MAX_OUTPUT_TOKENS"); maxOutputTokens != "" {
+		fmt.Sscanf(maxOutputTokens, "%d", &c.Conversion.MaxOutputTokens)
+	}
+	if overflowStrategy := os.Getenv("OVERFLOW_STRATEGY"); overflowStrategy != "" {
+		c.Conversion.OverflowStrategy = overflowStrategy
+	}
+	if contextBudget := os.Getenv("CONTEXT_BUDGET"); contextBudget != "" {
+		fmt.Sscanf(contextBudget, "%d", &c.Conversion.ContextBudget)
+	}
+	if requestsPerMinute := os.Getenv("REQUESTS_PER_MINUTE"); requestsPerMinute != "" {
+		fmt.Sscanf(requestsPerMinute, "%d", &c.Conversion.RequestsPerMinute)
+	}
 	if timeout := os.Getenv("CONVERSION_TIMEOUT"); timeout != "" {
 		fmt.Sscanf(timeout, "%d", &c.Conversion.Timeout)
 	}
@@ -109,9 +138,18 @@ func (c *Config) OverrideFromEnv() {
 	if aiyouAssistantID := os.Getenv("AIYOU_ASSISTANT_ID"); aiyouAssistantID != "" {
 		c.Conversion.AIYOUAssistantID = aiyouAssistantID
 	}
+	if strategy := os.Getenv("SEGMENTATION_STRATEGY"); strategy != "" {
+		c.Segmentation.Strategy = strategy
+	}
 	if schemaVersion := os.Getenv("SCHEMA_VERSION"); schemaVersion != "" {
 		c.Schema.Version = schemaVersion
 	}
+	if reviewDir := os.Getenv("REVIEW_DIR"); reviewDir != "" {
+		c.Review.Dir = reviewDir
+	}
+	if examplesDir := os.Getenv("EXAMPLES_DIR"); examplesDir != "" {
+		c.Examples.Dir = examplesDir
+	}
 }
 
 func Set(key, value string) error {
@@ -0,0 +1,193 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/chrlesur/json-ld-converter/internal/batch"
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+)
+
+// GoldSuffix termine le nom du JSON-LD de référence d'un document du jeu
+// d'évaluation : rapport.pdf est annoté par rapport.gold.jsonld, dans le même
+// répertoire
+const GoldSuffix = ".gold.jsonld"
+
+// Case est un document du jeu d'évaluation et son annotation de référence
+type Case struct {
+	batch.Entry
+	Gold map[string]interface{}
+}
+
+// LoadDataset lit un jeu d'évaluation : un répertoire ou une archive (comme pour
+// batch) dont chaque document est accompagné de son JSON-LD de référence. Les
+// documents sans référence sont ignorés et retournés à part.
+func LoadDataset(dataset, format string) ([]Case, []string, error) {
+	docs, err := batch.Open(dataset, batch.Options{Exclude: []string{"*" + GoldSuffix}, Format: format})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer docs.Close()
+	// Les références sont lues comme du texte quelle que soit leur extension
+	golds, err := batch.Open(dataset, batch.Options{Include: []string{"*" + GoldSuffix}, Format: "text"})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer golds.Close()
+
+	gold := make(map[string]batch.Entry, len(golds.Entries))
+	for _, entry := range golds.Entries {
+		gold[entry.Path] = entry
+	}
+
+	var cases []Case
+	var missing []string
+	for _, entry := range docs.Entries {
+		goldEntry, ok := gold[GoldPath(entry.Path)]
+		if !ok {
+			missing = append(missing, entry.Path)
+			continue
+		}
+		doc, err := readGold(goldEntry)
+		if err != nil {
+			return nil, nil, err
+		}
+		cases = append(cases, Case{Entry: entry, Gold: doc})
+	}
+	if len(cases) == 0 {
+		return nil, missing, fmt.Errorf("no annotated document in %s (gold files end with %s)", dataset, GoldSuffix)
+	}
+	return cases, missing, nil
+}
+
+// GoldPath retourne le chemin de la référence d'un document
+func GoldPath(rel string) string {
+	return strings.TrimSuffix(rel, path.Ext(rel)) + GoldSuffix
+}
+
+func readGold(entry batch.Entry) (map[string]interface{}, error) {
+	r, err := entry.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error reading gold file %s: %w", entry.Path, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading gold file %s: %w", entry.Path, err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing gold file %s: %w", entry.Path, err)
+	}
+	return doc, nil
+}
+
+// DocumentResult est l'évaluation d'un document
+type DocumentResult struct {
+	Path        string        `json:"path"`
+	Error       string        `json:"error,omitempty"`
+	MainType    string        `json:"main_type,omitempty"` // type du premier nœud produit
+	GoldType    string        `json:"gold_type"`           // type du premier nœud de la référence
+	TypeCorrect bool          `json:"type_correct"`
+	Valid       bool          `json:"valid"`
+	Problems    []string      `json:"problems,omitempty"` // problèmes relevés par la validation
+	Duration    time.Duration `json:"duration_ns"`
+	Usage       llm.Usage     `json:"usage"`
+}
+
+// Result est l'évaluation d'un jeu de documents par une configuration
+type Result struct {
+	Dataset   string           `json:"dataset"`
+	Label     string           `json:"label,omitempty"` // nom de la configuration évaluée
+	StartedAt time.Time        `json:"started_at"`
+	Duration  time.Duration    `json:"duration_ns"`
+	Documents []DocumentResult `json:"documents"`
+	Scores
+
+	TypeAccuracy   float64   `json:"type_accuracy"`   // part des documents dont le type principal est correct
+	ConversionRate float64   `json:"conversion_rate"` // part des documents convertis sans erreur
+	ValidityRate   float64   `json:"validity_rate"`   // part des documents produisant un JSON-LD valide
+	Usage          llm.Usage `json:"usage"`
+}
+
+// Options paramètre une évaluation
+type Options struct {
+	Label string
+
+	// Validate vérifie le JSON-LD produit et retourne les problèmes relevés ;
+	// sans validation, tout JSON-LD lisible est considéré comme valide
+	Validate func(doc map[string]interface{}) []string
+
+	// Progress est appelée après chaque document
+	Progress func(done, total int, result DocumentResult)
+}
+
+// Run convertit les documents du jeu d'évaluation un à un et compare chaque
+// résultat à sa référence. L'échec d'une conversion est consigné et compté :
+// les éléments de la référence sont alors tous manqués.
+func Run(ctx context.Context, dataset string, cases []Case, convert batch.ConvertFunc, opts Options) *Result {
+	result := &Result{Dataset: dataset, Label: opts.Label, StartedAt: time.Now(), Scores: newScores()}
+	converted, valid, correct := 0, 0, 0
+
+	for i, c := range cases {
+		start := time.Now()
+		output, err := convert(ctx, batch.Task{Entry: c.Entry})
+		doc := DocumentResult{Path: c.Path, GoldType: mainType(c.Gold), Duration: time.Since(start), Usage: output.Usage}
+		result.Usage.Add(output.Usage)
+
+		var predicted map[string]interface{}
+		if err == nil {
+			if err = json.Unmarshal(output.Data, &predicted); err != nil {
+				err = fmt.Errorf("invalid JSON-LD: %w", err)
+			}
+		}
+		if err != nil {
+			doc.Error = err.Error()
+			predicted = nil
+		} else {
+			converted++
+			doc.MainType = mainType(predicted)
+			if opts.Validate != nil {
+				doc.Problems = opts.Validate(predicted)
+			}
+			doc.Valid = len(doc.Problems) == 0
+		}
+		doc.TypeCorrect = doc.MainType != "" && doc.MainType == doc.GoldType
+
+		if doc.Valid {
+			valid++
+		}
+		if doc.TypeCorrect {
+			correct++
+		}
+		result.Scores.Add(predicted, c.Gold)
+		result.Documents = append(result.Documents, doc)
+		if opts.Progress != nil {
+			opts.Progress(i+1, len(cases), doc)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	n := len(result.Documents)
+	result.TypeAccuracy = ratio(correct, n)
+	result.ConversionRate = ratio(converted, n)
+	result.ValidityRate = ratio(valid, n)
+	result.Duration = time.Since(result.StartedAt)
+	return result
+}
+
+// Total cumule les comptes de toutes les propriétés (micro-moyenne)
+func Total(counts map[string]*Counts) Counts {
+	var total Counts
+	for _, c := range counts {
+		total.Add(*c)
+	}
+	return total
+}
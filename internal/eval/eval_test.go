@@ -0,0 +1,156 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chrlesur/json-ld-converter/internal/batch"
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+)
+
+func TestScoresAdd(t *testing.T) {
+	gold := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@graph": []interface{}{
+			map[string]interface{}{
+				"@type":     "Person",
+				"name":      "Marie Curie",
+				"birthDate": "1867-11-07",
+				"mentions":  []interface{}{"Pierre Curie", "Sorbonne"},
+			},
+			map[string]interface{}{"@type": "Place", "name": "Varsovie"},
+		},
+	}
+	predicted := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@graph": []interface{}{
+			map[string]interface{}{
+				"@type":     "schema:Person",
+				"name":      "Marie Curie",
+				"birthDate": "1867",
+				"jobTitle":  "physicienne",
+				"mentions":  []interface{}{map[string]interface{}{"name": "Curie"}, "Paris"},
+			},
+		},
+	}
+
+	s := newScores()
+	s.Add(predicted, gold)
+
+	if c := *s.Types["Person"]; c != (Counts{TP: 1}) {
+		t.Errorf("Person counts = %+v", c)
+	}
+	if c := *s.Types["Place"]; c != (Counts{FN: 1}) {
+		t.Errorf("Place counts = %+v", c)
+	}
+	if c := *s.Properties["name"]; c != (Counts{TP: 1, FN: 1}) {
+		t.Errorf("name counts = %+v", c)
+	}
+	if c := *s.Properties["birthDate"]; c != (Counts{FP: 1, FN: 1}) {
+		t.Errorf("birthDate counts = %+v", c)
+	}
+	if c := *s.Properties["jobTitle"]; c != (Counts{FP: 1}) {
+		t.Errorf("jobTitle counts = %+v", c)
+	}
+	if c := *s.Entities["mentions"]; c != (Counts{TP: 1, FP: 1, FN: 1}) {
+		t.Errorf("mentions counts = %+v", c)
+	}
+	if _, ok := s.Entities["events"]; ok {
+		t.Error("Entity lists absent from both documents should not be scored")
+	}
+	if p := Total(s.Properties).Precision(); p != 1.0/3 {
+		t.Errorf("Property precision = %v, want 1/3", p)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt":                "Marie Curie est née à Varsovie.",
+		"a" + GoldSuffix:       `{"@context": "https://schema.org", "@graph": [{"@type": "Person", "name": "Marie Curie"}]}`,
+		"b.md":                 "# Recette",
+		"b" + GoldSuffix:       `{"@context": "https://schema.org", "@type": "Recipe", "name": "Crêpes"}`,
+		"unannotated.txt":      "Pas de référence.",
+		"notes/c.txt":          "Le congrès Solvay.",
+		"notes/c" + GoldSuffix: `{"@type": "Event", "name": "Congrès Solvay"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases, missing, err := LoadDataset(dir, "")
+	if err != nil {
+		t.Fatalf("LoadDataset() error = %v", err)
+	}
+	if len(cases) != 3 || len(missing) != 1 || missing[0] != "unannotated.txt" {
+		t.Fatalf("LoadDataset() = %d cases, missing %v", len(cases), missing)
+	}
+
+	outputs := map[string]string{
+		"a.txt":       `{"@context": "https://schema.org", "@graph": [{"@type": "Person", "name": "Marie Curie"}]}`,
+		"notes/c.txt": `{"@context": "https://schema.org", "@graph": [{"@type": "Thing", "name": "Solvay"}]}`,
+	}
+	convert := func(ctx context.Context, task batch.Task) (batch.Output, error) {
+		usage := llm.Usage{Requests: 2, PromptTokens: 100, CompletionTokens: 20}
+		if output, ok := outputs[task.Path]; ok {
+			return batch.Output{Data: []byte(output), Usage: usage}, nil
+		}
+		return batch.Output{Usage: usage}, errors.New("LLM unavailable")
+	}
+	validate := func(doc map[string]interface{}) []string {
+		if mainType(doc) == "Thing" {
+			return []string{"type trop générique"}
+		}
+		return nil
+	}
+
+	result := Run(context.Background(), dir, cases, convert, Options{Label: "test", Validate: validate})
+	if len(result.Documents) != 3 || result.Usage.Tokens() != 360 {
+		t.Fatalf("Run() = %d documents, %d tokens", len(result.Documents), result.Usage.Tokens())
+	}
+	if result.TypeAccuracy != 1.0/3 || result.ConversionRate != 2.0/3 || result.ValidityRate != 1.0/3 {
+		t.Errorf("Rates = %v, %v, %v", result.TypeAccuracy, result.ConversionRate, result.ValidityRate)
+	}
+	if c := *result.Types["Recipe"]; c != (Counts{FN: 1}) {
+		t.Errorf("A failed conversion should miss every gold type, got %+v", c)
+	}
+
+	path := filepath.Join(dir, "results", "test.json")
+	if err := result.WriteJSON(path); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	loaded, err := LoadResult(path)
+	if err != nil {
+		t.Fatalf("LoadResult() error = %v", err)
+	}
+	if *loaded.Properties["name"] != *result.Properties["name"] || loaded.TypeAccuracy != result.TypeAccuracy {
+		t.Errorf("LoadResult() = %+v", loaded)
+	}
+
+	var out bytes.Buffer
+	if err := result.Write(&out); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Type accuracy:") || !strings.Contains(out.String(), "LLM unavailable") {
+		t.Errorf("Write() output:\n%s", out.String())
+	}
+
+	loaded.Label = "candidate"
+	loaded.TypeAccuracy = 1
+	out.Reset()
+	if err := Compare(&out, result, loaded); err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "+0.667") {
+		t.Errorf("Compare() output:\n%s", out.String())
+	}
+}
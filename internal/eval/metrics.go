@@ -0,0 +1,368 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chrlesur/json-ld-converter/pkg/tokenizer"
+)
+
+// Counts cumule les vrais positifs, faux positifs et faux négatifs d'une métrique
+type Counts struct {
+	TP int `json:"tp"`
+	FP int `json:"fp"`
+	FN int `json:"fn"`
+}
+
+// Add ajoute des comptes
+func (c *Counts) Add(other Counts) {
+	c.TP += other.TP
+	c.FP += other.FP
+	c.FN += other.FN
+}
+
+// Precision retourne la précision, 0 sans prédiction
+func (c Counts) Precision() float64 {
+	return ratio(c.TP, c.TP+c.FP)
+}
+
+// Recall retourne le rappel, 0 sans référence
+func (c Counts) Recall() float64 {
+	return ratio(c.TP, c.TP+c.FN)
+}
+
+// F1 retourne la moyenne harmonique de la précision et du rappel
+func (c Counts) F1() float64 {
+	p, r := c.Precision(), c.Recall()
+	if p+r == 0 {
+		return 0
+	}
+	return 2 * p * r / (p + r)
+}
+
+// Support retourne le nombre d'occurrences dans la référence
+func (c Counts) Support() int {
+	return c.TP + c.FN
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// entityProperties sont les propriétés listant des entités (voir
+// extractProperties), comparées d'après le nom de chaque entité
+var entityProperties = []string{"mentions", "locations", "events"}
+
+// valueThreshold est la similarité lexicale à partir de laquelle deux valeurs
+// de propriété sont considérées comme équivalentes
+const valueThreshold = 0.5
+
+// Scores regroupe les métriques par type, par propriété et par liste d'entités
+type Scores struct {
+	Types      map[string]*Counts `json:"types"`
+	Properties map[string]*Counts `json:"properties"`
+	Entities   map[string]*Counts `json:"entities"`
+}
+
+func newScores() Scores {
+	return Scores{
+		Types:      make(map[string]*Counts),
+		Properties: make(map[string]*Counts),
+		Entities:   make(map[string]*Counts),
+	}
+}
+
+// Add compare un document JSON-LD produit à sa référence et cumule les comptes.
+// Les nœuds des deux graphes sont appariés par type puis par similarité de nom ;
+// les propriétés sont comparées entre nœuds appariés, celles des nœuds sans
+// correspondant comptant comme faux positifs ou faux négatifs.
+func (s Scores) Add(predicted, gold map[string]interface{}) {
+	predictedNodes, goldNodes := graphNodes(predicted), graphNodes(gold)
+
+	predictedTypes, goldTypes := typeCounts(predictedNodes), typeCounts(goldNodes)
+	for t := range union(predictedTypes, goldTypes) {
+		tp := min(predictedTypes[t], goldTypes[t])
+		add(s.Types, t, Counts{TP: tp, FP: predictedTypes[t] - tp, FN: goldTypes[t] - tp})
+	}
+
+	matched := make([]bool, len(predictedNodes))
+	for _, g := range goldNodes {
+		best, bestScore := -1, -1.0
+		for i, p := range predictedNodes {
+			if matched[i] || !shareType(p, g) {
+				continue
+			}
+			if score := similarity(text(p["name"]), text(g["name"])); score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best < 0 {
+			s.compareProperties(nil, g)
+			continue
+		}
+		matched[best] = true
+		s.compareProperties(predictedNodes[best], g)
+	}
+	for i, p := range predictedNodes {
+		if !matched[i] {
+			s.compareProperties(p, nil)
+		}
+	}
+
+	for _, property := range entityProperties {
+		if c := compareEntities(entityNames(predictedNodes, property), entityNames(goldNodes, property)); c != (Counts{}) {
+			add(s.Entities, property, c)
+		}
+	}
+}
+
+// compareProperties compare les propriétés de deux nœuds appariés ; l'un des
+// deux est nil si le nœud n'a pas de correspondant. Les listes d'entités sont
+// évaluées à part.
+func (s Scores) compareProperties(predicted, gold map[string]interface{}) {
+	for key := range union(predicted, gold) {
+		if strings.HasPrefix(key, "@") || isEntityProperty(key) {
+			continue
+		}
+		p, inPredicted := predicted[key]
+		g, inGold := gold[key]
+		switch {
+		case inPredicted && inGold && valuesMatch(p, g):
+			add(s.Properties, key, Counts{TP: 1})
+		case inPredicted && inGold:
+			add(s.Properties, key, Counts{FP: 1, FN: 1})
+		case inPredicted:
+			add(s.Properties, key, Counts{FP: 1})
+		default:
+			add(s.Properties, key, Counts{FN: 1})
+		}
+	}
+}
+
+func isEntityProperty(key string) bool {
+	for _, property := range entityProperties {
+		if key == property {
+			return true
+		}
+	}
+	return false
+}
+
+// compareEntities apparie deux listes de noms d'entités ; deux noms
+// correspondent s'ils sont égaux ou si l'un contient l'autre (« Curie » et
+// « Marie Curie »)
+func compareEntities(predicted, gold []string) Counts {
+	var c Counts
+	used := make([]bool, len(predicted))
+	for _, g := range gold {
+		found := false
+		for i, p := range predicted {
+			if !used[i] && (p == g || strings.Contains(p, g) || strings.Contains(g, p)) {
+				used[i], found = true, true
+				break
+			}
+		}
+		if found {
+			c.TP++
+		} else {
+			c.FN++
+		}
+	}
+	c.FP = len(predicted) - c.TP
+	return c
+}
+
+// entityNames retourne les noms normalisés et sans doublon des entités d'une
+// propriété, dans tous les nœuds
+func entityNames(nodes []map[string]interface{}, property string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, node := range nodes {
+		for _, item := range items(node[property]) {
+			name := normalize(text(item))
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// valuesMatch compare deux valeurs de propriété d'après leur texte
+func valuesMatch(a, b interface{}) bool {
+	ta, tb := normalize(text(a)), normalize(text(b))
+	return ta == tb || similarity(ta, tb) >= valueThreshold
+}
+
+// text retourne le texte d'une valeur : le nom d'un objet qui en a un, sinon
+// ses valeurs textuelles ; les éléments d'une liste sont séparés par des espaces
+func text(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]interface{}:
+		if name, ok := v["name"]; ok {
+			return text(name)
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			if !strings.HasPrefix(key, "@") {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		var parts []string
+		for _, key := range keys {
+			parts = append(parts, text(v[key]))
+		}
+		return strings.Join(parts, " ")
+	case []interface{}:
+		var parts []string
+		for _, item := range v {
+			parts = append(parts, text(item))
+		}
+		return strings.Join(parts, " ")
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// similarity est l'indice de Jaccard des mots de deux textes
+func similarity(a, b string) float64 {
+	wa, wb := words(a), words(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		if len(wa) == len(wb) {
+			return 1
+		}
+		return 0
+	}
+	common := 0
+	for w := range wa {
+		if wb[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(wa)+len(wb)-common)
+}
+
+func words(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range tokenizer.SplitIntoTokens(strings.ToLower(s)) {
+		set[w] = true
+	}
+	return set
+}
+
+// graphNodes retourne les nœuds d'un document JSON-LD : ceux de @graph, ou le
+// document lui-même
+func graphNodes(doc map[string]interface{}) []map[string]interface{} {
+	graph, ok := doc["@graph"]
+	if !ok {
+		if doc == nil {
+			return nil
+		}
+		return []map[string]interface{}{doc}
+	}
+	var nodes []map[string]interface{}
+	for _, item := range items(graph) {
+		if node, ok := item.(map[string]interface{}); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// items retourne les éléments d'une valeur, une valeur simple formant une liste
+// d'un élément
+func items(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	default:
+		return []interface{}{v}
+	}
+}
+
+// types retourne les types d'un nœud, sans préfixe ni espace de noms Schema.org
+func types(node map[string]interface{}) []string {
+	var result []string
+	for _, item := range items(node["@type"]) {
+		if t, ok := item.(string); ok {
+			for _, prefix := range []string{"schema:", "https://schema.org/", "http://schema.org/"} {
+				t = strings.TrimPrefix(t, prefix)
+			}
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// mainType retourne le type du premier nœud d'un document
+func mainType(doc map[string]interface{}) string {
+	if nodes := graphNodes(doc); len(nodes) > 0 {
+		if t := types(nodes[0]); len(t) > 0 {
+			return t[0]
+		}
+	}
+	return ""
+}
+
+func typeCounts(nodes []map[string]interface{}) map[string]int {
+	counts := make(map[string]int)
+	for _, node := range nodes {
+		for _, t := range types(node) {
+			counts[t]++
+		}
+	}
+	return counts
+}
+
+func shareType(a, b map[string]interface{}) bool {
+	for _, ta := range types(a) {
+		for _, tb := range types(b) {
+			if ta == tb {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func union[V any](a, b map[string]V) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return keys
+}
+
+func add(m map[string]*Counts, key string, c Counts) {
+	if m[key] == nil {
+		m[key] = &Counts{}
+	}
+	m[key].Add(c)
+}
+
+// formatScore affiche une métrique comprise entre 0 et 1
+func formatScore(v float64) string {
+	return fmt.Sprintf("%.3f", v)
+}
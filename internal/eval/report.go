@@ -0,0 +1,180 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+)
+
+// Write affiche le résultat d'une évaluation : le résumé (exactitude du type
+// principal, taux de conversion et de validité, consommation), puis la
+// précision, le rappel et le F1 par type, par propriété et par liste d'entités
+func (r *Result) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if r.Label != "" {
+		fmt.Fprintf(tw, "Configuration:\t%s\n", r.Label)
+	}
+	fmt.Fprintf(tw, "Documents:\t%d\n", len(r.Documents))
+	fmt.Fprintf(tw, "Type accuracy:\t%s\n", formatScore(r.TypeAccuracy))
+	fmt.Fprintf(tw, "Conversion rate:\t%s\n", formatScore(r.ConversionRate))
+	fmt.Fprintf(tw, "Validity rate:\t%s\n", formatScore(r.ValidityRate))
+	fmt.Fprintf(tw, "Requests:\t%d\n", r.Usage.Requests)
+	fmt.Fprintf(tw, "Tokens:\t%d (%d per document)\n", r.Usage.Tokens(), r.Usage.Tokens()/max(len(r.Documents), 1))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, table := range []struct {
+		title  string
+		counts map[string]*Counts
+	}{
+		{"TYPE", r.Types},
+		{"PROPERTY", r.Properties},
+		{"ENTITIES", r.Entities},
+	} {
+		fmt.Fprintln(w)
+		if err := writeCounts(w, table.title, table.counts); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tTYPE\tGOLD\tVALID\tTOKENS\tDETAIL")
+	for _, d := range r.Documents {
+		detail := d.Error
+		if detail == "" && len(d.Problems) > 0 {
+			detail = fmt.Sprintf("%d problems: %s", len(d.Problems), d.Problems[0])
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%d\t%s\n", d.Path, orDash(d.MainType), orDash(d.GoldType), d.Valid, d.Usage.Tokens(), detail)
+	}
+	return tw.Flush()
+}
+
+// writeCounts affiche un tableau de métriques trié par nom, suivi de la micro-moyenne
+func writeCounts(w io.Writer, title string, counts map[string]*Counts) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tPRECISION\tRECALL\tF1\tSUPPORT\n", title)
+	for _, name := range sortedKeys(counts) {
+		c := counts[name]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", name, formatScore(c.Precision()), formatScore(c.Recall()), formatScore(c.F1()), c.Support())
+	}
+	total := Total(counts)
+	fmt.Fprintf(tw, "ALL\t%s\t%s\t%s\t%d\n", formatScore(total.Precision()), formatScore(total.Recall()), formatScore(total.F1()), total.Support())
+	return tw.Flush()
+}
+
+// WriteJSON enregistre le résultat au format JSON, pour une comparaison ultérieure
+func (r *Result) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling evaluation result: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating result directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing evaluation result: %w", err)
+	}
+	return nil
+}
+
+// LoadResult lit un résultat enregistré par WriteJSON
+func LoadResult(path string) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading evaluation result: %w", err)
+	}
+	r := &Result{Scores: newScores()}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("error parsing evaluation result %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Compare affiche côte à côte deux évaluations, base puis candidate, avec
+// l'écart de chaque métrique : résumé, puis F1 par type, par propriété et par
+// liste d'entités
+func Compare(w io.Writer, base, candidate *Result) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "METRIC\t%s\t%s\tDELTA\n", resultName(base, "base"), resultName(candidate, "candidate"))
+	for _, m := range []struct {
+		name string
+		a, b float64
+	}{
+		{"Type accuracy", base.TypeAccuracy, candidate.TypeAccuracy},
+		{"Conversion rate", base.ConversionRate, candidate.ConversionRate},
+		{"Validity rate", base.ValidityRate, candidate.ValidityRate},
+		{"Type F1", Total(base.Types).F1(), Total(candidate.Types).F1()},
+		{"Property F1", Total(base.Properties).F1(), Total(candidate.Properties).F1()},
+		{"Entity F1", Total(base.Entities).F1(), Total(candidate.Entities).F1()},
+	} {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%+.3f\n", m.name, formatScore(m.a), formatScore(m.b), m.b-m.a)
+	}
+	fmt.Fprintf(tw, "Tokens\t%d\t%d\t%+d\n", base.Usage.Tokens(), candidate.Usage.Tokens(), candidate.Usage.Tokens()-base.Usage.Tokens())
+	fmt.Fprintf(tw, "Requests\t%d\t%d\t%+d\n", base.Usage.Requests, candidate.Usage.Requests, candidate.Usage.Requests-base.Usage.Requests)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, table := range []struct {
+		title string
+		a, b  map[string]*Counts
+	}{
+		{"TYPE", base.Types, candidate.Types},
+		{"PROPERTY", base.Properties, candidate.Properties},
+		{"ENTITIES", base.Entities, candidate.Entities},
+	} {
+		fmt.Fprintln(w)
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "%s\tF1 %s\tF1 %s\tDELTA\n", table.title, resultName(base, "base"), resultName(candidate, "candidate"))
+		var names []string
+		for name := range union(table.a, table.b) {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			a, b := f1(table.a[name]), f1(table.b[name])
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%+.3f\n", name, formatScore(a), formatScore(b), b-a)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// f1 retourne le F1 de comptes éventuellement absents
+func f1(c *Counts) float64 {
+	if c == nil {
+		return 0
+	}
+	return c.F1()
+}
+
+func resultName(r *Result, fallback string) string {
+	if r.Label != "" {
+		return r.Label
+	}
+	return fallback
+}
+
+func sortedKeys(counts map[string]*Counts) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,47 @@
+package examples
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Example associe un extrait de document au JSON-LD attendu, avec le type
+// Schema.org du nœud et la langue de l'extrait
+type Example struct {
+	Type     string                 `json:"type"`
+	Language string                 `json:"language,omitempty"`
+	Input    string                 `json:"input"`
+	Output   map[string]interface{} `json:"output"`
+}
+
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// Save enregistre un exemple dans un fichier JSON du répertoire, nommé d'après
+// son type et son contenu : enregistrer deux fois le même exemple ne crée
+// qu'un fichier. Le chemin du fichier est retourné.
+func Save(dir string, example Example) (string, error) {
+	if example.Type == "" || example.Input == "" || len(example.Output) == 0 {
+		return "", fmt.Errorf("example needs a type, an input and an output")
+	}
+	data, err := json.MarshalIndent(example, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error marshaling example: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error creating examples directory: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	name := strings.Trim(unsafeName.ReplaceAllString(example.Type, "_"), "_")
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.json", name, hex.EncodeToString(sum[:6])))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("error writing example: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,70 @@
+package examples
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	example := Example{
+		Type:   "schema:Person",
+		Input:  "Marie Curie est née à Varsovie.",
+		Output: map[string]interface{}{"@type": "Person", "name": "Marie Curie"},
+	}
+
+	path, err := Save(dir, example)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	again, err := Save(dir, example)
+	if err != nil || again != path {
+		t.Errorf("Saving the same example twice should reuse %s, got %s (%v)", path, again, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected one example file, got %d", len(entries))
+	}
+
+	if _, err := Save(dir, Example{Type: "Person"}); err == nil {
+		t.Error("Save() should reject an incomplete example")
+	}
+}
+
+func TestLibrarySelect(t *testing.T) {
+	dir := t.TempDir()
+	for _, example := range []Example{
+		{Type: "Person", Language: "fr", Input: "Marie Curie, physicienne et chimiste, née à Varsovie.", Output: map[string]interface{}{"name": "Marie Curie"}},
+		{Type: "Person", Language: "en", Input: "Marie Curie, physicist and chemist, born in Warsaw.", Output: map[string]interface{}{"name": "Marie Curie"}},
+		{Type: "Event", Input: "Le congrès Solvay réunit les physiciens à Bruxelles.", Output: map[string]interface{}{"name": "Congrès Solvay"}},
+		{Type: "Recipe", Input: "Battre les œufs et ajouter la farine.", Output: map[string]interface{}{"name": "Crêpes"}},
+	} {
+		if _, err := Save(dir, example); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	lib, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if lib.Len() != 4 {
+		t.Fatalf("Load() = %d examples, want 4", lib.Len())
+	}
+
+	selected := lib.Select("schema:Person", "fr-FR", "Pierre Curie, physicien, épouse Marie à Paris avec les physiciens.", 1000)
+	if len(selected) != 2 || selected[0].Type != "Person" || selected[0].Language != "fr" || selected[1].Type != "Event" {
+		t.Errorf("Select() = %+v", selected)
+	}
+	if selected := lib.Select("Person", "fr", "Pierre Curie", Tokens(selected[0])); len(selected) != 1 {
+		t.Errorf("Select() should respect the token budget, got %d examples", len(selected))
+	}
+
+	lib.Add(selected[0])
+	if lib.Len() != 4 {
+		t.Error("Add() should ignore an example already in the library")
+	}
+	if empty, err := Load(filepath.Join(dir, "missing")); err != nil || empty.Len() != 0 {
+		t.Errorf("Load() of a missing directory = %v, %v", empty, err)
+	}
+}
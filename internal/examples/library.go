@@ -0,0 +1,166 @@
+package examples
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/chrlesur/json-ld-converter/pkg/tokenizer"
+)
+
+// Library regroupe les exemples disponibles pour guider l'extraction
+type Library struct {
+	mu       sync.RWMutex
+	examples []Example
+}
+
+// Load lit les exemples des fichiers JSON d'un répertoire ; un répertoire absent
+// donne une bibliothèque vide
+func Load(dir string) (*Library, error) {
+	lib := &Library{}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return lib, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading examples directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading example %s: %w", path, err)
+		}
+		var example Example
+		if err := json.Unmarshal(data, &example); err != nil {
+			return nil, fmt.Errorf("error parsing example %s: %w", path, err)
+		}
+		if example.Type == "" || example.Input == "" || len(example.Output) == 0 {
+			return nil, fmt.Errorf("example %s needs a type, an input and an output", path)
+		}
+		lib.examples = append(lib.examples, example)
+	}
+	return lib, nil
+}
+
+// Add ajoute un exemple à la bibliothèque (un exemple enregistré pendant
+// l'exécution du serveur par exemple) ; un exemple déjà présent est ignoré
+func (l *Library) Add(example Example) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, e := range l.examples {
+		if reflect.DeepEqual(e, example) {
+			return
+		}
+	}
+	l.examples = append(l.examples, example)
+}
+
+// Len retourne le nombre d'exemples de la bibliothèque
+func (l *Library) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.examples)
+}
+
+// Select retourne les exemples les plus pertinents pour un contenu dont le type
+// Schema.org est typeName : les exemples du même type d'abord, puis par
+// similarité lexicale avec le contenu. Les exemples d'une autre langue, et ceux
+// d'un autre type sans aucun mot en commun, sont écartés. La somme des tokens
+// des exemples retenus (extrait et JSON-LD) ne dépasse pas budget.
+func (l *Library) Select(typeName, language, content string, budget int) []Example {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	type candidate struct {
+		example Example
+		score   float64
+	}
+	words := wordSet(content)
+	var candidates []candidate
+	for _, example := range l.examples {
+		if language != "" && example.Language != "" && !sameLanguage(language, example.Language) {
+			continue
+		}
+		score := similarity(words, wordSet(example.Input))
+		if typeKey(example.Type) == typeKey(typeName) {
+			score++
+		} else if score == 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{example, score})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	var selected []Example
+	used := 0
+	for _, c := range candidates {
+		cost := Tokens(c.example)
+		if used+cost > budget {
+			continue
+		}
+		selected = append(selected, c.example)
+		used += cost
+	}
+	return selected
+}
+
+// Tokens estime la place d'un exemple dans un prompt
+func Tokens(example Example) int {
+	output, _ := json.Marshal(example.Output)
+	return tokenizer.CountTokens(example.Input) + tokenizer.CountTokens(string(output))
+}
+
+// similarity est l'indice de Jaccard de deux ensembles de mots
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for word := range a {
+		if b[word] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// wordSet retourne les mots d'un texte, en minuscules ; les mots très courts,
+// souvent des mots-outils, sont ignorés
+func wordSet(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range tokenizer.SplitIntoTokens(strings.ToLower(text)) {
+		if utf8.RuneCountInString(word) > 3 {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+// typeKey normalise un nom de type Schema.org pour la comparaison
+func typeKey(typeName string) string {
+	typeName = strings.TrimPrefix(typeName, "schema:")
+	typeName = strings.TrimPrefix(typeName, "https://schema.org/")
+	return strings.ToLower(strings.TrimPrefix(typeName, "http://schema.org/"))
+}
+
+// sameLanguage compare deux codes de langue sans tenir compte de la région (fr, fr-FR)
+func sameLanguage(a, b string) bool {
+	primary := func(code string) string {
+		code, _, _ = strings.Cut(strings.ToLower(code), "-")
+		code, _, _ = strings.Cut(code, "_")
+		return code
+	}
+	return primary(a) == primary(b)
+}
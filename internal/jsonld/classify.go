@@ -0,0 +1,278 @@
+package jsonld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+	"github.com/chrlesur/json-ld-converter/internal/schema"
+)
+
+// classificationDepth est le nombre maximal de niveaux de la hiérarchie
+// Schema.org parcourus pour classer un contenu (Thing > CreativeWork > Article
+// > NewsArticle), soit autant de requêtes au LLM
+const classificationDepth = 3
+
+// maxAlternatives est le nombre de types alternatifs conservés
+const maxAlternatives = 3
+
+// describedCandidates est le nombre de types candidats au-delà duquel la liste
+// proposée au LLM ne comporte que leurs noms, sans description
+const describedCandidates = 20
+
+// Classification est le classement d'un contenu dans la hiérarchie Schema.org
+type Classification struct {
+	Types        []string      `json:"types"`                  // types retenus, le type principal en premier
+	Confidence   float64       `json:"confidence"`             // confiance dans le type principal, entre 0 et 1
+	Alternatives []Alternative `json:"alternatives,omitempty"` // autres types envisagés
+}
+
+// Alternative est un type envisagé mais non retenu
+type Alternative struct {
+	Type       string  `json:"type"`
+	Confidence float64 `json:"confidence"`
+}
+
+// MainType retourne le type principal
+func (c Classification) MainType() string {
+	if len(c.Types) == 0 {
+		return "Thing"
+	}
+	return c.Types[0]
+}
+
+// TypeValue retourne la valeur de @type : le nom du type, ou la liste des types
+// d'un nœud qui en a plusieurs
+func (c Classification) TypeValue() interface{} {
+	if len(c.Types) <= 1 {
+		return c.MainType()
+	}
+	types := make([]interface{}, len(c.Types))
+	for i, t := range c.Types {
+		types[i] = t
+	}
+	return types
+}
+
+// classificationResponse est la réponse attendue du LLM
+type classificationResponse struct {
+	Types        []string `json:"types"`
+	Type         string   `json:"type"`
+	Confidence   float64  `json:"confidence"`
+	Alternatives []struct {
+		Type       string  `json:"type"`
+		Confidence float64 `json:"confidence"`
+	} `json:"alternatives"`
+}
+
+// Classify classe un contenu en descendant la hiérarchie Schema.org : le LLM
+// choisit d'abord parmi les sous-types directs de Thing, puis parmi les
+// sous-types du type retenu, jusqu'à ce qu'il conserve le type courant, que le
+// type n'ait plus de sous-type ou que classificationDepth niveaux soient
+// parcourus. Chaque réponse est limitée à la liste proposée ; un second type de
+// la liste peut être retenu pour un contenu qui relève de plusieurs types.
+func (c *Converter) Classify(ctx context.Context, content string) (Classification, error) {
+	result := Classification{Types: []string{"Thing"}}
+	for depth := 0; depth < classificationDepth; depth++ {
+		current := result.MainType()
+		candidates := c.typeCandidates(current)
+		if len(candidates) == 0 {
+			break
+		}
+		if depth > 0 {
+			// Le type courant reste un choix possible lorsqu'aucun sous-type ne convient
+			candidates = append([]schema.SchemaType{c.schemaOrg.Types["schema:"+current]}, candidates...)
+		}
+
+		prompt := classificationPrompt(content, current, candidates, depth > 0)
+		response, newContext, err := c.llmClient.Analyze(ctx, prompt, c.contextManager.Context())
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error calling LLM for type classification: %v", err))
+			return result, fmt.Errorf("erreur lors de l'appel au LLM : %w", err)
+		}
+		c.contextManager.SetContext(newContext)
+
+		answer, ok := parseClassification(response, candidates)
+		if !ok {
+			logger.Warning(fmt.Sprintf("Classification response matches none of the %d proposed types: %s", len(candidates), response))
+			if depth == 0 {
+				c.stats.ThingFallbacks++
+			}
+			break
+		}
+		if answer.MainType() == current {
+			break
+		}
+		result = c.mergeClassification(result, answer)
+	}
+
+	logger.Info(fmt.Sprintf("Content classified as %s (confidence %.2f%s)", strings.Join(result.Types, ", "), result.Confidence, formatAlternatives(result.Alternatives)))
+	return result, nil
+}
+
+// typeCandidates retourne les sous-types proposés au niveau suivant
+func (c *Converter) typeCandidates(typeName string) []schema.SchemaType {
+	if c.schemaOrg == nil {
+		return nil
+	}
+	return c.schemaOrg.Subtypes(typeName)
+}
+
+// mergeClassification combine le classement des niveaux précédents avec la
+// réponse du niveau courant : le type principal est remplacé par son sous-type
+// et les types secondaires déjà retenus sont conservés. Un type dont descend un
+// type déjà retenu est écarté.
+func (c *Converter) mergeClassification(previous, answer Classification) Classification {
+	merged := Classification{Confidence: answer.Confidence, Alternatives: answer.Alternatives}
+	for _, t := range append(append([]string{}, answer.Types...), previous.Types[1:]...) {
+		redundant := false
+		for _, chosen := range merged.Types {
+			if c.schemaOrg.IsSubtypeOf(chosen, t) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			merged.Types = append(merged.Types, t)
+		}
+	}
+	return merged
+}
+
+// classificationPrompt construit la question posée au LLM pour un niveau de la hiérarchie
+func classificationPrompt(content, current string, candidates []schema.SchemaType, keepCurrent bool) string {
+	var list strings.Builder
+	for _, candidate := range candidates {
+		name := schemaName(candidate.ID)
+		if len(candidates) > describedCandidates {
+			fmt.Fprintf(&list, "- %s\n", name)
+			continue
+		}
+		if description := firstSentence(candidate.Comment); description != "" {
+			fmt.Fprintf(&list, "- %s : %s\n", name, description)
+		} else {
+			fmt.Fprintf(&list, "- %s\n", name)
+		}
+	}
+
+	question := "Choisissez le type Schema.org qui décrit le mieux le contenu suivant"
+	if keepCurrent {
+		question = fmt.Sprintf("Le contenu suivant a été classé comme %s. Choisissez le type Schema.org plus précis qui le décrit le mieux, ou %s si aucun ne convient", current, current)
+	}
+	return fmt.Sprintf(`%s. Le type doit être UNIQUEMENT l'un des types de cette liste :
+%s
+Si le contenu relève réellement de deux types de la liste (par exemple un livre décrit aussi comme un produit en vente), indiquez les deux, le principal en premier.
+Retournez UNIQUEMENT un objet JSON valide, sans texte supplémentaire, de la forme :
+{"types": ["Type"], "confidence": 0.8, "alternatives": [{"type": "AutreType", "confidence": 0.15}]}
+"confidence" est votre confiance dans le type principal, entre 0 et 1 ; "alternatives" liste au plus %d autres types de la liste envisagés.
+
+Contenu :
+%s`, question, list.String(), maxAlternatives, content)
+}
+
+// parseClassification lit la réponse du LLM et ne retient que des types de la
+// liste des candidats. Une réponse qui n'est pas un objet JSON est acceptée si
+// elle cite un candidat comme mot entier ; la confiance est alors inconnue (0).
+func parseClassification(response string, candidates []schema.SchemaType) (Classification, bool) {
+	names := make(map[string]string, len(candidates))
+	for _, candidate := range candidates {
+		name := schemaName(candidate.ID)
+		names[strings.ToLower(name)] = name
+	}
+	lookup := func(typeName string) (string, bool) {
+		name, ok := names[strings.ToLower(schemaName(strings.TrimSpace(typeName)))]
+		return name, ok
+	}
+
+	var result Classification
+	var parsed classificationResponse
+	start, end := strings.Index(response, "{"), strings.LastIndex(response, "}")
+	isJSON := start != -1 && end > start && json.Unmarshal([]byte(response[start:end+1]), &parsed) == nil
+	if isJSON {
+		if parsed.Type != "" {
+			parsed.Types = append([]string{parsed.Type}, parsed.Types...)
+		}
+		for _, t := range parsed.Types {
+			if name, ok := lookup(t); ok && !contains(result.Types, name) {
+				result.Types = append(result.Types, name)
+			}
+		}
+		if len(result.Types) > 2 {
+			result.Types = result.Types[:2]
+		}
+		result.Confidence = normalizeConfidence(parsed.Confidence)
+		for _, alternative := range parsed.Alternatives {
+			if name, ok := lookup(alternative.Type); ok && !contains(result.Types, name) {
+				result.Alternatives = append(result.Alternatives, Alternative{Type: name, Confidence: normalizeConfidence(alternative.Confidence)})
+			}
+		}
+	}
+
+	if len(result.Types) == 0 && !isJSON {
+		// Repli : premier candidat cité comme mot entier dans la réponse
+		result = Classification{}
+		for _, word := range strings.FieldsFunc(response, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+			if name, ok := names[strings.ToLower(word)]; ok && word == name {
+				result.Types = []string{name}
+				break
+			}
+		}
+	}
+	if len(result.Types) == 0 {
+		return Classification{}, false
+	}
+
+	sort.SliceStable(result.Alternatives, func(i, j int) bool {
+		return result.Alternatives[i].Confidence > result.Alternatives[j].Confidence
+	})
+	if len(result.Alternatives) > maxAlternatives {
+		result.Alternatives = result.Alternatives[:maxAlternatives]
+	}
+	return result, true
+}
+
+// normalizeConfidence ramène une confiance entre 0 et 1, un pourcentage étant accepté
+func normalizeConfidence(confidence float64) float64 {
+	if confidence > 1 && confidence <= 100 {
+		confidence /= 100
+	}
+	return min(max(confidence, 0), 1)
+}
+
+// firstSentence retourne la première phrase d'une description Schema.org, sans
+// ses liens [[Type]], tronquée à 120 caractères
+func firstSentence(comment string) string {
+	comment = strings.NewReplacer("[[", "", "]]", "", "\n", " ").Replace(comment)
+	if i := strings.Index(comment, ". "); i != -1 {
+		comment = comment[:i+1]
+	}
+	if utf8.RuneCountInString(comment) > 120 {
+		comment = string([]rune(comment)[:117]) + "..."
+	}
+	return strings.TrimSpace(comment)
+}
+
+func formatAlternatives(alternatives []Alternative) string {
+	if len(alternatives) == 0 {
+		return ""
+	}
+	parts := make([]string, len(alternatives))
+	for i, a := range alternatives {
+		parts[i] = fmt.Sprintf("%s %.2f", a.Type, a.Confidence)
+	}
+	return ", alternatives: " + strings.Join(parts, ", ")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package jsonld
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+	"github.com/chrlesur/json-ld-converter/internal/schema"
+)
+
+// scriptedClient retourne ses réponses dans l'ordre, une par requête
+type scriptedClient struct {
+	responses []string
+	prompts   []string
+}
+
+func (s *scriptedClient) Analyze(ctx context.Context, content string, analysisContext *llm.AnalysisContext) (string, *llm.AnalysisContext, error) {
+	s.prompts = append(s.prompts, content)
+	response := s.responses[0]
+	s.responses = s.responses[1:]
+	return response, analysisContext, nil
+}
+
+var classificationSchema = &schema.SchemaOrg{
+	Types: map[string]schema.SchemaType{
+		"schema:Thing":        {ID: "schema:Thing", Comment: "The most generic type of item."},
+		"schema:CreativeWork": {ID: "schema:CreativeWork", SubClassOf: []string{"schema:Thing"}, Comment: "The most generic kind of creative work. Including books, movies."},
+		"schema:Person":       {ID: "schema:Person", SubClassOf: []string{"schema:Thing"}},
+		"schema:Product":      {ID: "schema:Product", SubClassOf: []string{"schema:Thing"}},
+		"schema:Taxon":        {ID: "schema:Taxon", SubClassOf: []string{"schema:Thing"}, IsPartOf: "https://pending.schema.org"},
+		"schema:Article":      {ID: "schema:Article", SubClassOf: []string{"schema:CreativeWork"}},
+		"schema:Book":         {ID: "schema:Book", SubClassOf: []string{"schema:CreativeWork"}},
+		"schema:NewsArticle":  {ID: "schema:NewsArticle", SubClassOf: []string{"schema:Article"}},
+	},
+}
+
+func TestClassify(t *testing.T) {
+	client := &scriptedClient{responses: []string{
+		`{"types": ["CreativeWork", "Product"], "confidence": 0.9, "alternatives": [{"type": "Person", "confidence": 0.05}, {"type": "Human", "confidence": 0.5}]}`,
+		"Voici ma réponse :\n```json\n{\"types\": [\"book\"], \"confidence\": 80, \"alternatives\": [{\"type\": \"schema:Article\", \"confidence\": 0.1}]}\n```",
+	}}
+	conv := NewConverter(classificationSchema, client, 1000, "")
+
+	classification, err := conv.Classify(context.Background(), "Les Misérables, roman de Victor Hugo, en vente à 9 euros.")
+	if err != nil {
+		t.Fatalf("Classify() error = %v", err)
+	}
+	want := Classification{
+		Types:        []string{"Book", "Product"},
+		Confidence:   0.8,
+		Alternatives: []Alternative{{Type: "Article", Confidence: 0.1}},
+	}
+	if !reflect.DeepEqual(classification, want) {
+		t.Errorf("Classify() = %+v, want %+v", classification, want)
+	}
+	if !reflect.DeepEqual(classification.TypeValue(), []interface{}{"Book", "Product"}) {
+		t.Errorf("TypeValue() = %v", classification.TypeValue())
+	}
+
+	// Book n'a pas de sous-type : deux niveaux suffisent
+	if len(client.prompts) != 2 {
+		t.Fatalf("Expected 2 LLM requests, got %d", len(client.prompts))
+	}
+	if !strings.Contains(client.prompts[0], "- CreativeWork : The most generic kind of creative work.\n") || strings.Contains(client.prompts[0], "Taxon") {
+		t.Errorf("First level should list the described subtypes of Thing, except pending ones:\n%s", client.prompts[0])
+	}
+	if !strings.Contains(client.prompts[1], "- CreativeWork : The most generic kind of creative work.\n- Article\n- Book\n") {
+		t.Errorf("Second level should offer CreativeWork and its subtypes:\n%s", client.prompts[1])
+	}
+}
+
+func TestClassifyKeepsCurrentType(t *testing.T) {
+	client := &scriptedClient{responses: []string{
+		`{"types": ["CreativeWork"], "confidence": 0.7}`,
+		`{"types": ["CreativeWork"], "confidence": 0.6}`,
+	}}
+	conv := NewConverter(classificationSchema, client, 1000, "")
+
+	classification, err := conv.Classify(context.Background(), "Une œuvre difficile à classer.")
+	if err != nil {
+		t.Fatalf("Classify() error = %v", err)
+	}
+	if classification.TypeValue() != "CreativeWork" || classification.Confidence != 0.7 || len(client.prompts) != 2 {
+		t.Errorf("Classify() = %+v after %d requests", classification, len(client.prompts))
+	}
+}
+
+func TestParseClassification(t *testing.T) {
+	candidates := []schema.SchemaType{{ID: "schema:Article"}, {ID: "schema:Person"}, {ID: "schema:Thing"}}
+
+	tests := []struct {
+		response string
+		want     []string
+		ok       bool
+	}{
+		{`{"type": "Person", "confidence": 0.9}`, []string{"Person"}, true},
+		{`Le type le plus approprié est Person.`, []string{"Person"}, true},
+		// Seuls les mots entiers comptent : "Articles" ne désigne pas Article
+		{`Articles de presse ; Thing.`, []string{"Thing"}, true},
+		{`{"types": ["Recipe"], "confidence": 1, "alternatives": [{"type": "Article"}]}`, nil, false},
+		{`CreativeWork`, nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseClassification(tt.response, candidates)
+		if ok != tt.ok || !reflect.DeepEqual(got.Types, tt.want) {
+			t.Errorf("parseClassification(%q) = %v, %v; want %v, %v", tt.response, got.Types, ok, tt.want, tt.ok)
+		}
+	}
+}
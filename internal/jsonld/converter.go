@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
-	"unicode"
 
+	"github.com/chrlesur/json-ld-converter/internal/examples"
 	"github.com/chrlesur/json-ld-converter/internal/llm"
 	"github.com/chrlesur/json-ld-converter/internal/logger"
 	"github.com/chrlesur/json-ld-converter/internal/parser"
@@ -20,7 +20,11 @@ type Converter struct {
 	llmClient              llm.LLMClient
 	maxTokens              int
 	additionalInstructions string
-	analysisContext        *llm.AnalysisContext
+	contextManager         *llm.ContextManager
+	stats                  Stats
+	examples               *examples.Library
+	exampleBudget          int
+	language               string
 }
 
 func NewConverter(schemaOrg *schema.SchemaOrg, client llm.LLMClient, maxTokens int, instructions string) *Converter {
@@ -30,10 +34,28 @@ func NewConverter(schemaOrg *schema.SchemaOrg, client llm.LLMClient, maxTokens i
 		llmClient:              client,
 		maxTokens:              maxTokens,
 		additionalInstructions: instructions,
-		analysisContext:        &llm.AnalysisContext{},
+		contextManager:         llm.NewContextManager(client, llm.DefaultContextBudget, llm.DefaultSummaryInterval),
 	}
 }
 
+// SetContextLimits configure le budget de tokens du contexte transmis entre segments
+// et le nombre de segments après lequel les résumés sont condensés
+func (c *Converter) SetContextLimits(budget, summaryInterval int) {
+	current := c.contextManager.Context()
+	c.contextManager = llm.NewContextManager(c.llmClient, budget, summaryInterval)
+	c.contextManager.SetContext(current)
+}
+
+// AnalysisContext retourne le contexte accumulé sur les segments déjà convertis
+func (c *Converter) AnalysisContext() *llm.AnalysisContext {
+	return c.contextManager.Context()
+}
+
+// SetAnalysisContext restaure un contexte, par exemple lors de la reprise d'une conversion
+func (c *Converter) SetAnalysisContext(ac *llm.AnalysisContext) {
+	c.contextManager.SetContext(ac)
+}
+
 func (c *Converter) Convert(ctx context.Context, doc *parser.Document) (map[string]interface{}, error) {
 	logger.Info(fmt.Sprintf("Starting conversion process for document with %d tokens", tokenizer.CountTokens(doc.Content)))
 
@@ -43,6 +65,7 @@ func (c *Converter) Convert(ctx context.Context, doc *parser.Document) (map[stri
 	}
 
 	logger.Debug(fmt.Sprintf("Starting conversion of document with content: %s", doc.Content))
+	c.language = doc.Metadata["language"]
 
 	jsonLD := map[string]interface{}{
 		"@context": "https://schema.org",
@@ -54,16 +77,17 @@ func (c *Converter) Convert(ctx context.Context, doc *parser.Document) (map[stri
 		logger.Error(fmt.Sprintf("Error enriching content with LLM: %v", err))
 		return nil, &ConversionError{Stage: "enrichissement", Err: err}
 	}
-	c.analysisContext = newContext
+	c.contextManager.SetContext(newContext)
 	logger.Debug("Content successfully enriched by LLM")
 
 	logger.Debug("Determining main type")
-	mainType, err := c.determineMainType(ctx, enrichedContent)
+	classification, err := c.determineMainType(ctx, enrichedContent)
 	if err != nil {
 		logger.Warning(fmt.Sprintf("Error determining main type: %v. Falling back to 'Thing'", err))
-		mainType = "Thing"
-		jsonLD["@type"] = mainType
+		classification = Classification{Types: []string{"Thing"}}
+		c.stats.ThingFallbacks++
 	}
+	mainType := classification.MainType()
 	logger.Debug(fmt.Sprintf("Main type determined: %s", mainType))
 	logger.Debug(fmt.Sprintf("Enriched content: %s", enrichedContent))
 	logger.Debug("Handling nested structures")
@@ -71,6 +95,7 @@ func (c *Converter) Convert(ctx context.Context, doc *parser.Document) (map[stri
 	nestedContent, err := c.handleNestedStructures(ctx, enrichedContent, mainType)
 	if err != nil {
 		logger.Warning(fmt.Sprintf("Error handling nested structures: %v. Falling back to flat structure", err))
+		c.stats.Repairs++
 		nestedContent, err = c.extractProperties(ctx, enrichedContent, mainType)
 		if err != nil {
 			logger.Error(fmt.Sprintf("Error extracting properties: %v", err))
@@ -83,6 +108,7 @@ func (c *Converter) Convert(ctx context.Context, doc *parser.Document) (map[stri
 	for key, value := range nestedContent {
 		jsonLD[key] = value
 	}
+	jsonLD["@type"] = classification.TypeValue()
 	logger.Debug("Nested content added to JSON-LD structure")
 
 	logger.Info("Applying additional instructions")
@@ -100,6 +126,15 @@ func (c *Converter) Convert(ctx context.Context, doc *parser.Document) (map[stri
 	}
 	logger.Debug("Final JSON-LD within token limit")
 
+	c.contextManager.EndSegment(ctx)
+
+	c.stats.Segments++
+	if typeName, ok := jsonLD["@type"].(string); ok {
+		c.recordType(typeName, 1)
+	} else {
+		c.recordType(mainType, 1)
+	}
+
 	logger.Info("Conversion process completed successfully")
 	logger.UpdateChunkProgress()
 	return jsonLD, nil
@@ -107,10 +142,10 @@ func (c *Converter) Convert(ctx context.Context, doc *parser.Document) (map[stri
 
 func (c *Converter) enrichContentWithLLM(ctx context.Context, content string) (string, *llm.AnalysisContext, error) {
 	logger.Debug("Enriching content with LLM")
-	prompt := llm.BuildPromptWithContext(content, c.analysisContext)
+	prompt := llm.BuildAnalysisPrompt(content)
 	logger.Debug(fmt.Sprintf("Prepared prompt for LLM:\n%s", prompt))
 
-	enrichedContent, newContext, err := c.llmClient.Analyze(ctx, prompt, c.analysisContext)
+	enrichedContent, newContext, err := c.llmClient.Analyze(ctx, prompt, c.contextManager.Context())
 	if err != nil {
 		logger.Error(fmt.Sprintf("Error calling LLM for content enrichment: %v", err))
 		return "", nil, fmt.Errorf("Error calling LLM for content enrichment : %w", err)
@@ -119,53 +154,16 @@ func (c *Converter) enrichContentWithLLM(ctx context.Context, content string) (s
 	return enrichedContent, newContext, nil
 }
 
-func (c *Converter) determineMainType(ctx context.Context, content string) (string, error) {
+// determineMainType classe le contenu dans la hiérarchie Schema.org (voir
+// Classify) ; un contenu qu'aucun type proposé ne décrit reste un Thing
+func (c *Converter) determineMainType(ctx context.Context, content string) (Classification, error) {
 	logger.Debug("Determining main type")
-	prompt := fmt.Sprintf("Déterminez le type Schema.org le plus approprié pour le contenu suivant. Répondez uniquement avec le nom du type, sans explication : %s", content)
-	response, newContext, err := c.llmClient.Analyze(ctx, prompt, c.analysisContext)
+	classification, err := c.Classify(ctx, content)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Error calling LLM for main type determination: %v", err))
-		return "", fmt.Errorf("erreur lors de l'appel au LLM : %w", err)
+		return Classification{}, err
 	}
-	c.analysisContext = newContext
-
-	mainType := c.extractSchemaOrgType(response)
-
-	if _, ok := c.schemaOrg.GetType(mainType); !ok {
-		logger.Warning(fmt.Sprintf("Type '%s' not found in Schema.org vocabulary. Using 'Thing' as default", mainType))
-		return "Thing", nil
-	}
-
-	logger.Debug(fmt.Sprintf("Main type determined: %s", mainType))
-	return mainType, nil
-}
-
-func (c *Converter) extractSchemaOrgType(response string) string {
-	logger.Debug(fmt.Sprintf("Extracting Schema.org type from LLM response: %s", response))
-
-	// Liste des types Schema.org courants à rechercher
-	commonTypes := []string{"Article", "Person", "Event", "Organization", "Place", "CreativeWork", "Thing"}
-
-	// Vérifier si l'un des types courants est présent dans la réponse
-	for _, t := range commonTypes {
-		if strings.Contains(response, t) {
-			logger.Debug(fmt.Sprintf("Found common Schema.org type: %s", t))
-			return t
-		}
-	}
-
-	// Si aucun type commun n'est trouvé, chercher le premier mot qui pourrait être un type
-	words := strings.Fields(response)
-	for _, word := range words {
-		// Vérifier si le mot commence par une majuscule (potentiel type Schema.org)
-		if len(word) > 0 && unicode.IsUpper(rune(word[0])) {
-			logger.Debug(fmt.Sprintf("Extracted potential Schema.org type: %s", word))
-			return word
-		}
-	}
-
-	logger.Warning("No valid Schema.org type found in response. Defaulting to 'Thing'")
-	return "Thing"
+	logger.Debug(fmt.Sprintf("Main type determined: %s", classification.MainType()))
+	return classification, nil
 }
 
 func (c *Converter) extractProperties(ctx context.Context, content string, mainType string) (map[string]interface{}, error) {
@@ -183,7 +181,7 @@ func (c *Converter) extractProperties(ctx context.Context, content string, mainT
 	
 	Propriétés possibles pour le type '%s' :
 	%s
-	
+	%s
 	Instructions spéciales pour l'extraction des propriétés :
 	
 	1. "mentions" : Liste exhaustive des personnes et entités importantes.
@@ -224,7 +222,7 @@ func (c *Converter) extractProperties(ctx context.Context, content string, mainT
 	
 	Assurez-vous que chaque propriété extraite est aussi détaillée et précise que possible. Si une information n'est pas disponible ou applicable, ne l'incluez pas dans la réponse JSON.
 	
-	N'incluez PAS les propriétés "@context" et "@type" dans votre réponse.`, mainType, content, mainType, strings.Join(schemaType.Properties, ", "))
+	N'incluez PAS les propriétés "@context" et "@type" dans votre réponse.`, mainType, content, mainType, strings.Join(schemaType.Properties, ", "), c.examplesPrompt(mainType, content))
 
 	response, _, err := c.llmClient.Analyze(ctx, prompt, &llm.AnalysisContext{})
 	if err != nil {
@@ -401,6 +399,7 @@ func (c *Converter) handleNestedStructures(ctx context.Context, content string,
 				logger.Warning(fmt.Sprintf("Error getting expected type for property %s: %v. Using 'Thing' as default", key, err))
 				// Stratégie de repli : utiliser "Thing" comme type par défaut pour les propriétés d'objet
 				nestedType = "Thing"
+				c.stats.ThingFallbacks++
 			}
 
 			nestedContent, err := c.extractNestedContent(ctx, content, key)
@@ -408,6 +407,7 @@ func (c *Converter) handleNestedStructures(ctx context.Context, content string,
 				logger.Warning(fmt.Sprintf("Error extracting nested content for property %s: %v. Using simple text value", key, err))
 				// Stratégie de repli : utiliser la valeur extraite comme contenu texte simple
 				jsonLD[key] = value
+				c.stats.Repairs++
 				continue
 			}
 
@@ -516,13 +516,14 @@ func (c *Converter) convertLargeDocument(ctx context.Context, content string) ([
 
 	for i, segment := range segments {
 		logger.Info(fmt.Sprintf("Processing segment %d of %d", i+1, len(segments)))
-		jsonLD, newContext, err := c.convertSegmentWithContext(ctx, segment, c.analysisContext)
+		jsonLD, newContext, err := c.convertSegmentWithContext(ctx, segment, c.contextManager.Context())
 		if err != nil {
 			logger.Error(fmt.Sprintf("Error converting segment %d: %v", i+1, err))
 			return nil, fmt.Errorf("erreur lors de la conversion du segment %d : %w", i+1, err)
 		}
 
-		c.analysisContext = newContext
+		c.contextManager.SetContext(newContext)
+		c.contextManager.EndSegment(ctx)
 
 		jsonLD["segment"] = map[string]interface{}{
 			"index": i + 1,
@@ -539,25 +540,29 @@ func (c *Converter) convertLargeDocument(ctx context.Context, content string) ([
 }
 
 func (c *Converter) convertSegmentWithContext(ctx context.Context, segment string, analysisContext *llm.AnalysisContext) (map[string]interface{}, *llm.AnalysisContext, error) {
-	enrichedContent, newContext, err := c.llmClient.Analyze(ctx, segment, analysisContext)
+	enrichedContent, newContext, err := c.llmClient.Analyze(ctx, llm.BuildAnalysisPrompt(segment), analysisContext)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error enriching content with LLM: %w", err)
 	}
 
-	mainType, err := c.determineMainType(ctx, enrichedContent)
+	classification, err := c.determineMainType(ctx, enrichedContent)
 	if err != nil {
 		logger.Warning(fmt.Sprintf("Error determining main type: %v. Falling back to 'Thing'", err))
-		mainType = "Thing"
+		classification = Classification{Types: []string{"Thing"}}
+		c.stats.ThingFallbacks++
 	}
+	mainType := classification.MainType()
 
 	jsonLD, err := c.handleNestedStructures(ctx, enrichedContent, mainType)
 	if err != nil {
 		logger.Warning(fmt.Sprintf("Error handling nested structures: %v. Falling back to flat structure", err))
+		c.stats.Repairs++
 		jsonLD, err = c.extractProperties(ctx, enrichedContent, mainType)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error extracting properties: %w", err)
 		}
 	}
+	jsonLD["@type"] = classification.TypeValue()
 
 	return jsonLD, newContext, nil
 }
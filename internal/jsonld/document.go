@@ -0,0 +1,112 @@
+package jsonld
+
+import (
+	"strings"
+)
+
+// Part regroupe les nœuds JSON-LD produits pour une partie d'un document
+// (un chapitre d'un livre, un extrait minuté d'une vidéo). Properties complète
+// le nœud de la partie, par exemple avec startOffset et endOffset.
+type Part struct {
+	Name       string
+	Position   int
+	Properties map[string]interface{}
+	Nodes      []map[string]interface{}
+}
+
+// partTypes associe au type du document le type Schema.org de ses parties
+var partTypes = map[string]string{
+	"Book":           "Chapter",
+	"VideoObject":    "Clip",
+	"PodcastEpisode": "Clip",
+}
+
+// DocumentNode construit le nœud racine d'un document dont le type Schema.org est
+// connu par ses métadonnées ("schema_type", "title", "author", "language",
+// "publisher", "isbn", "duration", ou les en-têtes d'un courriel). Les parties
+// sont rattachées par hasPart.
+func DocumentNode(metadata map[string]string, parts []Part) map[string]interface{} {
+	schemaType := metadata["schema_type"]
+	if schemaType == "" {
+		schemaType = "CreativeWork"
+	}
+	node := map[string]interface{}{
+		"@type": schemaType,
+	}
+
+	if schemaType == "EmailMessage" {
+		emailProperties(node, metadata)
+	} else {
+		documentProperties(node, metadata)
+	}
+
+	partType := partTypes[schemaType]
+	if partType == "" {
+		partType = "CreativeWork"
+	}
+	var hasPart []map[string]interface{}
+	for _, part := range parts {
+		if part.Name == "" && part.Position == 0 {
+			// Contenu hors chapitre (couverture, page de titre)
+			hasPart = append(hasPart, part.Nodes...)
+			continue
+		}
+		partNode := map[string]interface{}{
+			"@type":   partType,
+			"hasPart": part.Nodes,
+		}
+		if part.Name != "" {
+			partNode["name"] = part.Name
+		}
+		if part.Position > 0 {
+			partNode["position"] = part.Position
+		}
+		for key, value := range part.Properties {
+			partNode[key] = value
+		}
+		hasPart = append(hasPart, partNode)
+	}
+	if len(hasPart) > 0 {
+		node["hasPart"] = hasPart
+	}
+
+	return node
+}
+
+// documentProperties complète le nœud d'une œuvre (livre, vidéo) à partir de ses
+// métadonnées
+func documentProperties(node map[string]interface{}, metadata map[string]string) {
+	if title := metadata["title"]; title != "" {
+		node["name"] = title
+	}
+	if author := metadata["author"]; author != "" {
+		var authors []map[string]interface{}
+		for _, name := range strings.Split(author, ";") {
+			if name = strings.TrimSpace(name); name != "" {
+				authors = append(authors, map[string]interface{}{"@type": "Person", "name": name})
+			}
+		}
+		if len(authors) == 1 {
+			node["author"] = authors[0]
+		} else if len(authors) > 1 {
+			node["author"] = authors
+		}
+	}
+	if publisher := metadata["publisher"]; publisher != "" {
+		node["publisher"] = map[string]interface{}{"@type": "Organization", "name": publisher}
+	}
+	properties := map[string]string{
+		"language":    "inLanguage",
+		"isbn":        "isbn",
+		"date":        "datePublished",
+		"description": "description",
+		"keywords":    "keywords",
+		"rights":      "copyrightNotice",
+		"duration":    "duration",
+	}
+	for key, property := range properties {
+		if value := metadata[key]; value != "" {
+			node[property] = value
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package jsonld
+
+import (
+	"testing"
+)
+
+func TestDocumentNode(t *testing.T) {
+	metadata := map[string]string{
+		"schema_type": "Book",
+		"title":       "Évangile selon Jean",
+		"author":      "Jean; Luc",
+		"isbn":        "978-2-204-12345-6",
+		"language":    "fr",
+	}
+	parts := []Part{
+		{Nodes: []map[string]interface{}{{"@type": "WebPage"}}},
+		{Name: "Prologue", Position: 1, Nodes: []map[string]interface{}{{"@type": "Article"}}},
+	}
+
+	node := DocumentNode(metadata, parts)
+	if node["@type"] != "Book" || node["name"] != "Évangile selon Jean" || node["isbn"] != "978-2-204-12345-6" || node["inLanguage"] != "fr" {
+		t.Errorf("Unexpected book node: %v", node)
+	}
+	if authors, ok := node["author"].([]map[string]interface{}); !ok || len(authors) != 2 {
+		t.Errorf("Expected 2 authors, got %v", node["author"])
+	}
+
+	hasPart := node["hasPart"].([]map[string]interface{})
+	if len(hasPart) != 2 || hasPart[0]["@type"] != "WebPage" {
+		t.Fatalf("Unexpected parts: %v", hasPart)
+	}
+	if hasPart[1]["@type"] != "Chapter" || hasPart[1]["name"] != "Prologue" || hasPart[1]["position"] != 1 {
+		t.Errorf("Unexpected chapter: %v", hasPart[1])
+	}
+}
@@ -0,0 +1,130 @@
+package jsonld
+
+import (
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chrlesur/json-ld-converter/internal/parser"
+)
+
+// emailProperties complète un nœud EmailMessage à partir des en-têtes du message
+func emailProperties(node map[string]interface{}, metadata map[string]string) {
+	if senders := emailPersons(metadata["from"]); len(senders) > 0 {
+		node["sender"] = senders[0]
+	}
+	if recipients := emailPersons(metadata["to"]); len(recipients) > 0 {
+		node["recipient"] = recipients
+	}
+	if cc := emailPersons(metadata["cc"]); len(cc) > 0 {
+		node["ccRecipient"] = cc
+	}
+	if subject := metadata["subject"]; subject != "" {
+		node["name"] = subject
+	}
+	if date := metadata["date"]; date != "" {
+		node["dateSent"] = date
+	}
+	if id := metadata["message_id"]; id != "" {
+		node["identifier"] = id
+	}
+}
+
+// emailPersons convertit une liste d'adresses en personnes Schema.org
+func emailPersons(header string) []map[string]interface{} {
+	if header == "" {
+		return nil
+	}
+	addresses, err := mail.ParseAddressList(header)
+	if err != nil {
+		return []map[string]interface{}{{"@type": "Person", "name": header}}
+	}
+	persons := make([]map[string]interface{}, 0, len(addresses))
+	for _, address := range addresses {
+		person := map[string]interface{}{"@type": "Person", "email": address.Address}
+		if address.Name != "" {
+			person["name"] = address.Name
+		}
+		persons = append(persons, person)
+	}
+	return persons
+}
+
+var replyPrefixPattern = regexp.MustCompile(`(?i)^\s*((re|fwd?|tr|aw|wg)\s*(\[\d+\])?\s*:\s*)+`)
+
+// EmailThreads regroupe les nœuds EmailMessage de plusieurs messages en fils de
+// discussion (Conversation) d'après les en-têtes In-Reply-To et References.
+// Les messages de chaque fil sont triés par date d'envoi.
+func EmailThreads(docs []*parser.Document, nodes []map[string]interface{}) []map[string]interface{} {
+	parent := make([]int, len(docs))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	byID := make(map[string]int)
+	for i, doc := range docs {
+		if id := doc.Metadata["message_id"]; id != "" {
+			byID[id] = i
+		}
+	}
+	for i, doc := range docs {
+		refs := strings.Fields(doc.Metadata["references"])
+		refs = append(refs, doc.Metadata["in_reply_to"])
+		for _, ref := range refs {
+			if j, ok := byID[ref]; ok {
+				a, b := find(i), find(j)
+				if a < b {
+					parent[b] = a
+				} else {
+					parent[a] = b
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	var roots []int
+	for i := range docs {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+
+	threads := make([]map[string]interface{}, 0, len(roots))
+	for _, root := range roots {
+		members := groups[root]
+		sort.SliceStable(members, func(a, b int) bool {
+			return emailDate(docs[members[a]]).Before(emailDate(docs[members[b]]))
+		})
+
+		messages := make([]map[string]interface{}, 0, len(members))
+		for _, i := range members {
+			messages = append(messages, nodes[i])
+		}
+		thread := map[string]interface{}{
+			"@type":   "Conversation",
+			"hasPart": messages,
+		}
+		if subject := replyPrefixPattern.ReplaceAllString(docs[members[0]].Metadata["subject"], ""); subject != "" {
+			thread["name"] = subject
+		}
+		threads = append(threads, thread)
+	}
+	return threads
+}
+
+func emailDate(doc *parser.Document) time.Time {
+	date, _ := time.Parse(time.RFC3339, doc.Metadata["date"])
+	return date
+}
@@ -0,0 +1,36 @@
+package jsonld
+
+import (
+	"testing"
+
+	"github.com/chrlesur/json-ld-converter/internal/parser"
+)
+
+func TestEmailThreads(t *testing.T) {
+	docs := []*parser.Document{
+		{Metadata: map[string]string{"schema_type": "EmailMessage", "message_id": "2", "in_reply_to": "1", "subject": "Re: Projet", "date": "1948-12-11T09:00:00Z"}},
+		{Metadata: map[string]string{"schema_type": "EmailMessage", "message_id": "3", "subject": "Autre sujet", "date": "1948-12-12T09:00:00Z"}},
+		{Metadata: map[string]string{"schema_type": "EmailMessage", "message_id": "1", "subject": "Projet", "date": "1948-12-10T15:04:05+01:00", "from": "René Cassin <rene@example.org>", "to": "a@example.org, b@example.org"}},
+	}
+	var nodes []map[string]interface{}
+	for _, doc := range docs {
+		nodes = append(nodes, DocumentNode(doc.Metadata, nil))
+	}
+
+	sender := nodes[2]["sender"].(map[string]interface{})
+	if sender["name"] != "René Cassin" || sender["email"] != "rene@example.org" || len(nodes[2]["recipient"].([]map[string]interface{})) != 2 {
+		t.Errorf("Unexpected email node: %v", nodes[2])
+	}
+
+	threads := EmailThreads(docs, nodes)
+	if len(threads) != 2 {
+		t.Fatalf("Expected 2 threads, got %d", len(threads))
+	}
+	messages := threads[0]["hasPart"].([]map[string]interface{})
+	if threads[0]["@type"] != "Conversation" || threads[0]["name"] != "Projet" || len(messages) != 2 {
+		t.Fatalf("Unexpected thread: %v", threads[0])
+	}
+	if messages[0]["identifier"] != "1" {
+		t.Errorf("Messages should be sorted by date, got %v first", messages[0]["identifier"])
+	}
+}
@@ -13,6 +13,10 @@ func (e *ConversionError) Error() string {
 	return fmt.Sprintf("erreur de conversion lors de l'étape '%s': %v", e.Stage, e.Err)
 }
 
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
 type TokenLimitError struct {
 	Limit int
 	Count int
@@ -0,0 +1,48 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/chrlesur/json-ld-converter/internal/examples"
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+)
+
+// SetExamples fournit au convertisseur une bibliothèque d'exemples : les plus
+// pertinents pour chaque segment, dans la limite de budget tokens, sont ajoutés
+// au prompt d'extraction des propriétés
+func (c *Converter) SetExamples(lib *examples.Library, budget int) {
+	c.examples = lib
+	c.exampleBudget = budget
+}
+
+// examplesPrompt construit la partie du prompt d'extraction qui présente les
+// exemples retenus pour le contenu ; elle est vide sans exemple pertinent
+func (c *Converter) examplesPrompt(mainType, content string) string {
+	if c.examples == nil || c.exampleBudget <= 0 {
+		return ""
+	}
+	selected := c.examples.Select(mainType, c.language, content, c.exampleBudget)
+	if len(selected) == 0 {
+		return ""
+	}
+	logger.Debug(fmt.Sprintf("Adding %d examples to the extraction prompt for type %s", len(selected), mainType))
+
+	var b strings.Builder
+	b.WriteString("\n\tExemples de contenus similaires et du JSON attendu (inspirez-vous de leur structure et de leur niveau de détail, pas de leurs valeurs) :\n")
+	for i, example := range selected {
+		output := make(map[string]interface{}, len(example.Output))
+		for key, value := range example.Output {
+			if key != "@context" && key != "@type" {
+				output[key] = value
+			}
+		}
+		data, err := json.Marshal(output)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\tExemple %d (%s) :\n\tContenu : %s\n\tJSON attendu : %s\n", i+1, example.Type, example.Input, data)
+	}
+	return b.String()
+}
@@ -0,0 +1,43 @@
+package jsonld
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chrlesur/json-ld-converter/internal/examples"
+	"github.com/chrlesur/json-ld-converter/internal/schema"
+)
+
+func TestExtractPropertiesWithExamples(t *testing.T) {
+	schemaOrg := &schema.SchemaOrg{Types: map[string]schema.SchemaType{
+		"schema:Person": {ID: "schema:Person", Properties: []string{"schema:name", "schema:birthPlace"}},
+	}}
+	client := &fakeClient{response: `{"name": "Pierre Curie"}`}
+	conv := NewConverter(schemaOrg, client, 1000, "")
+
+	if _, err := conv.extractProperties(context.Background(), "Pierre Curie, physicien né à Paris.", "Person"); err != nil {
+		t.Fatalf("extractProperties() error = %v", err)
+	}
+	if strings.Contains(client.prompts[0], "Exemple 1") {
+		t.Error("Prompt should not contain examples without a library")
+	}
+
+	lib := &examples.Library{}
+	lib.Add(examples.Example{
+		Type:   "Person",
+		Input:  "Marie Curie, physicienne née à Varsovie.",
+		Output: map[string]interface{}{"@type": "Person", "name": "Marie Curie", "birthPlace": "Varsovie"},
+	})
+	conv.SetExamples(lib, 500)
+	if _, err := conv.extractProperties(context.Background(), "Pierre Curie, physicien né à Paris.", "Person"); err != nil {
+		t.Fatalf("extractProperties() error = %v", err)
+	}
+	prompt := client.prompts[1]
+	if !strings.Contains(prompt, "Exemple 1 (Person)") || !strings.Contains(prompt, "Marie Curie, physicienne née à Varsovie.") || !strings.Contains(prompt, `"birthPlace":"Varsovie"`) {
+		t.Errorf("Prompt should contain the example:\n%s", prompt)
+	}
+	if strings.Contains(prompt, `"@type":"Person"`) {
+		t.Error("Example output should not repeat @type")
+	}
+}
@@ -0,0 +1,275 @@
+package jsonld
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// ColumnMapping associe une colonne d'un tableau à une propriété Schema.org.
+// Une propriété pointée ("offers.price") crée un objet imbriqué dont Type est le
+// type ; pour une propriété simple, Type crée un objet {"@type", "name"}.
+// Les colonnes marquées Interpret sont converties par le LLM.
+type ColumnMapping struct {
+	Column    string `yaml:"column" json:"column"`
+	Property  string `yaml:"property,omitempty" json:"property,omitempty"`
+	Type      string `yaml:"type,omitempty" json:"type,omitempty"`
+	Interpret bool   `yaml:"interpret,omitempty" json:"interpret,omitempty"`
+}
+
+// TableMapping décrit la conversion de chaque ligne d'un tableau en un nœud de
+// type Type
+type TableMapping struct {
+	Type    string          `yaml:"type" json:"type"`
+	Columns []ColumnMapping `yaml:"columns" json:"columns"`
+}
+
+// tableInterpretBatch est le nombre de lignes envoyées par appel au LLM pour les
+// colonnes à interpréter
+const tableInterpretBatch = 20
+
+// LoadTableMapping lit une correspondance au format YAML ou JSON
+func LoadTableMapping(path string) (*TableMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading table mapping: %w", err)
+	}
+	var mapping TableMapping
+	if err := yaml.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("error parsing table mapping: %w", err)
+	}
+	if mapping.Type == "" {
+		return nil, fmt.Errorf("table mapping %s has no type", path)
+	}
+	return &mapping, nil
+}
+
+// SaveTableMapping enregistre une correspondance pour qu'elle puisse être
+// relue et corrigée
+func SaveTableMapping(path string, mapping *TableMapping) error {
+	data, err := json.MarshalIndent(mapping, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling table mapping: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing table mapping: %w", err)
+	}
+	return nil
+}
+
+// ProposeTableMapping demande au LLM, une seule fois par tableau, le type des
+// lignes et la propriété correspondant à chaque colonne d'après l'en-tête et un
+// échantillon de lignes
+func (c *Converter) ProposeTableMapping(ctx context.Context, headers []string, sample [][]string) (*TableMapping, error) {
+	var lines []string
+	lines = append(lines, strings.Join(headers, " | "))
+	for _, row := range sample {
+		lines = append(lines, strings.Join(row, " | "))
+	}
+
+	prompt := fmt.Sprintf(`Voici l'en-tête et quelques lignes d'un tableau :
+
+%s
+
+Déterminez le type Schema.org décrit par chaque ligne, puis associez chaque colonne à une propriété Schema.org de ce type. Retournez UNIQUEMENT un objet JSON valide de la forme :
+{"type": "Product", "columns": [{"column": "Nom de la colonne", "property": "name"}, {"column": "Prix", "property": "offers.price", "type": "Offer"}, {"column": "Description libre", "property": "description", "interpret": true}]}
+
+Règles :
+- "property" peut être pointée pour une propriété d'un objet imbriqué ; "type" est alors le type de cet objet.
+- Pour une propriété simple, "type" indique que la valeur est le nom d'un objet de ce type (par exemple "Place" pour une ville).
+- "interpret" vaut true uniquement si la valeur doit être interprétée (texte libre à découper, unités, dates ambiguës) au lieu d'être recopiée.
+- Omettez "property" pour les colonnes sans équivalent Schema.org.`, strings.Join(lines, "\n"))
+
+	response, _, err := c.llmClient.Analyze(ctx, prompt, &llm.AnalysisContext{})
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de l'analyse LLM : %w", err)
+	}
+
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("aucun JSON trouvé dans la réponse LLM")
+	}
+	var mapping TableMapping
+	if err := json.Unmarshal([]byte(response[start:end+1]), &mapping); err != nil {
+		return nil, fmt.Errorf("erreur lors du parsing de la correspondance : %w", err)
+	}
+
+	if _, ok := c.schemaOrg.GetType(mapping.Type); !ok {
+		logger.Warning(fmt.Sprintf("Type '%s' not found in Schema.org vocabulary. Using 'Thing' as row type", mapping.Type))
+		mapping.Type = "Thing"
+		c.stats.ThingFallbacks++
+	}
+	logger.Info(fmt.Sprintf("Proposed table mapping: %d columns mapped to type %s", len(mapping.Columns), mapping.Type))
+	return &mapping, nil
+}
+
+// MapRow convertit une ligne en nœud JSON-LD selon la correspondance, sans appel
+// au LLM ; les colonnes à interpréter et les valeurs vides sont ignorées
+func MapRow(mapping *TableMapping, headers, row []string) map[string]interface{} {
+	node := map[string]interface{}{"@type": mapping.Type}
+	index := make(map[string]int)
+	for i, h := range headers {
+		index[h] = i
+	}
+
+	for _, col := range mapping.Columns {
+		i, ok := index[col.Column]
+		if !ok || col.Property == "" || col.Interpret || i >= len(row) {
+			continue
+		}
+		value := strings.TrimSpace(row[i])
+		if value == "" {
+			continue
+		}
+
+		property := col.Property
+		if dot := strings.Index(property, "."); dot > 0 {
+			parent := property[:dot]
+			property = property[dot+1:]
+			nested, ok := node[parent].(map[string]interface{})
+			if !ok {
+				nested = make(map[string]interface{})
+				if col.Type != "" {
+					nested["@type"] = col.Type
+				}
+				node[parent] = nested
+			}
+			addPropertyValue(nested, property, value)
+			continue
+		}
+
+		var v interface{} = value
+		if col.Type != "" {
+			v = map[string]interface{}{"@type": col.Type, "name": value}
+		}
+		addPropertyValue(node, property, v)
+	}
+	return node
+}
+
+// addPropertyValue ajoute une valeur ; plusieurs colonnes associées à la même
+// propriété produisent une liste
+func addPropertyValue(node map[string]interface{}, property string, value interface{}) {
+	existing, ok := node[property]
+	if !ok {
+		node[property] = value
+		return
+	}
+	if list, ok := existing.([]interface{}); ok {
+		node[property] = append(list, value)
+		return
+	}
+	node[property] = []interface{}{existing, value}
+}
+
+// ConvertTable convertit chaque ligne d'un tableau en nœud typé. Les colonnes
+// marquées Interpret sont envoyées au LLM par lots de lignes ; les autres sont
+// recopiées directement.
+func (c *Converter) ConvertTable(ctx context.Context, mapping *TableMapping, headers []string, rows [][]string) ([]map[string]interface{}, error) {
+	nodes := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		nodes[i] = MapRow(mapping, headers, row)
+	}
+	c.recordType(mapping.Type, len(rows))
+
+	var interpreted []ColumnMapping
+	for _, col := range mapping.Columns {
+		if col.Interpret {
+			interpreted = append(interpreted, col)
+		}
+	}
+	if len(interpreted) == 0 {
+		return nodes, nil
+	}
+
+	index := make(map[string]int)
+	for i, h := range headers {
+		index[h] = i
+	}
+	for start := 0; start < len(rows); start += tableInterpretBatch {
+		end := start + tableInterpretBatch
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		batch := make([]map[string]string, 0, end-start)
+		for _, row := range rows[start:end] {
+			values := make(map[string]string)
+			for _, col := range interpreted {
+				if i, ok := index[col.Column]; ok && i < len(row) && strings.TrimSpace(row[i]) != "" {
+					values[col.Column] = row[i]
+				}
+			}
+			batch = append(batch, values)
+		}
+
+		properties, err := c.interpretColumns(ctx, mapping, interpreted, batch)
+		if err != nil {
+			logger.Warning(fmt.Sprintf("Unable to interpret columns for rows %d-%d: %v", start+1, end, err))
+			continue
+		}
+		for i, props := range properties {
+			for key, value := range props {
+				if _, exists := nodes[start+i][key]; !exists && key != "@type" {
+					nodes[start+i][key] = value
+				}
+			}
+		}
+		logger.UpdateChunkProgress()
+	}
+
+	return nodes, nil
+}
+
+// interpretColumns convertit en un seul appel les valeurs à interpréter d'un lot
+// de lignes ; la réponse contient un objet de propriétés par ligne
+func (c *Converter) interpretColumns(ctx context.Context, mapping *TableMapping, columns []ColumnMapping, batch []map[string]string) ([]map[string]interface{}, error) {
+	var hints []string
+	for _, col := range columns {
+		hint := fmt.Sprintf("- %s", col.Column)
+		if col.Property != "" {
+			hint += fmt.Sprintf(" (propriété suggérée : %s)", col.Property)
+		}
+		hints = append(hints, hint)
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt := fmt.Sprintf(`Chaque objet de la liste suivante contient des valeurs de colonnes d'un tableau décrivant des objets de type '%s' :
+
+%s
+
+Colonnes :
+%s
+
+Convertissez chaque objet en propriétés Schema.org du type '%s'. Retournez UNIQUEMENT un tableau JSON contenant exactement %d objets, dans le même ordre, sans "@context" ni "@type".`,
+		mapping.Type, string(data), strings.Join(hints, "\n"), mapping.Type, len(batch))
+
+	response, _, err := c.llmClient.Analyze(ctx, prompt, &llm.AnalysisContext{})
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de l'analyse LLM : %w", err)
+	}
+
+	start := strings.Index(response, "[")
+	end := strings.LastIndex(response, "]")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("aucun tableau JSON trouvé dans la réponse LLM")
+	}
+	var properties []map[string]interface{}
+	if err := json.Unmarshal([]byte(response[start:end+1]), &properties); err != nil {
+		return nil, fmt.Errorf("erreur lors du parsing de la réponse JSON : %w", err)
+	}
+	if len(properties) != len(batch) {
+		return nil, fmt.Errorf("le LLM a retourné %d objets pour %d lignes", len(properties), len(batch))
+	}
+	return properties, nil
+}
@@ -0,0 +1,90 @@
+package jsonld
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+)
+
+type fakeClient struct {
+	response string
+	prompts  []string
+}
+
+func (f *fakeClient) Analyze(ctx context.Context, content string, analysisContext *llm.AnalysisContext) (string, *llm.AnalysisContext, error) {
+	f.prompts = append(f.prompts, content)
+	return f.response, analysisContext, nil
+}
+
+var productMapping = &TableMapping{
+	Type: "Product",
+	Columns: []ColumnMapping{
+		{Column: "Nom", Property: "name"},
+		{Column: "Prix", Property: "offers.price", Type: "Offer"},
+		{Column: "Devise", Property: "offers.priceCurrency", Type: "Offer"},
+		{Column: "Ville", Property: "countryOfOrigin", Type: "Place"},
+		{Column: "Tag 1", Property: "keywords"},
+		{Column: "Tag 2", Property: "keywords"},
+		{Column: "Notes", Property: "description", Interpret: true},
+	},
+}
+
+func TestMapRow(t *testing.T) {
+	headers := []string{"Nom", "Prix", "Devise", "Ville", "Tag 1", "Tag 2", "Notes"}
+	node := MapRow(productMapping, headers, []string{"Pain", "2.50", "EUR", "Lyon", "boulangerie", "bio", "à interpréter"})
+
+	offers := node["offers"].(map[string]interface{})
+	if node["@type"] != "Product" || node["name"] != "Pain" || offers["@type"] != "Offer" || offers["price"] != "2.50" || offers["priceCurrency"] != "EUR" {
+		t.Errorf("Unexpected node: %v", node)
+	}
+	if place := node["countryOfOrigin"].(map[string]interface{}); place["@type"] != "Place" || place["name"] != "Lyon" {
+		t.Errorf("Unexpected place: %v", place)
+	}
+	if keywords, ok := node["keywords"].([]interface{}); !ok || len(keywords) != 2 {
+		t.Errorf("Expected keywords list, got %v", node["keywords"])
+	}
+	if _, ok := node["description"]; ok {
+		t.Error("Interpreted columns should not be copied")
+	}
+}
+
+func TestConvertTableInterpretsInBatches(t *testing.T) {
+	client := &fakeClient{response: `[{"description": "Pain au levain"}, {"description": "Viennoiserie"}]`}
+	conv := NewConverter(nil, client, 1000, "")
+
+	headers := []string{"Nom", "Notes"}
+	rows := [][]string{{"Pain", "levain"}, {"Croissant", "beurre"}}
+	nodes, err := conv.ConvertTable(context.Background(), productMapping, headers, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.prompts) != 1 {
+		t.Fatalf("Expected one LLM call for the batch, got %d", len(client.prompts))
+	}
+	if strings.Contains(client.prompts[0], "Croissant") {
+		t.Error("Only columns to interpret should be sent to the LLM")
+	}
+	if nodes[1]["name"] != "Croissant" || nodes[1]["description"] != "Viennoiserie" {
+		t.Errorf("Unexpected node: %v", nodes[1])
+	}
+	if stats := conv.Stats(); stats.Types["Product"] != 2 {
+		t.Errorf("Expected 2 Product nodes in stats, got %v", stats.Types)
+	}
+}
+
+func TestSaveAndLoadTableMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mapping.json")
+	if err := SaveTableMapping(path, productMapping); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadTableMapping(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Type != "Product" || len(loaded.Columns) != len(productMapping.Columns) || !loaded.Columns[6].Interpret {
+		t.Errorf("Loaded mapping does not match: %+v", loaded)
+	}
+}
@@ -0,0 +1,39 @@
+package jsonld
+
+// Stats décrit le déroulement des conversions menées par un Converter
+type Stats struct {
+	Segments       int            `json:"segments"`        // segments convertis
+	Types          map[string]int `json:"types"`           // nombre de nœuds par type principal
+	ThingFallbacks int            `json:"thing_fallbacks"` // types inconnus ou indéterminés remplacés par Thing
+	Repairs        int            `json:"repairs"`         // extractions reprises par une stratégie de repli
+}
+
+// Add cumule d'autres statistiques
+func (s *Stats) Add(other Stats) {
+	s.Segments += other.Segments
+	s.ThingFallbacks += other.ThingFallbacks
+	s.Repairs += other.Repairs
+	for t, n := range other.Types {
+		if s.Types == nil {
+			s.Types = make(map[string]int)
+		}
+		s.Types[t] += n
+	}
+}
+
+// Stats retourne les statistiques des conversions déjà menées
+func (c *Converter) Stats() Stats {
+	var stats Stats
+	stats.Add(c.stats)
+	return stats
+}
+
+func (c *Converter) recordType(typeName string, count int) {
+	if typeName == "" || count == 0 {
+		return
+	}
+	if c.stats.Types == nil {
+		c.stats.Types = make(map[string]int)
+	}
+	c.stats.Types[typeName] += count
+}
@@ -0,0 +1,153 @@
+package jsonld
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+)
+
+// SeedStructuredData ajoute au contexte d'analyse les entités nommées des données
+// structurées déjà déclarées par le document, afin que le LLM les réutilise au
+// lieu de les redécouvrir
+func (c *Converter) SeedStructuredData(items []map[string]interface{}) {
+	if len(items) == 0 {
+		return
+	}
+	ac := c.contextManager.Context().Clone()
+	count := 0
+	var visit func(item map[string]interface{})
+	visit = func(item map[string]interface{}) {
+		if name, ok := item["name"].(string); ok && strings.TrimSpace(name) != "" {
+			name = strings.TrimSpace(name)
+			entity := llm.Entity{Name: name, Type: firstType(item), LastSeen: ac.Segment}
+			if description, ok := item["description"].(string); ok {
+				entity.Description = description
+			}
+			ac.PreviousEntities[strings.ToLower(name)] = entity
+			count++
+		}
+		for _, value := range item {
+			for _, nested := range nestedItems(value) {
+				visit(nested)
+			}
+		}
+	}
+	for _, item := range items {
+		visit(item)
+	}
+	c.contextManager.SetContext(ac)
+	logger.Info(fmt.Sprintf("Seeded analysis context with %d entities from existing structured data", count))
+}
+
+// MergeStructuredData fusionne les données structurées déclarées par le document
+// avec les nœuds produits par la conversion. Un item déclaré complète le premier
+// nœud produit de même @id, ou de même type et de même nom ; ses valeurs priment
+// sur celles déduites par le LLM. Les items sans correspondance sont ajoutés.
+func MergeStructuredData(declared, generated []map[string]interface{}) []map[string]interface{} {
+	results := make([]map[string]interface{}, len(generated))
+	copy(results, generated)
+	merged := make([]bool, len(results))
+
+	for _, item := range declared {
+		found := false
+		for i, node := range results {
+			if !merged[i] && sameEntity(item, node) {
+				results[i] = mergeNode(node, item)
+				merged[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			results = append(results, item)
+			merged = append(merged, true)
+		}
+	}
+	return results
+}
+
+func sameEntity(a, b map[string]interface{}) bool {
+	if id, ok := a["@id"].(string); ok && id != "" {
+		if other, ok := b["@id"].(string); ok {
+			return id == other
+		}
+	}
+	if !sharesType(a, b) {
+		return false
+	}
+	name, hasName := a["name"].(string)
+	other, otherHasName := b["name"].(string)
+	return !hasName || !otherHasName || strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(other))
+}
+
+func sharesType(a, b map[string]interface{}) bool {
+	for _, t := range nodeTypes(a) {
+		for _, other := range nodeTypes(b) {
+			if t == other {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nodeTypes retourne le ou les types d'un nœud
+func nodeTypes(node map[string]interface{}) []string {
+	switch t := node["@type"].(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		var result []string
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func firstType(node map[string]interface{}) string {
+	if t := nodeTypes(node); len(t) > 0 {
+		return t[0]
+	}
+	return ""
+}
+
+// mergeNode retourne une copie de node complétée par les valeurs de declared ;
+// les objets présents des deux côtés sont fusionnés récursivement
+func mergeNode(node, declared map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(node)+len(declared))
+	for key, value := range node {
+		result[key] = value
+	}
+	for key, value := range declared {
+		existing, ok := result[key].(map[string]interface{})
+		nested, isMap := value.(map[string]interface{})
+		if ok && isMap {
+			result[key] = mergeNode(existing, nested)
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
+
+func nestedItems(value interface{}) []map[string]interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{v}
+	case []interface{}:
+		var items []map[string]interface{}
+		for _, element := range v {
+			if item, ok := element.(map[string]interface{}); ok {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+	return nil
+}
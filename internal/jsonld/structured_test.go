@@ -0,0 +1,47 @@
+package jsonld
+
+import (
+	"testing"
+)
+
+func TestMergeStructuredData(t *testing.T) {
+	declared := []map[string]interface{}{
+		{"@type": "Article", "headline": "Titre déclaré", "author": map[string]interface{}{"@type": "Person", "name": "Marie"}},
+		{"@type": "Organization", "name": "ACME"},
+	}
+	generated := []map[string]interface{}{
+		{"@type": "Person", "name": "Jean"},
+		{"@type": "Article", "headline": "Titre déduit", "description": "Résumé", "author": map[string]interface{}{"@type": "Person", "description": "Journaliste"}},
+	}
+
+	results := MergeStructuredData(declared, generated)
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 nodes, got %d", len(results))
+	}
+	article := results[1]
+	if article["headline"] != "Titre déclaré" || article["description"] != "Résumé" {
+		t.Errorf("Declared values should complete the generated node: %v", article)
+	}
+	author := article["author"].(map[string]interface{})
+	if author["name"] != "Marie" || author["description"] != "Journaliste" {
+		t.Errorf("Nested objects should be merged: %v", author)
+	}
+	if results[2]["name"] != "ACME" {
+		t.Errorf("Unmatched declared item should be appended: %v", results[2])
+	}
+	if generated[1]["headline"] != "Titre déduit" {
+		t.Error("Generated nodes should not be modified")
+	}
+}
+
+func TestSeedStructuredData(t *testing.T) {
+	conv := NewConverter(nil, &fakeClient{}, 1000, "")
+	conv.SeedStructuredData([]map[string]interface{}{
+		{"@type": "Recipe", "name": "Tarte", "author": map[string]interface{}{"@type": "Person", "name": "Marie", "description": "Cheffe"}},
+	})
+
+	entities := conv.AnalysisContext().PreviousEntities
+	if entities["tarte"].Type != "Recipe" || entities["marie"].Description != "Cheffe" {
+		t.Errorf("Unexpected seeded entities: %+v", entities)
+	}
+}
@@ -0,0 +1,105 @@
+package jsonld
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chrlesur/json-ld-converter/internal/schema"
+)
+
+// Validate vérifie un document JSON-LD par rapport au vocabulaire Schema.org :
+// présence du contexte, type des nœuds du graphe, types et propriétés connus.
+// Chaque problème est précédé du chemin du nœud concerné (@graph[0].author).
+func Validate(schemaOrg *schema.SchemaOrg, doc map[string]interface{}) []string {
+	var problems []string
+	if _, ok := doc["@context"]; !ok {
+		problems = append(problems, "@context manquant")
+	}
+
+	nodes, ok := doc["@graph"]
+	if !ok {
+		return validateNode(schemaOrg, doc, "", true, problems)
+	}
+	for i, item := range listItems(nodes) {
+		path := fmt.Sprintf("@graph[%d]", i)
+		if node, ok := item.(map[string]interface{}); ok {
+			problems = validateNode(schemaOrg, node, path, true, problems)
+		} else {
+			problems = append(problems, fmt.Sprintf("%s : le nœud n'est pas un objet", path))
+		}
+	}
+	return problems
+}
+
+// validateNode vérifie un nœud et ses nœuds imbriqués ; le type n'est exigé que
+// pour les nœuds du graphe
+func validateNode(schemaOrg *schema.SchemaOrg, node map[string]interface{}, path string, root bool, problems []string) []string {
+	label := path
+	if label == "" {
+		label = "document"
+	}
+
+	types := nodeTypes(node)
+	if len(types) == 0 && root {
+		problems = append(problems, fmt.Sprintf("%s : @type manquant", label))
+	}
+	for _, t := range types {
+		if _, ok := schemaOrg.Types["schema:"+schemaName(t)]; !ok {
+			problems = append(problems, fmt.Sprintf("%s : type inconnu de Schema.org : %s", label, t))
+		}
+	}
+
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.HasPrefix(key, "@") {
+			continue
+		}
+		childPath := key
+		if path != "" {
+			childPath = path + "." + key
+		}
+		if _, ok := schemaOrg.Properties["schema:"+schemaName(key)]; !ok {
+			problems = append(problems, fmt.Sprintf("%s : propriété inconnue de Schema.org", childPath))
+		}
+
+		if nested, ok := node[key].(map[string]interface{}); ok {
+			problems = validateNode(schemaOrg, nested, childPath, false, problems)
+			continue
+		}
+		for i, item := range listItems(node[key]) {
+			if nested, ok := item.(map[string]interface{}); ok {
+				problems = validateNode(schemaOrg, nested, fmt.Sprintf("%s[%d]", childPath, i), false, problems)
+			}
+		}
+	}
+	return problems
+}
+
+// listItems retourne les éléments d'une valeur multiple, qu'elle provienne d'un
+// document décodé ou produit par le convertisseur
+func listItems(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case []map[string]interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return items
+	}
+	return nil
+}
+
+// schemaName retire le préfixe ou l'espace de noms Schema.org d'un terme
+func schemaName(term string) string {
+	for _, prefix := range []string{"schema:", "https://schema.org/", "http://schema.org/"} {
+		term = strings.TrimPrefix(term, prefix)
+	}
+	return term
+}
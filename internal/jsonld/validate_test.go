@@ -0,0 +1,51 @@
+package jsonld
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chrlesur/json-ld-converter/internal/schema"
+)
+
+func TestValidate(t *testing.T) {
+	schemaOrg := &schema.SchemaOrg{
+		Types: map[string]schema.SchemaType{
+			"schema:Article": {ID: "schema:Article"},
+			"schema:Person":  {ID: "schema:Person"},
+		},
+		Properties: map[string]schema.SchemaProperty{
+			"schema:author":   {ID: "schema:author"},
+			"schema:headline": {ID: "schema:headline"},
+			"schema:name":     {ID: "schema:name"},
+		},
+	}
+
+	valid := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@graph": []map[string]interface{}{
+			{"@type": "Article", "headline": "Titre", "author": []interface{}{
+				map[string]interface{}{"@type": "schema:Person", "name": "Marie"},
+			}},
+		},
+	}
+	if problems := Validate(schemaOrg, valid); len(problems) != 0 {
+		t.Errorf("Validate() = %v, want no problem", problems)
+	}
+
+	invalid := map[string]interface{}{
+		"@graph": []interface{}{
+			map[string]interface{}{"headline": "Titre", "author": map[string]interface{}{"@type": "Human", "nickname": "M."}},
+			"texte",
+		},
+	}
+	want := []string{
+		"@context manquant",
+		"@graph[0] : @type manquant",
+		"@graph[0].author : type inconnu de Schema.org : Human",
+		"@graph[0].author.nickname : propriété inconnue de Schema.org",
+		"@graph[1] : le nœud n'est pas un objet",
+	}
+	if problems := Validate(schemaOrg, invalid); !reflect.DeepEqual(problems, want) {
+		t.Errorf("Validate() = %q, want %q", problems, want)
+	}
+}
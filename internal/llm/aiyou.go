@@ -15,17 +15,19 @@ import (
 const AIYOUAPIURL = "https://ai.dragonflygroup.fr/api"
 
 type AIYOUClient struct {
-    Token       string
-    AssistantID string
-    Timeout     time.Duration
-    HTTPClient  *http.Client
+    Token        string
+    AssistantID  string
+    Capabilities ModelCapabilities
+    Timeout      time.Duration
+    HTTPClient   *http.Client
 }
 
-func NewAIYOUClient(assistantID string, timeout time.Duration) *AIYOUClient {
+func NewAIYOUClient(assistantID string, capabilities ModelCapabilities, timeout time.Duration) *AIYOUClient {
     return &AIYOUClient{
-        AssistantID: assistantID,
-        Timeout:     timeout,
-        HTTPClient:  &http.Client{Timeout: timeout},
+        AssistantID:  assistantID,
+        Capabilities: capabilities,
+        Timeout:      timeout,
+        HTTPClient:   &http.Client{Timeout: timeout},
     }
 }
 
@@ -57,7 +59,11 @@ func (c *AIYOUClient) Login(email, password string) error {
 
 func (c *AIYOUClient) Analyze(ctx context.Context, content string, analysisContext *AnalysisContext) (string, *AnalysisContext, error) {
     logger.Debug("Starting analysis with AI.YOU API")
-    prompt := BuildPromptWithContext(content, analysisContext)
+    prompt, err := PreparePrompt(content, analysisContext, c.Capabilities, c.AssistantID)
+    if err != nil {
+        logger.Error(fmt.Sprintf("Prompt rejected before calling AI.YOU API: %v", err))
+        return "", nil, err
+    }
 
     logger.Debug(fmt.Sprintf("Prepared prompt for AI.YOU API:\n%s", prompt))
 
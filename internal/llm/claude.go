@@ -18,22 +18,22 @@ const ClaudeAPIURL = "https://api.anthropic.com/v1/messages"
 
 // ClaudeClient implémente l'interface LLMClient pour le modèle Claude d'Anthropic
 type ClaudeClient struct {
-	APIKey      string
-	Model       string
-	ContextSize int
-	Timeout     time.Duration
-	HTTPClient  *http.Client
+	APIKey       string
+	Model        string
+	Capabilities ModelCapabilities
+	Timeout      time.Duration
+	HTTPClient   *http.Client
 }
 
 // NewClaudeClient crée et retourne une nouvelle instance de ClaudeClient
-func NewClaudeClient(apiKey, model string, contextSize int, timeout time.Duration) *ClaudeClient {
-	logger.Info(fmt.Sprintf("Creating new ClaudeClient with model: %s, contextSize: %d, timeout: %v", model, contextSize, timeout))
+func NewClaudeClient(apiKey, model string, capabilities ModelCapabilities, timeout time.Duration) *ClaudeClient {
+	logger.Info(fmt.Sprintf("Creating new ClaudeClient with model: %s, context window: %d, max output: %d, timeout: %v", model, capabilities.ContextWindow, capabilities.MaxOutputTokens, timeout))
 	return &ClaudeClient{
-		APIKey:      apiKey,
-		Model:       model,
-		ContextSize: contextSize,
-		Timeout:     timeout,
-		HTTPClient:  &http.Client{Timeout: timeout},
+		APIKey:       apiKey,
+		Model:        model,
+		Capabilities: capabilities,
+		Timeout:      timeout,
+		HTTPClient:   &http.Client{Timeout: timeout},
 	}
 }
 
@@ -59,7 +59,11 @@ type claudeResponse struct {
 // Analyze implémente la méthode de l'interface LLMClient pour Claude
 func (c *ClaudeClient) Analyze(ctx context.Context, content string, analysisContext *AnalysisContext) (string, *AnalysisContext, error) {
 	logger.Debug("Starting analysis with Claude API")
-	prompt := BuildPromptWithContext(content, analysisContext)
+	prompt, err := PreparePrompt(content, analysisContext, c.Capabilities, c.Model)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Prompt rejected before calling Claude API: %v", err))
+		return "", nil, err
+	}
 
 	logger.Debug(fmt.Sprintf("Prepared prompt for Claude API (%d tokens)", tokenizer.CountTokens(prompt)))
 
@@ -68,7 +72,7 @@ func (c *ClaudeClient) Analyze(ctx context.Context, content string, analysisCont
 		Messages: []message{
 			{Role: "user", Content: prompt},
 		},
-		MaxTokens: c.ContextSize,
+		MaxTokens: c.Capabilities.MaxOutputTokens,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
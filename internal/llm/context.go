@@ -0,0 +1,193 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+	"github.com/chrlesur/json-ld-converter/pkg/tokenizer"
+)
+
+const (
+	DefaultContextBudget   = 1500
+	DefaultSummaryInterval = 5
+)
+
+// ContextManager maintient le contexte d'analyse transmis d'un segment à l'autre
+// dans un budget de tokens borné. Les résumés des segments récents sont
+// périodiquement condensés par le LLM ; au-delà du budget, les entités et
+// relations les moins récemment vues sont oubliées.
+type ContextManager struct {
+	client          LLMClient
+	budget          int
+	summaryInterval int
+	context         *AnalysisContext
+}
+
+func NewContextManager(client LLMClient, budget, summaryInterval int) *ContextManager {
+	if budget <= 0 {
+		budget = DefaultContextBudget
+	}
+	if summaryInterval <= 0 {
+		summaryInterval = DefaultSummaryInterval
+	}
+	return &ContextManager{
+		client:          client,
+		budget:          budget,
+		summaryInterval: summaryInterval,
+		context:         &AnalysisContext{},
+	}
+}
+
+func (m *ContextManager) Context() *AnalysisContext {
+	return m.context
+}
+
+func (m *ContextManager) SetContext(ac *AnalysisContext) {
+	if ac == nil {
+		ac = &AnalysisContext{}
+	}
+	m.context = ac
+}
+
+// Tokens estime la taille du contexte tel qu'il sera injecté dans un prompt
+func (m *ContextManager) Tokens() int {
+	return contextTokens(m.context)
+}
+
+func contextTokens(ac *AnalysisContext) int {
+	if ac.IsEmpty() {
+		return 0
+	}
+	return tokenizer.EstimateTokens(BuildPromptWithContext("", ac))
+}
+
+// EndSegment clôt l'analyse d'un segment : les résumés récents sont condensés
+// lorsque leur nombre atteint l'intervalle configuré ou que le budget est
+// dépassé, puis le contexte est élagué pour tenir dans le budget.
+func (m *ContextManager) EndSegment(ctx context.Context) {
+	m.context.Segment++
+
+	if len(m.context.RecentSummaries) >= m.summaryInterval || m.Tokens() > m.budget {
+		if err := m.summarize(ctx); err != nil {
+			logger.Warning(fmt.Sprintf("Unable to summarize analysis context: %v. Older summaries will be truncated", err))
+		}
+	}
+
+	m.prune()
+	logger.Debug(fmt.Sprintf("Analysis context after segment %d: %d entities, %d relations, ~%d tokens",
+		m.context.Segment, len(m.context.PreviousEntities), len(m.context.PreviousRelations), m.Tokens()))
+}
+
+// summarize condense le résumé existant et les résumés récents (sauf le dernier)
+// en un nouveau résumé
+func (m *ContextManager) summarize(ctx context.Context) error {
+	ac := m.context
+	if len(ac.RecentSummaries) < 2 && ac.Summary == "" {
+		return nil
+	}
+
+	older := ac.RecentSummaries
+	latest := []string{}
+	if len(older) > 0 {
+		latest = []string{older[len(older)-1]}
+		older = older[:len(older)-1]
+	}
+	toCondense := strings.TrimSpace(ac.Summary + " " + strings.Join(older, " "))
+	if toCondense == "" {
+		return nil
+	}
+
+	maxWords := m.budget / 4
+	prompt := fmt.Sprintf(`Condensez le résumé suivant d'un document en un seul paragraphe de %d mots au maximum, en conservant les personnes, lieux, événements et faits essentiels. Répondez uniquement avec le résumé :
+
+%s`, maxWords, toCondense)
+
+	logger.Debug(fmt.Sprintf("Summarizing analysis context (%d tokens)", tokenizer.EstimateTokens(toCondense)))
+	response, _, err := m.client.Analyze(ctx, prompt, &AnalysisContext{})
+	if err != nil {
+		return err
+	}
+
+	ac.Summary = strings.TrimSpace(response)
+	ac.RecentSummaries = latest
+	return nil
+}
+
+// prune retire les éléments les plus anciens jusqu'à respecter le budget :
+// relations, puis entités, puis le début du résumé condensé
+func (m *ContextManager) prune() {
+	ac := m.context
+	if m.Tokens() <= m.budget {
+		return
+	}
+
+	sort.SliceStable(ac.PreviousRelations, func(i, j int) bool {
+		return ac.PreviousRelations[i].LastSeen < ac.PreviousRelations[j].LastSeen
+	})
+	for len(ac.PreviousRelations) > 0 && m.Tokens() > m.budget {
+		ac.PreviousRelations = ac.PreviousRelations[1:]
+	}
+
+	if m.Tokens() > m.budget {
+		keys := make([]string, 0, len(ac.PreviousEntities))
+		for k := range ac.PreviousEntities {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			a, b := ac.PreviousEntities[keys[i]], ac.PreviousEntities[keys[j]]
+			if a.LastSeen != b.LastSeen {
+				return a.LastSeen < b.LastSeen
+			}
+			return keys[i] < keys[j]
+		})
+		for _, k := range keys {
+			if m.Tokens() <= m.budget {
+				break
+			}
+			delete(ac.PreviousEntities, k)
+		}
+	}
+
+	for len(ac.RecentSummaries) > 1 && m.Tokens() > m.budget {
+		ac.RecentSummaries = ac.RecentSummaries[1:]
+	}
+	for m.Tokens() > m.budget {
+		if !trimSummary(ac) {
+			break
+		}
+	}
+}
+
+// SaveAnalysisContext enregistre un contexte dans un fichier JSON pour pouvoir
+// reprendre une conversion
+func SaveAnalysisContext(path string, ac *AnalysisContext) error {
+	data, err := json.MarshalIndent(ac, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling analysis context: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing analysis context: %w", err)
+	}
+	return nil
+}
+
+// LoadAnalysisContext relit un contexte enregistré par SaveAnalysisContext
+func LoadAnalysisContext(path string) (*AnalysisContext, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading analysis context: %w", err)
+	}
+	var ac AnalysisContext
+	if err := json.Unmarshal(data, &ac); err != nil {
+		return nil, fmt.Errorf("error unmarshaling analysis context: %w", err)
+	}
+	if ac.PreviousEntities == nil {
+		ac.PreviousEntities = make(map[string]Entity)
+	}
+	return &ac, nil
+}
@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fakeClient struct {
+	response string
+	calls    int
+}
+
+func (f *fakeClient) Analyze(ctx context.Context, content string, analysisContext *AnalysisContext) (string, *AnalysisContext, error) {
+	f.calls++
+	return f.response, analysisContext, nil
+}
+
+func TestUpdateAnalysisContext(t *testing.T) {
+	response := "```json\n" + `{
+  "entities": [{"name": "Jean", "type": "Person", "description": "apôtre"}, {"name": "Galilée", "type": "Place"}],
+  "relations": [{"subject": "Jean", "predicate": "se rend en", "object": "Galilée"}],
+  "summary": "Jean part en Galilée."
+}` + "\n```"
+
+	ac, err := UpdateAnalysisContext(response, &AnalysisContext{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(ac.PreviousEntities) != 2 || len(ac.PreviousRelations) != 1 {
+		t.Errorf("Expected 2 entities and 1 relation, got %d and %d", len(ac.PreviousEntities), len(ac.PreviousRelations))
+	}
+	if len(ac.RecentSummaries) != 1 {
+		t.Errorf("Expected 1 recent summary, got %d", len(ac.RecentSummaries))
+	}
+
+	// Une réponse en prose ne doit pas faire grossir le contexte
+	prose, _ := UpdateAnalysisContext("Le type le plus approprié est Article.", ac)
+	if prose.FullSummary() != ac.FullSummary() || len(prose.PreviousEntities) != 2 {
+		t.Error("Non-JSON responses should leave the context unchanged")
+	}
+}
+
+func TestContextManagerSummarizesAndStaysInBudget(t *testing.T) {
+	client := &fakeClient{response: "Résumé condensé."}
+	m := NewContextManager(client, 200, 3)
+
+	ac := &AnalysisContext{PreviousEntities: map[string]Entity{}}
+	for i := 0; i < 3; i++ {
+		ac.RecentSummaries = append(ac.RecentSummaries, strings.Repeat("événement ", 20))
+	}
+	m.SetContext(ac)
+	m.EndSegment(context.Background())
+
+	if client.calls != 1 {
+		t.Errorf("Expected one summarization call, got %d", client.calls)
+	}
+	if m.Context().Summary != "Résumé condensé." || len(m.Context().RecentSummaries) != 1 {
+		t.Errorf("Unexpected context after summarization: %+v", m.Context())
+	}
+
+	for i := 0; i < 100; i++ {
+		name := strings.Repeat("x", i+1)
+		m.Context().PreviousEntities[name] = Entity{Name: name, Description: "une entité quelconque", LastSeen: i}
+	}
+	m.EndSegment(context.Background())
+	if m.Tokens() > 200 {
+		t.Errorf("Context exceeds budget after pruning: %d tokens", m.Tokens())
+	}
+	if _, ok := m.Context().PreviousEntities[strings.Repeat("x", 100)]; !ok {
+		t.Error("Most recently seen entity should be kept")
+	}
+}
+
+func TestSaveAndLoadAnalysisContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "context.json")
+	ac := &AnalysisContext{
+		PreviousEntities: map[string]Entity{"jean": {Name: "Jean", Type: "Person"}},
+		Summary:          "Résumé",
+		Segment:          4,
+	}
+	if err := SaveAnalysisContext(path, ac); err != nil {
+		t.Fatalf("Failed to save context: %v", err)
+	}
+	loaded, err := LoadAnalysisContext(path)
+	if err != nil {
+		t.Fatalf("Failed to load context: %v", err)
+	}
+	if loaded.Segment != 4 || loaded.PreviousEntities["jean"].Type != "Person" || loaded.Summary != "Résumé" {
+		t.Errorf("Loaded context does not match saved one: %+v", loaded)
+	}
+}
@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/chrlesur/json-ld-converter/internal/config"
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+	"github.com/sashabaranov/go-openai"
+)
+
+// Embedder calcule des vecteurs d'embedding pour une liste de textes
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// NewEmbedder crée le client d'embeddings configuré pour la segmentation sémantique.
+// Il retourne nil lorsque aucun moteur n'est configuré (ou "tfidf") : la
+// segmentation utilise alors son calcul TF-IDF local.
+func NewEmbedder(cfg *config.Config) (Embedder, error) {
+	timeout := time.Duration(cfg.Conversion.Timeout) * time.Second
+	switch cfg.Segmentation.EmbeddingEngine {
+	case "", "tfidf":
+		return nil, nil
+	case "ollama":
+		model := cfg.Segmentation.EmbeddingModel
+		if model == "" {
+			model = "nomic-embed-text"
+		}
+		return NewOllamaEmbedder(cfg.Conversion.OllamaHost, cfg.Conversion.OllamaPort, model, timeout), nil
+	case "openai":
+		apiKey := os.Getenv("OPENAI_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
+		}
+		model := cfg.Segmentation.EmbeddingModel
+		if model == "" {
+			model = string(openai.SmallEmbedding3)
+		}
+		return &OpenAIEmbedder{APIKey: apiKey, Model: model, Timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("unsupported embedding engine: %s", cfg.Segmentation.EmbeddingEngine)
+	}
+}
+
+// OllamaEmbedder utilise l'API /api/embed d'Ollama
+type OllamaEmbedder struct {
+	Host       string
+	Port       string
+	Model      string
+	HTTPClient *http.Client
+}
+
+// ollamaEmbeddingBatch limite le nombre de textes envoyés par requête
+const ollamaEmbeddingBatch = 64
+
+func NewOllamaEmbedder(host, port, model string, timeout time.Duration) *OllamaEmbedder {
+	logger.Info(fmt.Sprintf("Creating new OllamaEmbedder with model: %s", model))
+	return &OllamaEmbedder{
+		Host:       host,
+		Port:       port,
+		Model:      model,
+		HTTPClient: &http.Client{Timeout: timeout},
+	}
+}
+
+func (e *OllamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	url := fmt.Sprintf("http://%s:%s/api/embed", e.Host, e.Port)
+	vectors := make([][]float64, 0, len(texts))
+
+	for start := 0; start < len(texts); start += ollamaEmbeddingBatch {
+		end := min(start+ollamaEmbeddingBatch, len(texts))
+
+		jsonData, err := json.Marshal(map[string]interface{}{"model": e.Model, "input": texts[start:end]})
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling embedding request: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := e.HTTPClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error calling Ollama embed API: %w", err)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading Ollama embed response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("Ollama embed API returned status %d: %s", resp.StatusCode, string(body))
+		}
+
+		var embedResp struct {
+			Embeddings [][]float64 `json:"embeddings"`
+		}
+		if err := json.Unmarshal(body, &embedResp); err != nil {
+			return nil, fmt.Errorf("error decoding Ollama embed response: %w", err)
+		}
+		if len(embedResp.Embeddings) != end-start {
+			return nil, fmt.Errorf("Ollama embed API returned %d vectors for %d texts", len(embedResp.Embeddings), end-start)
+		}
+		vectors = append(vectors, embedResp.Embeddings...)
+	}
+
+	logger.Debug(fmt.Sprintf("Computed %d embeddings with Ollama model %s", len(vectors), e.Model))
+	return vectors, nil
+}
+
+// OpenAIEmbedder utilise l'API d'embeddings d'OpenAI
+type OpenAIEmbedder struct {
+	APIKey  string
+	Model   string
+	Timeout time.Duration
+}
+
+// openAIEmbeddingBatch limite le nombre de textes envoyés par requête
+const openAIEmbeddingBatch = 256
+
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	clientConfig := openai.DefaultConfig(e.APIKey)
+	clientConfig.HTTPClient = &http.Client{Timeout: e.Timeout}
+	client := openai.NewClientWithConfig(clientConfig)
+
+	vectors := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += openAIEmbeddingBatch {
+		end := start + openAIEmbeddingBatch
+		if end > len(texts) {
+			end = len(texts)
+		}
+
+		resp, err := client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+			Input: texts[start:end],
+			Model: openai.EmbeddingModel(e.Model),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error calling OpenAI embeddings API: %w", err)
+		}
+		if len(resp.Data) != end-start {
+			return nil, fmt.Errorf("OpenAI embeddings API returned %d vectors for %d texts", len(resp.Data), end-start)
+		}
+		for _, d := range resp.Data {
+			vector := make([]float64, len(d.Embedding))
+			for i, v := range d.Embedding {
+				vector[i] = float64(v)
+			}
+			vectors = append(vectors, vector)
+		}
+	}
+
+	logger.Debug(fmt.Sprintf("Computed %d embeddings with OpenAI model %s", len(vectors), e.Model))
+	return vectors, nil
+}
@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOllamaEmbedderBatchesInput(t *testing.T) {
+	var batches []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		batches = append(batches, len(req.Input))
+		embeddings := make([][]float64, len(req.Input))
+		for i, text := range req.Input {
+			embeddings[i] = []float64{float64(len(text))}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"model": req.Model, "embeddings": embeddings})
+	}))
+	defer server.Close()
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	e := NewOllamaEmbedder(host, port, "nomic-embed-text", time.Second)
+
+	texts := make([]string, ollamaEmbeddingBatch+2)
+	for i := range texts {
+		texts[i] = string(make([]byte, i))
+	}
+	vectors, err := e.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(batches) != 2 || batches[0] != ollamaEmbeddingBatch || batches[1] != 2 {
+		t.Errorf("Expected 2 requests of %d and 2 texts, got %v", ollamaEmbeddingBatch, batches)
+	}
+	if len(vectors) != len(texts) || vectors[ollamaEmbeddingBatch+1][0] != float64(ollamaEmbeddingBatch+1) {
+		t.Errorf("Vectors should follow the order of the texts, got %d vectors", len(vectors))
+	}
+}
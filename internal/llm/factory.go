@@ -10,23 +10,24 @@ import (
 
 // NewLLMClient crée et retourne le client LLM approprié en fonction du type de moteur spécifié
 func NewLLMClient(cfg *config.Config) (LLMClient, error) {
+    capabilities := ResolveCapabilities(cfg)
     switch cfg.Conversion.Engine {
     case "claude":
         apiKey := os.Getenv("CLAUDE_API_KEY")
         if apiKey == "" {
             return nil, fmt.Errorf("CLAUDE_API_KEY environment variable is not set")
         }
-        return NewClaudeClient(apiKey, cfg.Conversion.Model, cfg.Conversion.ContextSize, time.Duration(cfg.Conversion.Timeout)*time.Second), nil
+        return NewClaudeClient(apiKey, cfg.Conversion.Model, capabilities, time.Duration(cfg.Conversion.Timeout)*time.Second), nil
     case "openai":
         apiKey := os.Getenv("OPENAI_API_KEY")
         if apiKey == "" {
             return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
         }
-        return NewOpenAIClient(apiKey, cfg.Conversion.Model, cfg.Conversion.ContextSize, time.Duration(cfg.Conversion.Timeout)*time.Second), nil
+        return NewOpenAIClient(apiKey, cfg.Conversion.Model, capabilities, time.Duration(cfg.Conversion.Timeout)*time.Second), nil
     case "ollama":
-        return NewOllamaClient(cfg.Conversion.OllamaHost, cfg.Conversion.OllamaPort, cfg.Conversion.Model, cfg.Conversion.ContextSize, time.Duration(cfg.Conversion.Timeout)*time.Second), nil
+        return NewOllamaClient(cfg.Conversion.OllamaHost, cfg.Conversion.OllamaPort, cfg.Conversion.Model, capabilities, time.Duration(cfg.Conversion.Timeout)*time.Second), nil
     case "aiyou":
-        client := NewAIYOUClient(cfg.Conversion.AIYOUAssistantID, time.Duration(cfg.Conversion.Timeout)*time.Second)
+        client := NewAIYOUClient(cfg.Conversion.AIYOUAssistantID, capabilities, time.Duration(cfg.Conversion.Timeout)*time.Second)
         email := os.Getenv("AIYOU_EMAIL")
         password := os.Getenv("AIYOU_PASSWORD")
         if email == "" || password == "" {
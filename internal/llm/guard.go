@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+	"github.com/chrlesur/json-ld-converter/pkg/tokenizer"
+)
+
+// ContextOverflowError est retournée lorsqu'un prompt ne tient pas dans la fenêtre
+// de contexte du modèle, même après réduction du contexte d'analyse.
+type ContextOverflowError struct {
+	Model        string
+	PromptTokens int
+	Budget       int
+}
+
+func (e *ContextOverflowError) Error() string {
+	return fmt.Sprintf("prompt too large for model %q: ~%d tokens, input budget is %d tokens", e.Model, e.PromptTokens, e.Budget)
+}
+
+// PreparePrompt construit le prompt envoyé au modèle et vérifie qu'il tient dans
+// son budget d'entrée. Si ce n'est pas le cas, le contexte d'analyse est réduit
+// (résumé, puis relations, puis entités) ; si le contenu seul reste trop grand,
+// une ContextOverflowError est retournée sans appeler le modèle.
+func PreparePrompt(content string, analysisContext *AnalysisContext, caps ModelCapabilities, model string) (string, error) {
+	if analysisContext == nil {
+		analysisContext = &AnalysisContext{}
+	}
+	budget := caps.InputBudget()
+
+	prompt := BuildPromptWithContext(content, analysisContext)
+	tokens := tokenizer.EstimateTokens(prompt)
+	if tokens <= budget {
+		return prompt, nil
+	}
+
+	logger.Warning(fmt.Sprintf("Prompt exceeds input budget (~%d tokens, budget: %d), trimming analysis context", tokens, budget))
+	trimmed := analysisContext.Clone()
+	for _, trim := range []func(*AnalysisContext) bool{trimSummary, trimRelations, trimEntities} {
+		for trim(trimmed) {
+			prompt = BuildPromptWithContext(content, trimmed)
+			tokens = tokenizer.EstimateTokens(prompt)
+			if tokens <= budget {
+				logger.Debug(fmt.Sprintf("Prompt fits after trimming analysis context (~%d tokens)", tokens))
+				return prompt, nil
+			}
+		}
+	}
+
+	return "", &ContextOverflowError{Model: model, PromptTokens: tokens, Budget: budget}
+}
+
+// trimSummary retire le résumé récent le plus ancien, puis conserve la moitié la
+// plus récente du résumé condensé
+func trimSummary(ac *AnalysisContext) bool {
+	if len(ac.RecentSummaries) > 0 {
+		ac.RecentSummaries = ac.RecentSummaries[1:]
+		return true
+	}
+	words := strings.Fields(ac.Summary)
+	if len(words) == 0 {
+		return false
+	}
+	ac.Summary = strings.Join(words[len(words)/2+len(words)%2:], " ")
+	return true
+}
+
+// trimRelations conserve la moitié la plus récente des relations
+func trimRelations(ac *AnalysisContext) bool {
+	if len(ac.PreviousRelations) == 0 {
+		return false
+	}
+	ac.PreviousRelations = ac.PreviousRelations[len(ac.PreviousRelations)/2+len(ac.PreviousRelations)%2:]
+	return true
+}
+
+func trimEntities(ac *AnalysisContext) bool {
+	if len(ac.PreviousEntities) == 0 {
+		return false
+	}
+	ac.PreviousEntities = nil
+	return true
+}
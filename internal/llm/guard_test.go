@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGetModelCapabilities(t *testing.T) {
+	caps := GetModelCapabilities("openai", "gpt-4o-mini-2024-07-18")
+	if caps.MaxOutputTokens != 16384 || !caps.SupportsJSONMode {
+		t.Errorf("Unexpected capabilities for gpt-4o-mini: %+v", caps)
+	}
+
+	caps = GetModelCapabilities("openai", "gpt-4-0613")
+	if caps.ContextWindow != 8192 {
+		t.Errorf("Expected gpt-4 context window 8192, got %d", caps.ContextWindow)
+	}
+
+	caps = GetModelCapabilities("ollama", "some-unknown-model")
+	if caps.ContextWindow == 0 || caps.MaxOutputTokens == 0 {
+		t.Errorf("Expected engine defaults for unknown model, got %+v", caps)
+	}
+}
+
+func TestPreparePromptTrimsContext(t *testing.T) {
+	caps := ModelCapabilities{ContextWindow: 1200, MaxOutputTokens: 200}
+	ac := &AnalysisContext{
+		PreviousEntities: map[string]Entity{"jean": {Name: "Jean", Description: "apôtre"}},
+		Summary:          strings.Repeat("résumé ", 2000),
+	}
+
+	prompt, err := PreparePrompt("Nouveau contenu court.", ac, caps, "test")
+	if err != nil {
+		t.Fatalf("Expected prompt to fit after trimming, got error: %v", err)
+	}
+	if !strings.Contains(prompt, "Nouveau contenu court.") {
+		t.Error("Prompt should still contain the content")
+	}
+	if strings.Count(ac.Summary, "résumé") != 2000 {
+		t.Error("PreparePrompt should not modify the caller's analysis context")
+	}
+}
+
+func TestPreparePromptOverflow(t *testing.T) {
+	caps := ModelCapabilities{ContextWindow: 600, MaxOutputTokens: 100}
+	content := strings.Repeat("mot ", 1000)
+
+	_, err := PreparePrompt(content, &AnalysisContext{}, caps, "test")
+	var overflow *ContextOverflowError
+	if !errors.As(err, &overflow) {
+		t.Fatalf("Expected ContextOverflowError, got %v", err)
+	}
+	if overflow.Budget != 500 {
+		t.Errorf("Expected budget 500, got %d", overflow.Budget)
+	}
+}
@@ -4,12 +4,30 @@ import (
 	"context"
 )
 
+// Entity est une entité mémorisée d'un segment à l'autre
+type Entity struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	LastSeen    int    `json:"last_seen"`
+}
+
+// Relation est une relation mémorisée entre deux entités
+type Relation struct {
+	Subject   string `json:"subject"`
+	Predicate string `json:"predicate"`
+	Object    string `json:"object"`
+	LastSeen  int    `json:"last_seen"`
+}
+
 type AnalysisContext struct {
-	PreviousEntities  map[string]string
-	PreviousRelations []string
-	Summary           string
+	PreviousEntities  map[string]Entity `json:"entities"`
+	PreviousRelations []Relation        `json:"relations"`
+	Summary           string            `json:"summary"`          // résumé condensé des segments anciens
+	RecentSummaries   []string          `json:"recent_summaries"` // résumés des derniers segments, pas encore condensés
+	Segment           int               `json:"segment"`          // nombre de segments déjà analysés
 }
 
 type LLMClient interface {
-    Analyze(ctx context.Context, content string, analysisContext *AnalysisContext) (string, *AnalysisContext, error)
+	Analyze(ctx context.Context, content string, analysisContext *AnalysisContext) (string, *AnalysisContext, error)
 }
@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter espace les requêtes envoyées au LLM. Il est partagé par les
+// conversions menées en parallèle pour respecter le quota du fournisseur.
+type RateLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+// NewRateLimiter crée un limiteur autorisant requestsPerMinute requêtes par
+// minute ; il retourne nil (aucune limite) si requestsPerMinute est nul
+func NewRateLimiter(requestsPerMinute int) *RateLimiter {
+	if requestsPerMinute <= 0 {
+		return nil
+	}
+	return &RateLimiter{interval: time.Minute / time.Duration(requestsPerMinute)}
+}
+
+// Wait attend le prochain créneau disponible ou l'annulation du contexte
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type rateLimitedClient struct {
+	client  LLMClient
+	limiter *RateLimiter
+}
+
+// WithRateLimit retourne un client dont les requêtes passent par le limiteur
+func WithRateLimit(client LLMClient, limiter *RateLimiter) LLMClient {
+	if limiter == nil {
+		return client
+	}
+	return &rateLimitedClient{client: client, limiter: limiter}
+}
+
+func (c *rateLimitedClient) Analyze(ctx context.Context, content string, analysisContext *AnalysisContext) (string, *AnalysisContext, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return "", analysisContext, err
+	}
+	return c.client.Analyze(ctx, content, analysisContext)
+}
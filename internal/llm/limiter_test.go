@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	if NewRateLimiter(0) != nil {
+		t.Fatal("NewRateLimiter(0) should not limit")
+	}
+
+	limiter := NewRateLimiter(600) // une requête toutes les 100 ms
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond {
+		t.Errorf("3 requests took %v, want at least 200ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limiter.Wait(context.Background())
+	if err := limiter.Wait(ctx); err == nil {
+		t.Error("Wait() on a cancelled context should fail")
+	}
+}
+
+func TestMeteredClient(t *testing.T) {
+	client := NewMeteredClient(WithRateLimit(&fakeClient{response: "une réponse"}, nil))
+	client.Analyze(context.Background(), "un contenu à analyser", nil)
+	client.Analyze(context.Background(), "un autre contenu", nil)
+
+	usage := client.Usage()
+	if usage.Requests != 2 || usage.PromptTokens == 0 || usage.CompletionTokens == 0 {
+		t.Errorf("usage = %+v", usage)
+	}
+}
@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"strings"
+
+	"github.com/chrlesur/json-ld-converter/internal/config"
+)
+
+// ModelCapabilities décrit les limites et fonctionnalités connues d'un modèle
+type ModelCapabilities struct {
+	ContextWindow    int  // taille totale de la fenêtre de contexte (entrée + sortie), en tokens
+	MaxOutputTokens  int  // nombre maximal de tokens générés par réponse
+	SupportsJSONMode bool // le moteur sait contraindre la réponse à du JSON
+}
+
+// InputBudget retourne le nombre de tokens disponibles pour le prompt une fois
+// la sortie réservée.
+func (m ModelCapabilities) InputBudget() int {
+	return m.ContextWindow - m.MaxOutputTokens
+}
+
+type modelEntry struct {
+	engine       string
+	prefix       string
+	capabilities ModelCapabilities
+}
+
+// modelRegistry liste les modèles connus par moteur. La recherche se fait par
+// préfixe du nom de modèle, le préfixe le plus long l'emportant ; un préfixe
+// vide sert de valeur par défaut pour le moteur.
+var modelRegistry = []modelEntry{
+	{"claude", "claude-3-5-sonnet", ModelCapabilities{ContextWindow: 200000, MaxOutputTokens: 8192}},
+	{"claude", "claude-3-5-haiku", ModelCapabilities{ContextWindow: 200000, MaxOutputTokens: 8192}},
+	{"claude", "claude-3-opus", ModelCapabilities{ContextWindow: 200000, MaxOutputTokens: 4096}},
+	{"claude", "claude-3-sonnet", ModelCapabilities{ContextWindow: 200000, MaxOutputTokens: 4096}},
+	{"claude", "claude-3-haiku", ModelCapabilities{ContextWindow: 200000, MaxOutputTokens: 4096}},
+	{"claude", "", ModelCapabilities{ContextWindow: 200000, MaxOutputTokens: 4096}},
+	{"openai", "gpt-4o-mini", ModelCapabilities{ContextWindow: 128000, MaxOutputTokens: 16384, SupportsJSONMode: true}},
+	{"openai", "gpt-4o", ModelCapabilities{ContextWindow: 128000, MaxOutputTokens: 16384, SupportsJSONMode: true}},
+	{"openai", "gpt-4-turbo", ModelCapabilities{ContextWindow: 128000, MaxOutputTokens: 4096, SupportsJSONMode: true}},
+	{"openai", "gpt-4", ModelCapabilities{ContextWindow: 8192, MaxOutputTokens: 4096}},
+	{"openai", "gpt-3.5-turbo", ModelCapabilities{ContextWindow: 16385, MaxOutputTokens: 4096, SupportsJSONMode: true}},
+	{"openai", "", ModelCapabilities{ContextWindow: 16385, MaxOutputTokens: 4096}},
+	{"ollama", "llama3.1", ModelCapabilities{ContextWindow: 131072, MaxOutputTokens: 4096, SupportsJSONMode: true}},
+	{"ollama", "llama3.2", ModelCapabilities{ContextWindow: 131072, MaxOutputTokens: 4096, SupportsJSONMode: true}},
+	{"ollama", "llama3", ModelCapabilities{ContextWindow: 8192, MaxOutputTokens: 2048, SupportsJSONMode: true}},
+	{"ollama", "mistral-nemo", ModelCapabilities{ContextWindow: 128000, MaxOutputTokens: 4096, SupportsJSONMode: true}},
+	{"ollama", "mistral", ModelCapabilities{ContextWindow: 32768, MaxOutputTokens: 4096, SupportsJSONMode: true}},
+	{"ollama", "qwen2.5", ModelCapabilities{ContextWindow: 32768, MaxOutputTokens: 4096, SupportsJSONMode: true}},
+	{"ollama", "gemma2", ModelCapabilities{ContextWindow: 8192, MaxOutputTokens: 2048, SupportsJSONMode: true}},
+	{"ollama", "", ModelCapabilities{ContextWindow: 8192, MaxOutputTokens: 2048, SupportsJSONMode: true}},
+	{"aiyou", "", ModelCapabilities{ContextWindow: 32000, MaxOutputTokens: 4096}},
+}
+
+var defaultCapabilities = ModelCapabilities{ContextWindow: 8192, MaxOutputTokens: 2048}
+
+// GetModelCapabilities retourne les capacités connues d'un modèle pour un moteur donné
+func GetModelCapabilities(engine, model string) ModelCapabilities {
+	model = strings.ToLower(model)
+	best := -1
+	for i, entry := range modelRegistry {
+		if entry.engine != engine || !strings.HasPrefix(model, entry.prefix) {
+			continue
+		}
+		if best == -1 || len(entry.prefix) > len(modelRegistry[best].prefix) {
+			best = i
+		}
+	}
+	if best == -1 {
+		return defaultCapabilities
+	}
+	return modelRegistry[best].capabilities
+}
+
+// ResolveCapabilities combine le registre et les surcharges de la configuration :
+// context_size fixe la fenêtre de contexte et max_output_tokens le budget de sortie.
+func ResolveCapabilities(cfg *config.Config) ModelCapabilities {
+	caps := GetModelCapabilities(cfg.Conversion.Engine, cfg.Conversion.Model)
+	if cfg.Conversion.ContextSize > 0 {
+		caps.ContextWindow = cfg.Conversion.ContextSize
+	}
+	if cfg.Conversion.MaxOutputTokens > 0 {
+		caps.MaxOutputTokens = cfg.Conversion.MaxOutputTokens
+	}
+	if caps.MaxOutputTokens >= caps.ContextWindow {
+		caps.MaxOutputTokens = caps.ContextWindow / 2
+	}
+	return caps
+}
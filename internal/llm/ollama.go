@@ -15,23 +15,23 @@ import (
 )
 
 type OllamaClient struct {
-	Host        string
-	Port        string
-	Model       string
-	ContextSize int
-	Timeout     time.Duration
-	HTTPClient  *http.Client
+	Host         string
+	Port         string
+	Model        string
+	Capabilities ModelCapabilities
+	Timeout      time.Duration
+	HTTPClient   *http.Client
 }
 
-func NewOllamaClient(host, port, model string, contextSize int, timeout time.Duration) *OllamaClient {
-	logger.Info(fmt.Sprintf("Creating new OllamaClient with model: %s, contextSize: %d, timeout: %v", model, contextSize, timeout))
+func NewOllamaClient(host, port, model string, capabilities ModelCapabilities, timeout time.Duration) *OllamaClient {
+	logger.Info(fmt.Sprintf("Creating new OllamaClient with model: %s, context window: %d, max output: %d, timeout: %v", model, capabilities.ContextWindow, capabilities.MaxOutputTokens, timeout))
 	return &OllamaClient{
-		Host:        host,
-		Port:        port,
-		Model:       model,
-		ContextSize: contextSize,
-		Timeout:     timeout,
-		HTTPClient:  &http.Client{Timeout: timeout},
+		Host:         host,
+		Port:         port,
+		Model:        model,
+		Capabilities: capabilities,
+		Timeout:      timeout,
+		HTTPClient:   &http.Client{Timeout: timeout},
 	}
 }
 
@@ -40,7 +40,8 @@ type ollamaRequest struct {
 	Prompt  string `json:"prompt"`
 	Stream  bool   `json:"stream"`
 	Options struct {
-		NumCtx int `json:"num_ctx"`
+		NumCtx     int `json:"num_ctx"`
+		NumPredict int `json:"num_predict"`
 	} `json:"options"`
 }
 
@@ -50,7 +51,11 @@ type ollamaResponse struct {
 
 func (c *OllamaClient) Analyze(ctx context.Context, content string, analysisContext *AnalysisContext) (string, *AnalysisContext, error) {
 	logger.Debug("Starting analysis with Ollama API")
-	prompt := BuildPromptWithContext(content, analysisContext)
+	prompt, err := PreparePrompt(content, analysisContext, c.Capabilities, c.Model)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Prompt rejected before calling Ollama API: %v", err))
+		return "", nil, err
+	}
 
 	logger.Debug(fmt.Sprintf("Prepared prompt for Ollama API (%d tokens)", tokenizer.CountTokens(prompt)))
 
@@ -59,7 +64,8 @@ func (c *OllamaClient) Analyze(ctx context.Context, content string, analysisCont
 		Prompt: prompt,
 		Stream: false,
 	}
-	reqBody.Options.NumCtx = c.ContextSize
+	reqBody.Options.NumCtx = c.Capabilities.ContextWindow
+	reqBody.Options.NumPredict = c.Capabilities.MaxOutputTokens
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
@@ -15,25 +15,29 @@ import (
 )
 
 type OpenAIClient struct {
-	APIKey      string
-	Model       string
-	ContextSize int
-	Timeout     time.Duration
+	APIKey       string
+	Model        string
+	Capabilities ModelCapabilities
+	Timeout      time.Duration
 }
 
-func NewOpenAIClient(apiKey, model string, contextSize int, timeout time.Duration) *OpenAIClient {
-	logger.Info(fmt.Sprintf("Creating new OpenAIClient with model: %s, contextSize: %d, timeout: %v", model, contextSize, timeout))
+func NewOpenAIClient(apiKey, model string, capabilities ModelCapabilities, timeout time.Duration) *OpenAIClient {
+	logger.Info(fmt.Sprintf("Creating new OpenAIClient with model: %s, context window: %d, max output: %d, timeout: %v", model, capabilities.ContextWindow, capabilities.MaxOutputTokens, timeout))
 	return &OpenAIClient{
-		APIKey:      apiKey,
-		Model:       model,
-		ContextSize: contextSize,
-		Timeout:     timeout,
+		APIKey:       apiKey,
+		Model:        model,
+		Capabilities: capabilities,
+		Timeout:      timeout,
 	}
 }
 
 func (c *OpenAIClient) Analyze(ctx context.Context, content string, analysisContext *AnalysisContext) (string, *AnalysisContext, error) {
 	logger.Debug("Starting analysis with OpenAI API")
-	prompt := BuildPromptWithContext(content, analysisContext)
+	prompt, err := PreparePrompt(content, analysisContext, c.Capabilities, c.Model)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Prompt rejected before calling OpenAI API: %v", err))
+		return "", nil, err
+	}
 
 	logger.Debug(fmt.Sprintf("Prepared prompt for OpenAI API (%d tokens)", tokenizer.CountTokens(prompt)))
 
@@ -45,10 +49,9 @@ func (c *OpenAIClient) Analyze(ctx context.Context, content string, analysisCont
 				Content: prompt,
 			},
 		},
-		MaxTokens: c.ContextSize,
+		MaxTokens: c.Capabilities.MaxOutputTokens,
 	}
 	var resp openai.ChatCompletionResponse
-	var err error
 
 	jsonData, err := json.Marshal(req)
 	if err != nil {
@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"context"
+	"sync"
+
+	"github.com/chrlesur/json-ld-converter/pkg/tokenizer"
+)
+
+// Usage totalise les requêtes adressées au LLM et une estimation des tokens
+// échangés
+type Usage struct {
+	Requests         int `json:"requests"`
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// Tokens retourne le nombre total de tokens estimés
+func (u Usage) Tokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Add ajoute une autre consommation
+func (u *Usage) Add(other Usage) {
+	u.Requests += other.Requests
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+}
+
+// MeteredClient mesure la consommation des requêtes passant par un client.
+// Les tokens sont estimés à partir du contenu envoyé et de la réponse, les
+// fournisseurs ne les retournant pas tous.
+type MeteredClient struct {
+	client LLMClient
+	mu     sync.Mutex
+	usage  Usage
+}
+
+// NewMeteredClient enveloppe un client pour en mesurer la consommation
+func NewMeteredClient(client LLMClient) *MeteredClient {
+	return &MeteredClient{client: client}
+}
+
+func (c *MeteredClient) Analyze(ctx context.Context, content string, analysisContext *AnalysisContext) (string, *AnalysisContext, error) {
+	response, ac, err := c.client.Analyze(ctx, content, analysisContext)
+
+	c.mu.Lock()
+	c.usage.Requests++
+	c.usage.PromptTokens += tokenizer.EstimateTokens(content)
+	c.usage.CompletionTokens += tokenizer.EstimateTokens(response)
+	c.mu.Unlock()
+
+	return response, ac, err
+}
+
+// Usage retourne la consommation mesurée
+func (c *MeteredClient) Usage() Usage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.usage
+}
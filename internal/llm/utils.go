@@ -1,63 +1,183 @@
 package llm
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 )
 
-func FormatMapToString(m map[string]string) string {
+// FormatEntities présente les entités mémorisées, triées par nom
+func FormatEntities(entities map[string]Entity) string {
 	var result []string
-	for k, v := range m {
-		result = append(result, fmt.Sprintf("%s: %s", k, v))
+	for _, e := range entities {
+		item := e.Name
+		if e.Type != "" {
+			item += " (" + e.Type + ")"
+		}
+		if e.Description != "" {
+			item += " : " + e.Description
+		}
+		result = append(result, item)
 	}
-	return strings.Join(result, ", ")
+	sort.Strings(result)
+	return strings.Join(result, " ; ")
 }
 
-func BuildPromptWithContext(content string, context *AnalysisContext) string {
-	prompt := `Analysez silencieusement le document fourni et produisez une représentation structurée avec les éléments suivants :
+// FormatRelations présente les relations mémorisées sous la forme sujet -> prédicat -> objet
+func FormatRelations(relations []Relation) string {
+	var result []string
+	for _, r := range relations {
+		result = append(result, fmt.Sprintf("%s -> %s -> %s", r.Subject, r.Predicate, r.Object))
+	}
+	return strings.Join(result, " ; ")
+}
 
-	Entités principales :
-	- Liste des entités importantes
+// BuildAnalysisPrompt construit le prompt d'analyse d'un segment. La réponse attendue
+// est un objet JSON que UpdateAnalysisContext sait intégrer au contexte.
+func BuildAnalysisPrompt(content string) string {
+	prompt := `Analysez silencieusement le document fourni et produisez une représentation structurée.
+Retournez UNIQUEMENT un objet JSON valide, sans texte avant ou après, au format suivant :
+{
+  "entities": [{"name": "Nom", "type": "Person/Organization/Place/Event/Concept", "description": "Rôle ou description"}],
+  "relations": [{"subject": "Entité", "predicate": "relation", "object": "Entité"}],
+  "summary": "Un bref résumé du nouveau contenu"
+}
 
-	Relations clés :
-	- Liste des relations importantes entre les entités
+Nouveau contenu à analyser : %s`
 
-	Résumé :
-	Un bref résumé du contenu
+	return fmt.Sprintf(prompt, content)
+}
 
-	N'utilisez pas de caractère # ou * et ne produisez que du texte simple
+// BuildPromptWithContext préfixe le contenu par le contexte accumulé sur les segments
+// précédents. Sans contexte, le contenu est retourné tel quel.
+func BuildPromptWithContext(content string, context *AnalysisContext) string {
+	if context.IsEmpty() {
+		return content
+	}
 
-	Contexte précédent :
-    Entités : %s
-    Relations : %s
-    Résumé : %s
-    Nouveau contenu à analyser : %s`
+	preamble := `Contexte des segments précédents (à utiliser pour assurer la cohérence, sans le réanalyser) :
+Entités : %s
+Relations : %s
+Résumé : %s
 
-	return fmt.Sprintf(prompt,
-		FormatMapToString(context.PreviousEntities),
-		strings.Join(context.PreviousRelations, ", "),
-		context.Summary,
+%s`
+
+	return fmt.Sprintf(preamble,
+		FormatEntities(context.PreviousEntities),
+		FormatRelations(context.PreviousRelations),
+		context.FullSummary(),
 		content)
 }
 
+// IsEmpty indique si le contexte ne contient aucune information à transmettre
+func (ac *AnalysisContext) IsEmpty() bool {
+	return ac == nil || (len(ac.PreviousEntities) == 0 && len(ac.PreviousRelations) == 0 &&
+		ac.Summary == "" && len(ac.RecentSummaries) == 0)
+}
+
+// FullSummary combine le résumé condensé et les résumés récents
+func (ac *AnalysisContext) FullSummary() string {
+	parts := make([]string, 0, len(ac.RecentSummaries)+1)
+	if ac.Summary != "" {
+		parts = append(parts, ac.Summary)
+	}
+	parts = append(parts, ac.RecentSummaries...)
+	return strings.Join(parts, " ")
+}
+
+// Clone retourne une copie indépendante du contexte
+func (ac *AnalysisContext) Clone() *AnalysisContext {
+	clone := &AnalysisContext{
+		PreviousEntities:  make(map[string]Entity),
+		PreviousRelations: []Relation{},
+		RecentSummaries:   []string{},
+	}
+	if ac == nil {
+		return clone
+	}
+	for k, v := range ac.PreviousEntities {
+		clone.PreviousEntities[k] = v
+	}
+	clone.PreviousRelations = append(clone.PreviousRelations, ac.PreviousRelations...)
+	clone.RecentSummaries = append(clone.RecentSummaries, ac.RecentSummaries...)
+	clone.Summary = ac.Summary
+	clone.Segment = ac.Segment
+	return clone
+}
+
+// UpdateAnalysisContext intègre au contexte les entités, relations et résumé d'une
+// réponse JSON produite à partir de BuildAnalysisPrompt. Les réponses qui ne
+// contiennent pas ce JSON laissent le contexte inchangé.
 func UpdateAnalysisContext(response string, prevContext *AnalysisContext) (*AnalysisContext, error) {
-	newContext := &AnalysisContext{
-		PreviousEntities:  make(map[string]string),
-		PreviousRelations: []string{},
-		Summary:           prevContext.Summary,
-	}
-
-	lines := strings.Split(response, "\n")
-	for _, line := range lines {
-		parts := strings.Split(line, "\t")
-		if len(parts) >= 3 {
-			newContext.PreviousEntities[parts[0]] = parts[2]
-			newContext.PreviousRelations = append(newContext.PreviousRelations, parts[1])
+	newContext := prevContext.Clone()
+
+	jsonResponse, ok := extractJSONObject(response)
+	if !ok {
+		return newContext, nil
+	}
+
+	var parsed struct {
+		Entities  []Entity   `json:"entities"`
+		Relations []Relation `json:"relations"`
+		Summary   string     `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(jsonResponse), &parsed); err != nil {
+		return newContext, nil
+	}
+
+	for _, e := range parsed.Entities {
+		name := strings.TrimSpace(e.Name)
+		if name == "" {
+			continue
+		}
+		key := strings.ToLower(name)
+		existing, found := newContext.PreviousEntities[key]
+		if found {
+			if e.Type == "" {
+				e.Type = existing.Type
+			}
+			if e.Description == "" {
+				e.Description = existing.Description
+			}
 		}
+		e.Name = name
+		e.LastSeen = newContext.Segment
+		newContext.PreviousEntities[key] = e
 	}
 
-	// Mettre à jour le résumé (ceci est une approche simplifiée)
-	newContext.Summary += " " + strings.Join(lines, " ")
+	for _, r := range parsed.Relations {
+		if r.Subject == "" || r.Object == "" {
+			continue
+		}
+		r.LastSeen = newContext.Segment
+		replaced := false
+		for i, existing := range newContext.PreviousRelations {
+			if strings.EqualFold(existing.Subject, r.Subject) && strings.EqualFold(existing.Predicate, r.Predicate) && strings.EqualFold(existing.Object, r.Object) {
+				newContext.PreviousRelations[i] = r
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			newContext.PreviousRelations = append(newContext.PreviousRelations, r)
+		}
+	}
+
+	if summary := strings.TrimSpace(parsed.Summary); summary != "" {
+		newContext.RecentSummaries = append(newContext.RecentSummaries, summary)
+	}
 
 	return newContext, nil
 }
+
+// extractJSONObject isole le premier objet JSON d'une réponse (éventuellement
+// entourée de texte ou de balises de code)
+func extractJSONObject(response string) (string, bool) {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start == -1 || end <= start {
+		return "", false
+	}
+	return response[start : end+1], true
+}
@@ -21,7 +21,7 @@ const (
 var (
 	logLevel        LogLevel
 	logFile         *os.File
-	console         io.Writer
+	console         io.Writer = os.Stdout
 	mu              sync.Mutex
 	silentMode      bool
 	debugMode       bool
@@ -29,6 +29,7 @@ var (
 	currentDocument int
 	totalChunks     int
 	currentChunk    int
+	parallelDocs    bool
 )
 
 func Init(level LogLevel, filePath string) error {
@@ -49,6 +50,14 @@ func Init(level LogLevel, filePath string) error {
 	return nil
 }
 
+// SetOutput redirige la sortie console des journaux et de la progression, vers
+// la sortie d'erreur par exemple lorsque le résultat est écrit sur la sortie standard
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	console = w
+}
+
 func SetLogLevel(level LogLevel) {
 	mu.Lock()
 	defer mu.Unlock()
@@ -79,9 +88,22 @@ func InitProgress(totalDocs int) {
 	currentChunk = 0
 }
 
+// SetParallelDocuments indique que plusieurs documents sont convertis en même
+// temps : les segments de tous les documents sont alors cumulés au lieu d'être
+// remis à zéro à chaque document
+func SetParallelDocuments(parallel bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	parallelDocs = parallel
+}
+
 func SetTotalChunks(total int) {
 	mu.Lock()
 	defer mu.Unlock()
+	if parallelDocs {
+		totalChunks += total
+		return
+	}
 	totalChunks = total
 	currentChunk = 0
 }
@@ -90,9 +112,11 @@ func UpdateDocumentProgress() {
 	mu.Lock()
 	defer mu.Unlock()
 	currentDocument++
-	currentChunk = 0
+	if !parallelDocs {
+		currentChunk = 0
+	}
 	if !silentMode {
-		fmt.Printf("\rDocument: %d/%d, Chunk: %d/%d", currentDocument, totalDocuments, currentChunk, totalChunks)
+		fmt.Fprintf(console, "\rDocument: %d/%d, Chunk: %d/%d", currentDocument, totalDocuments, currentChunk, totalChunks)
 	}
 }
 
@@ -101,7 +125,7 @@ func UpdateChunkProgress() {
 	defer mu.Unlock()
 	currentChunk++
 	if !silentMode {
-		fmt.Printf("\rDocument: %d/%d, Chunk: %d/%d", currentDocument, totalDocuments, currentChunk, totalChunks)
+		fmt.Fprintf(console, "\rDocument: %d/%d, Chunk: %d/%d", currentDocument, totalDocuments, currentChunk, totalChunks)
 	}
 }
 
@@ -122,11 +146,11 @@ func log(level LogLevel, message string) {
 
 	if !silentMode {
 		// Clear the current line
-		fmt.Print("\r" + strings.Repeat(" ", 80) + "\r")
+		fmt.Fprint(console, "\r"+strings.Repeat(" ", 80)+"\r")
 		// Print the log message
-		fmt.Print(logMessage)
+		fmt.Fprint(console, logMessage)
 		// Print the progress on the next line
-		fmt.Printf("Document: %d/%d, Chunk: %d/%d", currentDocument, totalDocuments, currentChunk, totalChunks)
+		fmt.Fprintf(console, "Document: %d/%d, Chunk: %d/%d", currentDocument, totalDocuments, currentChunk, totalChunks)
 	}
 }
 
@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVParser lit les fichiers CSV et TSV. La première ligne est l'en-tête ; le
+// document contient un élément "table" dont chaque ligne ("row") est composée de
+// cellules portant le nom de leur colonne (attribut "column").
+type CSVParser struct {
+	// Comma est le séparateur ; il est détecté sur la première ligne s'il vaut 0
+	Comma rune
+}
+
+func NewCSVParser() *CSVParser {
+	return &CSVParser{}
+}
+
+func NewTSVParser() *CSVParser {
+	return &CSVParser{Comma: '\t'}
+}
+
+func (p *CSVParser) Parse(r io.Reader) (*Document, error) {
+	text, err := readText(r)
+	if err != nil {
+		return nil, err
+	}
+	comma := p.Comma
+	if comma == 0 {
+		comma = detectDelimiter(text)
+	}
+
+	csvReader := csv.NewReader(strings.NewReader(text))
+	csvReader.Comma = comma
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty CSV file")
+	}
+
+	table := tableElement(records, nil)
+	return &Document{
+		Content:   TextOf([]DocumentElement{table}),
+		Metadata:  map[string]string{"tabular": "true", "rows": strconv.Itoa(len(records) - 1)},
+		Structure: []DocumentElement{table},
+	}, nil
+}
+
+// detectDelimiter choisit le séparateur le plus fréquent de la première ligne
+func detectDelimiter(sample string) rune {
+	if i := strings.IndexByte(sample, '\n'); i >= 0 {
+		sample = sample[:i]
+	}
+	best, bestCount := ',', 0
+	for _, candidate := range []rune{',', ';', '\t', '|'} {
+		if count := strings.Count(sample, string(candidate)); count > bestCount {
+			best, bestCount = candidate, count
+		}
+	}
+	return best
+}
+
+// tableElement construit un tableau dont la première ligne est l'en-tête
+func tableElement(records [][]string, attributes map[string]string) DocumentElement {
+	var headers []string
+	var rows []DocumentElement
+	for i, record := range records {
+		if i == 0 {
+			for j, h := range record {
+				h = collapseWhitespace(h)
+				if h == "" {
+					h = "column_" + strconv.Itoa(j+1)
+				}
+				headers = append(headers, h)
+			}
+		}
+
+		var cells []DocumentElement
+		var texts []string
+		empty := true
+		for j, value := range record {
+			value = strings.TrimSpace(value)
+			empty = empty && value == ""
+			cell := DocumentElement{Type: "cell", Content: value}
+			if i > 0 && j < len(headers) {
+				cell.Attributes = map[string]string{"column": headers[j]}
+			}
+			cells = append(cells, cell)
+			texts = append(texts, value)
+		}
+		if empty {
+			continue
+		}
+
+		row := DocumentElement{Type: "row", Content: strings.Join(texts, " | "), Children: cells}
+		if i == 0 {
+			row.Attributes = map[string]string{"header": "true"}
+		}
+		rows = append(rows, row)
+	}
+	return newContainer("table", attributes, rows)
+}
+
+// TableData retourne l'en-tête et les lignes d'un élément "table" ; l'en-tête est
+// la première ligne marquée "header", ou à défaut la première ligne, et ses
+// cellules vides sont nommées d'après leur position
+func TableData(table DocumentElement) ([]string, [][]string) {
+	var headers []string
+	var rows [][]string
+	for i, row := range table.Children {
+		if row.Type != "row" {
+			continue
+		}
+		values := make([]string, 0, len(row.Children))
+		for _, cell := range row.Children {
+			values = append(values, cell.Content)
+		}
+		if headers == nil && (i == 0 || row.Attributes["header"] == "true") {
+			for j, h := range values {
+				if h == "" {
+					values[j] = "column_" + strconv.Itoa(j+1)
+				}
+			}
+			headers = values
+			continue
+		}
+		rows = append(rows, values)
+	}
+	return headers, rows
+}
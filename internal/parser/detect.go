@@ -0,0 +1,261 @@
+package parser
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// sniffLen est le nombre d'octets examinés pour reconnaître un format texte
+const sniffLen = 8192
+
+// extensionFormats associe les extensions de fichier aux formats des parseurs
+var extensionFormats = map[string]string{
+	".txt":        "text",
+	".md":         "markdown",
+	".markdown":   "markdown",
+	".pdf":        "pdf",
+	".html":       "html",
+	".htm":        "html",
+	".xhtml":      "html",
+	".docx":       "docx",
+	".odt":        "odt",
+	".epub":       "epub",
+	".srt":        "srt",
+	".vtt":        "vtt",
+	".transcript": "transcript",
+	".eml":        "eml",
+	".mbox":       "mbox",
+	".csv":        "csv",
+	".tsv":        "tsv",
+	".xlsx":       "xlsx",
+}
+
+// FormatForExtension retourne le format associé à l'extension du fichier, ou
+// une chaîne vide si elle est inconnue
+func FormatForExtension(filename string) string {
+	return extensionFormats[strings.ToLower(filepath.Ext(filename))]
+}
+
+// Detect identifie le format d'un flux d'après son contenu et son nom. Le
+// lecteur retourné relit le flux depuis le début ; les archives zip sont lues
+// entièrement pour en examiner le contenu.
+func Detect(r io.Reader, filename string) (string, io.Reader, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	head = head[:n]
+	r = io.MultiReader(bytes.NewReader(head), r)
+
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return "", nil, err
+		}
+		return DetectFormat(filename, data), bytes.NewReader(data), nil
+	}
+	return DetectFormat(filename, head), r, nil
+}
+
+// DetectFormat identifie le format à partir du début du contenu (ou de
+// l'archive complète pour les formats zip) et du nom du fichier. Les
+// signatures sans ambiguïté (PDF, conteneurs zip, doctype HTML, en-têtes de
+// courriel, WEBVTT) priment sur l'extension ; les heuristiques plus faibles
+// (Markdown, CSV, sous-titres) ne servent que si l'extension est absente,
+// inconnue ou .txt. À défaut, le contenu est traité comme du texte.
+func DetectFormat(filename string, data []byte) string {
+	if format := sniffSignature(data); format != "" {
+		return format
+	}
+	if format := FormatForExtension(filename); format != "" && format != "text" {
+		return format
+	}
+	if format := sniffText(data); format != "" {
+		return format
+	}
+	return "text"
+}
+
+// sniffSignature reconnaît les formats à signature caractéristique
+func sniffSignature(data []byte) string {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return sniffZip(data)
+	}
+	if i := bytes.Index(data, []byte("%PDF-")); i >= 0 && i < 1024 {
+		return "pdf"
+	}
+
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	trimmed := strings.TrimSpace(text)
+	lower := strings.ToLower(trimmed)
+	switch {
+	case strings.HasPrefix(lower, "<!doctype html"), strings.HasPrefix(lower, "<html"):
+		return "html"
+	case strings.HasPrefix(trimmed, "WEBVTT"):
+		return "vtt"
+	case strings.HasPrefix(text, "From ") && looksLikeEmail(text[strings.IndexByte(text+"\n", '\n')+1:]):
+		return "mbox"
+	case looksLikeEmail(text):
+		return "eml"
+	}
+	return ""
+}
+
+// sniffZip distingue les conteneurs zip d'après leur fichier mimetype (EPUB,
+// OpenDocument) ou leurs parties (DOCX, XLSX)
+func sniffZip(data []byte) string {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return ""
+	}
+	if mimetype, err := readZipFile(archive, "mimetype"); err == nil && mimetype != nil {
+		switch strings.TrimSpace(string(mimetype)) {
+		case "application/epub+zip":
+			return "epub"
+		case "application/vnd.oasis.opendocument.text":
+			return "odt"
+		}
+	}
+	for _, f := range archive.File {
+		switch f.Name {
+		case "word/document.xml":
+			return "docx"
+		case "xl/workbook.xml":
+			return "xlsx"
+		}
+	}
+	return ""
+}
+
+// emailHeaders sont les en-têtes qui, ensemble, signalent un message RFC 822
+var emailHeaders = map[string]bool{
+	"from": true, "to": true, "cc": true, "subject": true, "date": true,
+	"message-id": true, "received": true, "return-path": true,
+	"mime-version": true, "content-type": true, "reply-to": true,
+	"delivered-to": true, "in-reply-to": true, "references": true,
+}
+
+var headerLinePattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9-]*):`)
+
+// looksLikeEmail indique si le texte commence par un bloc d'en-têtes RFC 822
+// comptant au moins deux en-têtes de courriel connus, dont From ou Received
+func looksLikeEmail(text string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 4096), sniffLen)
+	known := 0
+	sender := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			continue // suite d'un en-tête replié
+		}
+		match := headerLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			return false
+		}
+		name := strings.ToLower(match[1])
+		if emailHeaders[name] {
+			known++
+		}
+		sender = sender || name == "from" || name == "received"
+	}
+	return known >= 2 && sender
+}
+
+var (
+	srtStartPattern        = regexp.MustCompile(`^\d+\s*\n\d{1,2}:\d{2}:\d{2}[,.]\d{3}\s*-->`)
+	transcriptStartPattern = regexp.MustCompile(`^\[\d{1,2}:\d{2}(:\d{2})?(\.\d+)?\]`)
+	markdownLinePatterns   = []*regexp.Regexp{
+		regexp.MustCompile(`^#{1,6}\s+\S`),
+		regexp.MustCompile("^```"),
+		regexp.MustCompile(`^\s*[-*+]\s+\S`),
+		regexp.MustCompile(`^\s*\d+\.\s+\S`),
+		regexp.MustCompile(`^>\s?`),
+		regexp.MustCompile(`^\|?\s*:?-{3,}:?\s*(\|\s*:?-{3,}:?\s*)+\|?$`),
+		regexp.MustCompile(`\[[^\]]+\]\([^)]+\)`),
+		regexp.MustCompile(`(\*\*|__)\S.*?\S(\*\*|__)`),
+	}
+	htmlTagPattern = regexp.MustCompile(`(?i)<(head|body|div|p|table|h[1-6])[\s>]`)
+)
+
+// sniffText reconnaît un format texte par heuristique sur ses premières lignes
+func sniffText(data []byte) string {
+	text := strings.ReplaceAll(strings.TrimPrefix(string(data), "\ufeff"), "\r\n", "\n")
+	trimmed := strings.TrimLeft(text, " \t\n")
+	switch {
+	case srtStartPattern.MatchString(trimmed):
+		return "srt"
+	case transcriptStartPattern.MatchString(trimmed):
+		return "transcript"
+	case len(htmlTagPattern.FindAllString(text, 3)) >= 3:
+		return "html"
+	}
+
+	lines := strings.Split(text, "\n")
+	if len(data) == sniffLen && len(lines) > 1 {
+		lines = lines[:len(lines)-1] // dernière ligne tronquée
+	}
+	if delimiter := sniffDelimiter(lines); delimiter == '\t' {
+		return "tsv"
+	} else if delimiter != 0 {
+		return "csv"
+	}
+
+	score := 0
+	for _, line := range lines {
+		for _, pattern := range markdownLinePatterns {
+			if pattern.MatchString(line) {
+				score++
+				break
+			}
+		}
+	}
+	if score >= 3 {
+		return "markdown"
+	}
+	return ""
+}
+
+// sniffDelimiter retourne le séparateur d'un contenu tabulaire : au moins trois
+// lignes non vides comptant toutes le même nombre de séparateurs (au moins deux
+// virgules ou points-virgules, ou une tabulation)
+func sniffDelimiter(lines []string) rune {
+	var rows []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			rows = append(rows, line)
+		}
+	}
+	if len(rows) < 3 {
+		return 0
+	}
+	for _, candidate := range []struct {
+		delimiter rune
+		min       int
+	}{{'\t', 1}, {';', 2}, {',', 2}} {
+		count := strings.Count(rows[0], string(candidate.delimiter))
+		if count < candidate.min {
+			continue
+		}
+		consistent := true
+		for _, row := range rows[1:] {
+			if strings.Count(row, string(candidate.delimiter)) != count {
+				consistent = false
+				break
+			}
+		}
+		if consistent {
+			return candidate.delimiter
+		}
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	docx := buildZip(t, map[string]string{"[Content_Types].xml": "<Types/>", "word/document.xml": "<document/>"})
+	epub := buildZip(t, map[string]string{"mimetype": "application/epub+zip", "META-INF/container.xml": "<container/>"})
+	docxData, _ := io.ReadAll(docx)
+	epubData, _ := io.ReadAll(epub)
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		expected string
+	}{
+		{"PDF without extension", "report", "%PDF-1.7\n%âãÏÓ\n1 0 obj", "pdf"},
+		{"DOCX with wrong extension", "rapport.bin", string(docxData), "docx"},
+		{"EPUB by mimetype", "", string(epubData), "epub"},
+		{"HTML saved as text", "page.txt", "<!DOCTYPE html>\n<html><body><p>Bonjour</p></body></html>", "html"},
+		{"HTML fragment", "", "<div>\n<h1>Titre</h1>\n<p>Texte</p>\n</div>", "html"},
+		{"email", "message", "Received: from mx\nFrom: Jean <jean@example.com>\nTo: marie@example.com\nSubject: Bonjour\n\nCorps", "eml"},
+		{"mbox", "archive", "From jean@example.com Mon Jan  1 00:00:00 2024\nFrom: jean@example.com\nSubject: Test\n\nCorps\n", "mbox"},
+		{"WebVTT", "captions.txt", "WEBVTT\n\n00:00.000 --> 00:01.000\nBonjour", "vtt"},
+		{"SRT", "", "1\n00:00:01,000 --> 00:00:02,000\nBonjour\n", "srt"},
+		{"transcript", "", "[00:01:23] Marie: Bonjour\n", "transcript"},
+		{"markdown", "notes", "# Titre\n\nUn paragraphe avec un [lien](https://example.com).\n\n- premier\n- second\n", "markdown"},
+		{"CSV", "export", "nom;prix;ville\npain;2,5;Lyon\ncroissant;1,1;Paris\n", "csv"},
+		{"extension for ambiguous content", "notes.md", "Juste une phrase.", "markdown"},
+		{"plain text", "notes.txt", "Une phrase, puis une autre.\nEt encore une.", "text"},
+		{"text with header-like line", "", "Note: ceci n'est pas un courriel.\n", "text"},
+	}
+	for _, test := range tests {
+		if got := DetectFormat(test.filename, []byte(test.content)); got != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestDetectReplaysStream(t *testing.T) {
+	input := "<!doctype html><html><body><p>" + strings.Repeat("texte ", 3000) + "</p></body></html>"
+	format, r, err := Detect(strings.NewReader(input), "page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "html" {
+		t.Errorf("Expected html, got %s", format)
+	}
+	data, _ := io.ReadAll(r)
+	if string(data) != input {
+		t.Error("The returned reader should replay the whole stream")
+	}
+}
@@ -0,0 +1,397 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DOCXParser lit les documents Word (Office Open XML) directement depuis
+// l'archive zip
+type DOCXParser struct{}
+
+func NewDOCXParser() *DOCXParser {
+	return &DOCXParser{}
+}
+
+type docxStyle struct {
+	name    string
+	basedOn string
+	outline int
+	numID   string
+}
+
+type docxListEntry struct {
+	level   int
+	ordered bool
+	element DocumentElement
+}
+
+type docxBuilder struct {
+	styles    map[string]docxStyle
+	ordered   map[string]map[string]bool
+	notes     map[string]*xmlNode
+	links     map[string]string
+	footnotes []DocumentElement
+}
+
+func (p *DOCXParser) Parse(r io.Reader) (*Document, error) {
+	archive, err := openZip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := readZipXML(archive, "word/document.xml")
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, fmt.Errorf("invalid DOCX file: missing word/document.xml")
+	}
+
+	b := &docxBuilder{
+		styles:  make(map[string]docxStyle),
+		ordered: make(map[string]map[string]bool),
+		notes:   make(map[string]*xmlNode),
+		links:   make(map[string]string),
+	}
+	parts := map[string]func(*xmlNode){
+		"word/styles.xml":              b.loadStyles,
+		"word/numbering.xml":           b.loadNumbering,
+		"word/footnotes.xml":           func(root *xmlNode) { b.loadNotes(root, "footnote") },
+		"word/endnotes.xml":            func(root *xmlNode) { b.loadNotes(root, "endnote") },
+		"word/_rels/document.xml.rels": b.loadRelationships,
+	}
+	for name, load := range parts {
+		root, err := readZipXML(archive, name)
+		if err != nil {
+			return nil, err
+		}
+		if root != nil {
+			load(root)
+		}
+	}
+
+	structure := nestSections(b.blocks(document.find("body")))
+	if len(b.footnotes) > 0 {
+		structure = append(structure, newContainer("footnotes", nil, b.footnotes))
+	}
+
+	metadata := make(map[string]string)
+	core, err := readZipXML(archive, "docProps/core.xml")
+	if err != nil {
+		return nil, err
+	}
+	if props := core.child("coreProperties"); props != nil {
+		names := map[string]string{
+			"title":          "title",
+			"subject":        "subject",
+			"creator":        "author",
+			"keywords":       "keywords",
+			"description":    "description",
+			"lastModifiedBy": "last_modified_by",
+			"created":        "created",
+			"modified":       "modified",
+			"language":       "language",
+		}
+		for _, prop := range props.Children {
+			if key, ok := names[prop.Name]; ok {
+				if value := strings.TrimSpace(prop.text()); value != "" {
+					metadata[key] = value
+				}
+			}
+		}
+	}
+
+	return &Document{
+		Content:   TextOf(structure),
+		Metadata:  metadata,
+		Structure: structure,
+	}, nil
+}
+
+func (b *docxBuilder) loadStyles(root *xmlNode) {
+	for _, style := range root.findAll("style") {
+		s := docxStyle{
+			name:    style.child("name").attr("val"),
+			basedOn: style.child("basedOn").attr("val"),
+			outline: -1,
+		}
+		if pPr := style.child("pPr"); pPr != nil {
+			if level, err := strconv.Atoi(pPr.child("outlineLvl").attr("val")); err == nil {
+				s.outline = level
+			}
+			s.numID = pPr.child("numPr").child("numId").attr("val")
+		}
+		b.styles[style.attr("styleId")] = s
+	}
+}
+
+func (b *docxBuilder) loadNumbering(root *xmlNode) {
+	abstract := make(map[string]map[string]bool)
+	for _, def := range root.findAll("abstractNum") {
+		levels := make(map[string]bool)
+		for _, lvl := range def.findAll("lvl") {
+			format := lvl.child("numFmt").attr("val")
+			levels[lvl.attr("ilvl")] = format != "bullet" && format != "none" && format != ""
+		}
+		abstract[def.attr("abstractNumId")] = levels
+	}
+	for _, num := range root.findAll("num") {
+		b.ordered[num.attr("numId")] = abstract[num.child("abstractNumId").attr("val")]
+	}
+}
+
+func (b *docxBuilder) loadNotes(root *xmlNode, name string) {
+	for _, note := range root.findAll(name) {
+		// Les notes de type "separator" sont des éléments de mise en page
+		if note.attr("type") == "" {
+			b.notes[name+":"+note.attr("id")] = note
+		}
+	}
+}
+
+func (b *docxBuilder) loadRelationships(root *xmlNode) {
+	for _, rel := range root.findAll("Relationship") {
+		b.links[rel.attr("Id")] = rel.attr("Target")
+	}
+}
+
+// blocks convertit les paragraphes et tableaux d'un conteneur (corps, cellule)
+func (b *docxBuilder) blocks(parent *xmlNode) []DocumentElement {
+	var elements []DocumentElement
+	var list []docxListEntry
+
+	flushList := func() {
+		if len(list) > 0 {
+			elements = append(elements, buildDOCXList(list))
+			list = nil
+		}
+	}
+
+	if parent == nil {
+		return nil
+	}
+	for _, node := range parent.Children {
+		switch node.Name {
+		case "p":
+			el, numID, level := b.paragraph(node)
+			if numID != "" && numID != "0" {
+				list = append(list, docxListEntry{level: level, ordered: b.ordered[numID][strconv.Itoa(level)], element: el})
+				continue
+			}
+			flushList()
+			if el.Content != "" || len(el.Children) > 0 {
+				elements = append(elements, el)
+			}
+		case "tbl":
+			flushList()
+			elements = append(elements, b.table(node))
+		case "sdt":
+			// Contrôles de contenu ; la table des matières répète les titres
+			flushList()
+			if node.find("docPartGallery").attr("val") != "Table of Contents" {
+				elements = append(elements, b.blocks(node.child("sdtContent"))...)
+			}
+		}
+	}
+	flushList()
+	return elements
+}
+
+// paragraph convertit un paragraphe ; il retourne aussi sa numérotation
+// éventuelle (identifiant de liste et niveau)
+func (b *docxBuilder) paragraph(p *xmlNode) (DocumentElement, string, int) {
+	pPr := p.child("pPr")
+	styleID := pPr.child("pStyle").attr("val")
+	content, inline := b.inline(p)
+
+	el := DocumentElement{Type: "paragraph", Content: content, Children: inline}
+	if level, ok := b.headingLevel(styleID, pPr); ok {
+		el.Type = "heading"
+		el.Attributes = map[string]string{"level": strconv.Itoa(level)}
+		return el, "", 0
+	}
+
+	numPr := pPr.child("numPr")
+	numID := numPr.child("numId").attr("val")
+	if numID == "" {
+		numID = b.styles[styleID].numID
+	}
+	level, _ := strconv.Atoi(numPr.child("ilvl").attr("val"))
+	return el, numID, level
+}
+
+// headingLevel déduit le niveau de titre du niveau hiérarchique du paragraphe
+// ou de son style (styles « Heading n », « Titre n » et leurs dérivés)
+func (b *docxBuilder) headingLevel(styleID string, pPr *xmlNode) (int, bool) {
+	if level, err := strconv.Atoi(pPr.child("outlineLvl").attr("val")); err == nil && level < 9 {
+		return level + 1, true
+	}
+
+	for i := 0; styleID != "" && i < 10; i++ {
+		style, ok := b.styles[styleID]
+		if !ok {
+			break
+		}
+		if style.outline >= 0 && style.outline < 9 {
+			return style.outline + 1, true
+		}
+		if level, ok := headingStyleLevel(style.name); ok {
+			return level, true
+		}
+		styleID = style.basedOn
+	}
+	return headingStyleLevel(styleID)
+}
+
+// headingStyleLevel reconnaît les noms de styles de titre
+func headingStyleLevel(name string) (int, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, prefix := range []string{"heading", "titre"} {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(name, prefix))
+		if level, err := strconv.Atoi(rest); err == nil && level >= 1 && level <= 9 {
+			return level, true
+		}
+	}
+	if name == "title" || name == "titre" {
+		return 1, true
+	}
+	return 0, false
+}
+
+// inline retourne le texte d'un paragraphe ainsi que ses liens et images ; les
+// appels de note sont remplacés par leur numéro entre crochets
+func (b *docxBuilder) inline(p *xmlNode) (string, []DocumentElement) {
+	var sb strings.Builder
+	var elements []DocumentElement
+
+	var walk func(n *xmlNode)
+	walk = func(n *xmlNode) {
+		for _, part := range n.parts {
+			if part.isText() {
+				continue
+			}
+			switch part.Name {
+			case "t":
+				sb.WriteString(part.text())
+			case "tab", "br", "cr":
+				sb.WriteString(" ")
+			case "pPr", "rPr", "del", "delText", "instrText":
+				// Propriétés, texte supprimé et codes de champ ignorés
+			case "footnoteReference", "endnoteReference":
+				kind := strings.TrimSuffix(part.Name, "Reference")
+				sb.WriteString(b.noteMarker(kind, part.attr("id")))
+			case "hyperlink":
+				label, _ := b.inline(part)
+				sb.WriteString(label)
+				href := b.links[part.attr("id")]
+				if anchor := part.attr("anchor"); href == "" && anchor != "" {
+					href = "#" + anchor
+				}
+				if href != "" {
+					elements = append(elements, DocumentElement{
+						Type:       "link",
+						Content:    label,
+						Attributes: map[string]string{"href": href},
+					})
+				}
+			case "docPr":
+				if alt := part.attr("descr"); alt != "" {
+					elements = append(elements, DocumentElement{
+						Type:       "image",
+						Content:    alt,
+						Attributes: map[string]string{"alt": alt},
+					})
+				}
+			default:
+				walk(part)
+			}
+		}
+	}
+	walk(p)
+
+	return collapseWhitespace(sb.String()), elements
+}
+
+// noteMarker enregistre une note de bas de page ou de fin et retourne son appel
+func (b *docxBuilder) noteMarker(kind, id string) string {
+	note, ok := b.notes[kind+":"+id]
+	if !ok {
+		return ""
+	}
+	delete(b.notes, kind+":"+id)
+
+	var texts []string
+	for _, p := range note.findAll("p") {
+		if text, _ := b.inline(p); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	number := strconv.Itoa(len(b.footnotes) + 1)
+	b.footnotes = append(b.footnotes, DocumentElement{
+		Type:       "footnote",
+		Content:    "[" + number + "] " + strings.Join(texts, " "),
+		Attributes: map[string]string{"id": number, "kind": kind},
+	})
+	return "[" + number + "]"
+}
+
+func (b *docxBuilder) table(tbl *xmlNode) DocumentElement {
+	var rows []DocumentElement
+	for _, tr := range tbl.Children {
+		if tr.Name != "tr" {
+			continue
+		}
+		var cells []DocumentElement
+		var texts []string
+		for _, tc := range tr.Children {
+			if tc.Name != "tc" {
+				continue
+			}
+			children := b.blocks(tc)
+			content := collapseWhitespace(TextOf(children))
+			cells = append(cells, DocumentElement{Type: "cell", Content: content, Children: children})
+			texts = append(texts, content)
+		}
+		row := DocumentElement{Type: "row", Content: strings.Join(texts, " | "), Children: cells}
+		if tr.child("trPr").child("tblHeader") != nil {
+			row.Attributes = map[string]string{"header": "true"}
+		}
+		rows = append(rows, row)
+	}
+	return newContainer("table", nil, rows)
+}
+
+// buildDOCXList reconstruit l'imbrication d'une suite de paragraphes numérotés à
+// partir de leur niveau
+func buildDOCXList(entries []docxListEntry) DocumentElement {
+	base := entries[0].level
+	var items [][]DocumentElement
+	for i := 0; i < len(entries); {
+		if entries[i].level <= base {
+			items = append(items, []DocumentElement{entries[i].element})
+			i++
+			continue
+		}
+		j := i
+		for j < len(entries) && entries[j].level > base {
+			j++
+		}
+		if len(items) == 0 {
+			items = append(items, nil)
+		}
+		items[len(items)-1] = append(items[len(items)-1], buildDOCXList(entries[i:j]))
+		i = j
+	}
+
+	listItems := make([]DocumentElement, 0, len(items))
+	for _, children := range items {
+		listItems = append(listItems, newContainer("listitem", nil, children))
+	}
+	return newContainer("list", map[string]string{"ordered": strconv.FormatBool(entries[0].ordered)}, listItems)
+}
@@ -0,0 +1,337 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// EMLParser lit un message au format RFC 5322 (.eml). Les en-têtes sont repris
+// dans Metadata ("from", "to", "cc", "date", "subject", "message_id",
+// "in_reply_to", "references") et le corps MIME est décodé.
+type EMLParser struct{}
+
+func NewEMLParser() *EMLParser {
+	return &EMLParser{}
+}
+
+// MBOXParser lit une boîte aux lettres au format mbox. Parse retourne un seul
+// document contenant un élément "message" par courriel ; ParseAll retourne un
+// document par courriel.
+type MBOXParser struct{}
+
+func NewMBOXParser() *MBOXParser {
+	return &MBOXParser{}
+}
+
+func (p *EMLParser) Parse(r io.Reader) (*Document, error) {
+	return parseEmail(r)
+}
+
+func (p *MBOXParser) ParseAll(r io.Reader) ([]*Document, error) {
+	messages, err := splitMBOX(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []*Document
+	for i, raw := range messages {
+		doc, err := parseEmail(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing message %d: %w", i+1, err)
+		}
+		docs = append(docs, doc)
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no message found in mbox file")
+	}
+	return docs, nil
+}
+
+func (p *MBOXParser) Parse(r io.Reader) (*Document, error) {
+	docs, err := p.ParseAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var structure []DocumentElement
+	for _, doc := range docs {
+		attributes := make(map[string]string)
+		for _, key := range []string{"from", "subject", "date", "message_id", "in_reply_to"} {
+			if value := doc.Metadata[key]; value != "" {
+				attributes[key] = value
+			}
+		}
+		structure = append(structure, newContainer("message", attributes, doc.Structure))
+	}
+
+	return &Document{
+		Content:   TextOf(structure),
+		Metadata:  map[string]string{"messages": fmt.Sprint(len(docs))},
+		Structure: structure,
+	}, nil
+}
+
+// splitMBOX découpe une boîte mbox en messages bruts ; chaque message commence
+// par une ligne "From " et les lignes ">From " échappées sont restaurées
+func splitMBOX(r io.Reader) ([][]byte, error) {
+	var messages [][]byte
+	var current *bytes.Buffer
+	previousBlank := true
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			trimmed := strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(line, "From ") && previousBlank:
+				if current != nil {
+					messages = append(messages, current.Bytes())
+				}
+				current = &bytes.Buffer{}
+			case current != nil:
+				unquoted := strings.TrimLeft(line, ">")
+				if strings.HasPrefix(unquoted, "From ") && len(unquoted) < len(line) {
+					line = line[1:]
+				}
+				current.WriteString(line)
+			}
+			previousBlank = trimmed == ""
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if current != nil {
+		messages = append(messages, current.Bytes())
+	}
+	return messages, nil
+}
+
+var wordDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+func parseEmail(r io.Reader) (*Document, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email message: %w", err)
+	}
+
+	metadata := map[string]string{"schema_type": "EmailMessage"}
+	headers := map[string]string{
+		"From":        "from",
+		"To":          "to",
+		"Cc":          "cc",
+		"Subject":     "subject",
+		"Message-Id":  "message_id",
+		"In-Reply-To": "in_reply_to",
+		"References":  "references",
+	}
+	for header, key := range headers {
+		value := msg.Header.Get(header)
+		if value == "" {
+			continue
+		}
+		if decoded, err := wordDecoder.DecodeHeader(value); err == nil {
+			value = decoded
+		}
+		value = collapseWhitespace(value)
+		switch key {
+		case "message_id", "in_reply_to":
+			value = strings.Trim(value, "<>")
+		case "references":
+			value = strings.NewReplacer("<", "", ">", "").Replace(value)
+		}
+		metadata[key] = value
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		metadata["date"] = date.Format(time.RFC3339)
+	}
+
+	structure, err := mimeBody(msg.Header, msg.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Document{
+		Content:   TextOf(structure),
+		Metadata:  metadata,
+		Structure: structure,
+	}, nil
+}
+
+// mimeHeader donne accès aux en-têtes d'un message ou d'une partie MIME
+type mimeHeader interface {
+	Get(key string) string
+}
+
+// mimeBody décode le corps d'un message ou d'une partie MIME. Pour une partie
+// multipart/alternative, la version texte est préférée à la version HTML ; les
+// pièces jointes sont représentées par un élément "attachment".
+func mimeBody(header mimeHeader, body io.Reader) ([]DocumentElement, error) {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if disposition == "attachment" || (filename != "" && !strings.HasPrefix(mediaType, "text/")) {
+		if decoded, err := wordDecoder.DecodeHeader(filename); err == nil {
+			filename = decoded
+		}
+		return []DocumentElement{{
+			Type:       "attachment",
+			Content:    filename,
+			Attributes: map[string]string{"filename": filename, "type": mediaType},
+		}}, nil
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		var parts [][]DocumentElement
+		var types []string
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error reading MIME part: %w", err)
+			}
+			elements, err := mimeBody(part.Header, part)
+			if err != nil {
+				return nil, err
+			}
+			partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			parts = append(parts, elements)
+			types = append(types, partType)
+		}
+
+		if mediaType == "multipart/alternative" {
+			for _, preferred := range []string{"text/plain", "text/html"} {
+				for i, t := range types {
+					if t == preferred && len(parts[i]) > 0 {
+						return parts[i], nil
+					}
+				}
+			}
+			if len(parts) > 0 {
+				return parts[len(parts)-1], nil
+			}
+			return nil, nil
+		}
+
+		var elements []DocumentElement
+		for _, p := range parts {
+			elements = append(elements, p...)
+		}
+		return elements, nil
+	}
+
+	if mediaType == "message/rfc822" {
+		forwarded, err := parseEmail(body)
+		if err != nil {
+			return nil, err
+		}
+		attributes := map[string]string{}
+		for _, key := range []string{"from", "subject", "date"} {
+			if value := forwarded.Metadata[key]; value != "" {
+				attributes[key] = value
+			}
+		}
+		return []DocumentElement{newContainer("message", attributes, forwarded.Structure)}, nil
+	}
+
+	if !strings.HasPrefix(mediaType, "text/") {
+		return nil, nil
+	}
+
+	content, err := decodeTransfer(header.Get("Content-Transfer-Encoding"), body, params["charset"])
+	if err != nil {
+		return nil, err
+	}
+	if mediaType == "text/html" {
+		doc, err := NewHTMLParser().Parse(strings.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing HTML body: %w", err)
+		}
+		return doc.Structure, nil
+	}
+	return plainTextBody(content), nil
+}
+
+// decodeTransfer décode le corps selon son encodage de transfert et le convertit
+// en UTF-8 ; un corps sans jeu de caractères valide est traité par decodeText
+func decodeTransfer(encoding string, body io.Reader, charsetLabel string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+
+	if charsetLabel != "" && !strings.EqualFold(charsetLabel, "utf-8") && !strings.EqualFold(charsetLabel, "us-ascii") {
+		converted, err := charset.NewReaderLabel(charsetLabel, body)
+		if err == nil {
+			body = converted
+		}
+	}
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("error decoding message body: %w", err)
+	}
+	return decodeText(content, ""), nil
+}
+
+// plainTextBody découpe un corps texte en paragraphes ; les lignes citées (">")
+// d'une réponse forment un élément "blockquote"
+func plainTextBody(content string) []DocumentElement {
+	var elements []DocumentElement
+	var lines []string
+	quoted := false
+
+	flush := func() {
+		if len(lines) == 0 {
+			return
+		}
+		text := strings.Join(lines, "\n")
+		if quoted {
+			elements = append(elements, newContainer("blockquote", nil, plainTextBody(text)))
+		} else {
+			elements = append(elements, textParagraphs(text)...)
+		}
+		lines = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		isQuoted := strings.HasPrefix(line, ">")
+		if isQuoted != quoted && strings.TrimSpace(line) != "" {
+			flush()
+			quoted = isQuoted
+		}
+		if isQuoted {
+			line = strings.TrimPrefix(strings.TrimPrefix(line, ">"), " ")
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return elements
+}
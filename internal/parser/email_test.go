@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const multipartEmail = `From: =?UTF-8?Q?Ren=C3=A9_Cassin?= <rene@example.org>
+To: Eleanor Roosevelt <eleanor@example.org>, comite@example.org
+Cc: secretariat@example.org
+Subject: =?ISO-8859-1?Q?D=E9claration_universelle?=
+Date: Fri, 10 Dec 1948 15:04:05 +0100
+Message-ID: <decl-1@example.org>
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="mixed"
+
+--mixed
+Content-Type: multipart/alternative; boundary="alt"
+
+--alt
+Content-Type: text/plain; charset=iso-8859-1
+Content-Transfer-Encoding: quoted-printable
+
+Le texte est adopt=E9.
+
+> Merci pour le projet.
+> Cordialement
+--alt
+Content-Type: text/html; charset=utf-8
+
+<p>Le texte est adopté.</p>
+--alt--
+--mixed
+Content-Type: application/pdf; name="declaration.pdf"
+Content-Disposition: attachment; filename="declaration.pdf"
+Content-Transfer-Encoding: base64
+
+JVBERi0xLjQK
+--mixed--
+`
+
+func TestEMLParser(t *testing.T) {
+	doc, err := NewEMLParser().Parse(strings.NewReader(multipartEmail))
+	if err != nil {
+		t.Fatalf("Failed to parse email: %v", err)
+	}
+
+	expected := map[string]string{
+		"schema_type": "EmailMessage",
+		"from":        "René Cassin <rene@example.org>",
+		"subject":     "Déclaration universelle",
+		"cc":          "secretariat@example.org",
+		"message_id":  "decl-1@example.org",
+		"date":        "1948-12-10T15:04:05+01:00",
+	}
+	for key, value := range expected {
+		if doc.Metadata[key] != value {
+			t.Errorf("Metadata %s: expected %q, got %q", key, value, doc.Metadata[key])
+		}
+	}
+
+	if len(doc.Structure) != 3 {
+		t.Fatalf("Expected paragraph, quote and attachment, got %+v", doc.Structure)
+	}
+	if doc.Structure[0].Content != "Le texte est adopté." {
+		t.Errorf("Expected decoded plain text part, got %q", doc.Structure[0].Content)
+	}
+	if doc.Structure[1].Type != "blockquote" || doc.Structure[1].Content != "Merci pour le projet. Cordialement" {
+		t.Errorf("Unexpected quote: %+v", doc.Structure[1])
+	}
+	if doc.Structure[2].Type != "attachment" || doc.Structure[2].Attributes["filename"] != "declaration.pdf" {
+		t.Errorf("Unexpected attachment: %+v", doc.Structure[2])
+	}
+}
+
+func TestMBOXParser(t *testing.T) {
+	input := `From rene@example.org Fri Dec 10 15:04:05 1948
+From: rene@example.org
+Subject: Projet
+Message-ID: <1@example.org>
+
+Voici le projet.
+>From the archives.
+
+From eleanor@example.org Sat Dec 11 09:00:00 1948
+From: eleanor@example.org
+Subject: Re: Projet
+Message-ID: <2@example.org>
+In-Reply-To: <1@example.org>
+Content-Type: text/html
+
+<p>Merci !</p>
+`
+	docs, err := NewMBOXParser().ParseAll(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse mbox: %v", err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(docs))
+	}
+	if docs[0].Content != "Voici le projet. From the archives." {
+		t.Errorf("Unexpected first body: %q", docs[0].Content)
+	}
+	if docs[1].Metadata["in_reply_to"] != "1@example.org" || docs[1].Content != "Merci !" {
+		t.Errorf("Unexpected second message: %+v", docs[1])
+	}
+
+	doc, err := NewMBOXParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse mbox: %v", err)
+	}
+	if len(doc.Structure) != 2 || doc.Structure[1].Type != "message" || doc.Structure[1].Attributes["subject"] != "Re: Projet" {
+		t.Errorf("Unexpected combined structure: %+v", doc.Structure)
+	}
+}
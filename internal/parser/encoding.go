@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/unicode/norm"
+)
+
+// readText lit entièrement un flux texte et le convertit en UTF-8 (voir decodeText)
+func readText(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return decodeText(data, ""), nil
+}
+
+// decodeText convertit un contenu en UTF-8 normalisé NFC. L'encodage est, par
+// ordre de priorité : celui indiqué par une marque d'ordre des octets (UTF-8,
+// UTF-16), UTF-8 si le contenu est valide, l'encodage déclaré par le document
+// (declared), et à défaut Windows-1252, sur-ensemble d'ISO-8859-1 utilisé par
+// les archives anciennes.
+func decodeText(data []byte, declared string) string {
+	var text string
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		text = string(data[3:])
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}), bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		decoded, err := unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder().Bytes(data)
+		if err == nil {
+			text = string(decoded)
+			break
+		}
+		text = string(data)
+	case utf8.Valid(data):
+		text = string(data)
+	default:
+		text = decodeLegacy(data, declared)
+	}
+	return norm.NFC.String(text)
+}
+
+// decodeLegacy décode un contenu qui n'est pas en UTF-8 avec l'encodage déclaré,
+// ou Windows-1252 si celui-ci est absent, inconnu ou invalide
+func decodeLegacy(data []byte, declared string) string {
+	if declared != "" {
+		if enc, err := htmlindex.Get(declared); err == nil {
+			if decoded, err := enc.NewDecoder().Bytes(data); err == nil && utf8.Valid(decoded) {
+				return string(decoded)
+			}
+		}
+	}
+	decoded, _ := charmap.Windows1252.NewDecoder().Bytes(data)
+	return string(decoded)
+}
+
+var (
+	metaCharsetPattern = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([\w.:-]+)`)
+	xmlEncodingPattern = regexp.MustCompile(`^<\?xml[^>]+encoding\s*=\s*["']([\w.:-]+)["']`)
+)
+
+// declaredCharset retourne l'encodage déclaré au début d'un document HTML
+// (balise meta charset ou http-equiv) ou XML (déclaration encoding)
+func declaredCharset(data []byte) string {
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	if match := xmlEncodingPattern.FindSubmatch(bytes.TrimSpace(head)); match != nil {
+		return string(match[1])
+	}
+	if match := metaCharsetPattern.FindSubmatch(head); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
+
+// splitLines découpe un texte en lignes quelle que soit leur longueur, en
+// acceptant les fins de ligne Windows ; la fin de ligne finale est ignorée
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		declared string
+		want     string
+	}{
+		{"utf8", []byte("café"), "", "café"},
+		{"utf8 bom", []byte("\xef\xbb\xbfcafé"), "", "café"},
+		{"utf16le bom", []byte("\xff\xfec\x00a\x00f\x00\xe9\x00"), "", "café"},
+		{"utf16be bom", []byte("\xfe\xff\x00c\x00a\x00f\x00\xe9"), "", "café"},
+		{"windows-1252", []byte("caf\xe9 \x80"), "", "café €"},
+		{"declared", []byte("\xe7a"), "koi8-r", "Гa"},
+		{"nfd", []byte("cafe\u0301"), "", "café"},
+	}
+	for _, tt := range tests {
+		if got := decodeText(tt.data, tt.declared); got != tt.want {
+			t.Errorf("%s: decodeText() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHTMLParserMetaCharset(t *testing.T) {
+	page := "<html><head><meta charset=\"iso-8859-1\"><title>R\xe9sum\xe9</title></head><body><p>Th\xe9\xe2tre</p></body></html>"
+	doc, err := NewHTMLParser().Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if doc.Metadata["title"] != "Résumé" {
+		t.Errorf("title = %v, want Résumé", doc.Metadata["title"])
+	}
+	if !strings.Contains(doc.Content, "Théâtre") {
+		t.Errorf("content = %q, want Théâtre", doc.Content)
+	}
+}
+
+func TestTextParserLongLine(t *testing.T) {
+	line := strings.Repeat("a", 200*1024)
+	doc, err := NewTextParser().Parse(strings.NewReader("début\r\n" + line + "\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(doc.Structure) != 2 || doc.Structure[1].Content != line {
+		t.Fatalf("got %d paragraphs, want the long line intact", len(doc.Structure))
+	}
+}
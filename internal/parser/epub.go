@@ -0,0 +1,358 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EPUBParser lit les livres numériques EPUB 2 et 3. Les chapitres sont lus dans
+// l'ordre de lecture (spine) et imbriqués selon la table des matières ; chaque
+// chapitre est un élément "chapter" (attributs "title", "level", "position").
+type EPUBParser struct{}
+
+func NewEPUBParser() *EPUBParser {
+	return &EPUBParser{}
+}
+
+type tocEntry struct {
+	title    string
+	href     string
+	children []tocEntry
+}
+
+// chapterStart indique le titre et le niveau du chapitre qui commence avec un
+// fichier de la spine
+type chapterStart struct {
+	title string
+	level int
+}
+
+func (p *EPUBParser) Parse(r io.Reader) (*Document, error) {
+	archive, err := openZip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	container, err := readZipXML(archive, "META-INF/container.xml")
+	if err != nil {
+		return nil, err
+	}
+	opfPath := container.find("rootfile").attr("full-path")
+	if opfPath == "" {
+		return nil, fmt.Errorf("invalid EPUB file: no package document in META-INF/container.xml")
+	}
+	opf, err := readZipXML(archive, opfPath)
+	if err != nil {
+		return nil, err
+	}
+	if opf == nil {
+		return nil, fmt.Errorf("invalid EPUB file: missing package document %s", opfPath)
+	}
+	opfDir := path.Dir(opfPath)
+
+	type manifestItem struct {
+		href       string
+		mediaType  string
+		properties string
+	}
+	manifest := make(map[string]manifestItem)
+	var navPath string
+	for _, item := range opf.find("manifest").Children {
+		if item.Name != "item" {
+			continue
+		}
+		m := manifestItem{
+			href:       resolveEPUBPath(opfDir, item.attr("href")),
+			mediaType:  item.attr("media-type"),
+			properties: item.attr("properties"),
+		}
+		manifest[item.attr("id")] = m
+		if strings.Contains(" "+m.properties+" ", " nav ") {
+			navPath = m.href
+		}
+	}
+
+	// Table des matières : document de navigation EPUB 3, ou NCX EPUB 2
+	spine := opf.find("spine")
+	var toc []tocEntry
+	if navPath != "" {
+		if nav, err := readZipXML(archive, navPath); err == nil && nav != nil {
+			toc = navTOC(nav, path.Dir(navPath))
+		}
+	}
+	if len(toc) == 0 {
+		if ncx, ok := manifest[spine.attr("toc")]; ok {
+			if root, err := readZipXML(archive, ncx.href); err == nil && root != nil {
+				toc = ncxTOC(root.find("navMap"), path.Dir(ncx.href))
+			}
+		}
+	}
+
+	starts := make(map[string]chapterStart)
+	var assign func(entries []tocEntry, level int)
+	assign = func(entries []tocEntry, level int) {
+		for _, entry := range entries {
+			file := strings.SplitN(entry.href, "#", 2)[0]
+			if _, ok := starts[file]; !ok && file != "" {
+				starts[file] = chapterStart{title: entry.title, level: level}
+			}
+			assign(entry.children, level+1)
+		}
+	}
+	assign(toc, 1)
+
+	var chapters []epubChapter
+	for _, ref := range spine.Children {
+		if ref.Name != "itemref" {
+			continue
+		}
+		item, ok := manifest[ref.attr("idref")]
+		if !ok || !strings.Contains(item.mediaType, "html") {
+			continue
+		}
+		content, err := readZipFile(archive, item.href)
+		if err != nil {
+			return nil, err
+		}
+		if content == nil {
+			continue
+		}
+		chapterDoc, err := NewHTMLParser().Parse(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing chapter %s: %w", item.href, err)
+		}
+
+		start, ok := starts[item.href]
+		if !ok && len(toc) == 0 {
+			// Sans table des matières, chaque fichier est un chapitre
+			start, ok = chapterStart{title: firstHeading(chapterDoc.Structure), level: 1}, true
+		}
+		chapters = append(chapters, epubChapter{
+			start:    start,
+			isStart:  ok,
+			href:     item.href,
+			elements: chapterDoc.Structure,
+		})
+	}
+
+	structure := nestChapters(chapters)
+
+	return &Document{
+		Content:   TextOf(structure),
+		Metadata:  epubMetadata(opf.find("metadata")),
+		Structure: structure,
+	}, nil
+}
+
+type epubChapter struct {
+	start    chapterStart
+	isStart  bool
+	href     string
+	elements []DocumentElement
+}
+
+// nestChapters imbrique les fichiers de la spine selon le niveau de leur entrée
+// dans la table des matières ; un fichier absent de la table des matières
+// prolonge le chapitre précédent
+func nestChapters(files []epubChapter) []DocumentElement {
+	// Un nœud est soit un chapitre (attributes non nil), soit un élément
+	type node struct {
+		attributes map[string]string
+		level      int
+		element    DocumentElement
+		children   []*node
+	}
+
+	root := &node{attributes: map[string]string{}}
+	stack := []*node{root}
+	position := 0
+	for _, f := range files {
+		if f.isStart {
+			for len(stack) > 1 && stack[len(stack)-1].level >= f.start.level {
+				stack = stack[:len(stack)-1]
+			}
+			position++
+			chapter := &node{
+				attributes: map[string]string{
+					"title":    f.start.title,
+					"level":    strconv.Itoa(f.start.level),
+					"position": strconv.Itoa(position),
+					"href":     f.href,
+				},
+				level: f.start.level,
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, chapter)
+			stack = append(stack, chapter)
+		}
+		current := stack[len(stack)-1]
+		for _, el := range f.elements {
+			current.children = append(current.children, &node{element: el})
+		}
+	}
+
+	var build func(n *node) []DocumentElement
+	build = func(n *node) []DocumentElement {
+		result := make([]DocumentElement, 0, len(n.children))
+		for _, c := range n.children {
+			if c.attributes == nil {
+				result = append(result, c.element)
+			} else {
+				result = append(result, newContainer("chapter", c.attributes, build(c)))
+			}
+		}
+		return result
+	}
+	return build(root)
+}
+
+// navTOC lit la table des matières d'un document de navigation EPUB 3
+func navTOC(doc *xmlNode, dir string) []tocEntry {
+	navs := doc.findAll("nav")
+	if len(navs) == 0 {
+		return nil
+	}
+	toc := navs[0]
+	for _, nav := range navs {
+		if nav.attr("type") == "toc" {
+			toc = nav
+			break
+		}
+	}
+
+	var entries func(list *xmlNode) []tocEntry
+	entries = func(list *xmlNode) []tocEntry {
+		var result []tocEntry
+		if list == nil {
+			return nil
+		}
+		for _, li := range list.Children {
+			if li.Name != "li" {
+				continue
+			}
+			entry := tocEntry{children: entries(li.child("ol"))}
+			if a := li.child("a"); a != nil {
+				entry.title = collapseWhitespace(a.text())
+				entry.href = resolveEPUBPath(dir, a.attr("href"))
+			} else {
+				entry.title = collapseWhitespace(li.child("span").text())
+			}
+			result = append(result, entry)
+		}
+		return result
+	}
+	return entries(toc.child("ol"))
+}
+
+// ncxTOC lit la table des matières NCX d'un EPUB 2
+func ncxTOC(navMap *xmlNode, dir string) []tocEntry {
+	var result []tocEntry
+	if navMap == nil {
+		return nil
+	}
+	for _, point := range navMap.Children {
+		if point.Name != "navPoint" {
+			continue
+		}
+		result = append(result, tocEntry{
+			title:    collapseWhitespace(point.child("navLabel").child("text").text()),
+			href:     resolveEPUBPath(dir, point.child("content").attr("src")),
+			children: ncxTOC(point, dir),
+		})
+	}
+	return result
+}
+
+// resolveEPUBPath résout un lien relatif en chemin dans l'archive, en conservant
+// l'ancre éventuelle
+func resolveEPUBPath(dir, href string) string {
+	if href == "" {
+		return ""
+	}
+	fragment := ""
+	if i := strings.Index(href, "#"); i >= 0 {
+		href, fragment = href[:i], href[i:]
+	}
+	if unescaped, err := url.PathUnescape(href); err == nil {
+		href = unescaped
+	}
+	if href == "" {
+		return fragment
+	}
+	return path.Join(dir, href) + fragment
+}
+
+func firstHeading(elements []DocumentElement) string {
+	title := ""
+	Walk(elements, func(el DocumentElement, depth int) {
+		if title == "" && el.Type == "heading" {
+			title = el.Content
+		}
+	})
+	return title
+}
+
+var isbnPattern = regexp.MustCompile(`^(97[89])?\d{9}[\dXx]$`)
+
+// epubMetadata reprend les métadonnées Dublin Core du document OPF
+func epubMetadata(metadata *xmlNode) map[string]string {
+	result := map[string]string{"schema_type": "Book"}
+	var creators, subjects []string
+
+	for _, field := range metadata.Children {
+		value := collapseWhitespace(field.text())
+		if value == "" {
+			continue
+		}
+		switch field.Name {
+		case "title":
+			if result["title"] == "" {
+				result["title"] = value
+			}
+		case "creator":
+			creators = append(creators, value)
+		case "subject":
+			subjects = append(subjects, value)
+		case "language", "publisher", "description", "rights":
+			if result[field.Name] == "" {
+				result[field.Name] = value
+			}
+		case "date":
+			if result["date"] == "" {
+				result["date"] = value
+			}
+		case "identifier":
+			if isbn, ok := parseISBN(value, field.attr("scheme")); ok {
+				result["isbn"] = isbn
+			} else if result["identifier"] == "" {
+				result["identifier"] = value
+			}
+		}
+	}
+
+	if len(creators) > 0 {
+		result["author"] = strings.Join(creators, "; ")
+	}
+	if len(subjects) > 0 {
+		result["keywords"] = strings.Join(subjects, ", ")
+	}
+	return result
+}
+
+// parseISBN reconnaît un identifiant ISBN (schéma explicite, URN ou forme numérique)
+func parseISBN(value, scheme string) (string, bool) {
+	lower := strings.ToLower(value)
+	explicit := strings.EqualFold(scheme, "isbn") || strings.HasPrefix(lower, "urn:isbn:") || strings.HasPrefix(lower, "isbn")
+	value = strings.TrimSpace(value[strings.LastIndexAny(value, ": ")+1:])
+	digits := strings.NewReplacer("-", "", " ", "").Replace(value)
+	if isbnPattern.MatchString(digits) && (explicit || len(digits) == 13) {
+		return value, true
+	}
+	return "", false
+}
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"testing"
+)
+
+func epubFiles(opfMetadata, manifest, spine string, extra map[string]string) map[string]string {
+	files := map[string]string{
+		"mimetype": "application/epub+zip",
+		"META-INF/container.xml": `<container xmlns="urn:oasis:names:tc:opendocument:xmlns:container"><rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles></container>`,
+		"OEBPS/content.opf": `<package xmlns="http://www.idpf.org/2007/opf" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+<metadata>` + opfMetadata + `</metadata><manifest>` + manifest + `</manifest>` + spine + `</package>`,
+	}
+	for name, content := range extra {
+		files[name] = content
+	}
+	return files
+}
+
+func chapterHTML(title, body string) string {
+	return `<?xml version="1.0" encoding="utf-8"?><html xmlns="http://www.w3.org/1999/xhtml"><head><title>` + title + `</title></head><body>` + body + `</body></html>`
+}
+
+func TestEPUBParser(t *testing.T) {
+	archive := buildZip(t, epubFiles(
+		`<dc:title>Évangile selon Jean</dc:title><dc:creator>Jean</dc:creator><dc:language>fr</dc:language>
+<dc:publisher>Éditions du Cerf</dc:publisher><dc:identifier opf:scheme="ISBN">978-2-204-12345-6</dc:identifier>`,
+		`<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+<item id="c1" href="text/ch1.xhtml" media-type="application/xhtml+xml"/>
+<item id="c1b" href="text/ch1b.xhtml" media-type="application/xhtml+xml"/>
+<item id="c2" href="text/ch2.xhtml" media-type="application/xhtml+xml"/>
+<item id="c3" href="text/ch3.xhtml" media-type="application/xhtml+xml"/>`,
+		`<spine><itemref idref="c1"/><itemref idref="c1b"/><itemref idref="c2"/><itemref idref="c3"/></spine>`,
+		map[string]string{
+			"OEBPS/nav.xhtml": `<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops"><body><nav epub:type="toc"><ol>
+<li><a href="text/ch1.xhtml">Prologue</a><ol><li><a href="text/ch2.xhtml#v1">Le témoignage</a></li></ol></li>
+<li><a href="text/ch3.xhtml">Les noces de Cana</a></li></ol></nav></body></html>`,
+			"OEBPS/text/ch1.xhtml":  chapterHTML("Prologue", `<p>Au commencement était le Verbe.</p>`),
+			"OEBPS/text/ch1b.xhtml": chapterHTML("Suite", `<p>Et le Verbe était Dieu.</p>`),
+			"OEBPS/text/ch2.xhtml":  chapterHTML("Témoignage", `<h1 id="v1">Le témoignage</h1><p>Voici le témoignage de Jean.</p>`),
+			"OEBPS/text/ch3.xhtml":  chapterHTML("Cana", `<p>Il y eut des noces à Cana.</p>`),
+		},
+	))
+
+	doc, err := NewEPUBParser().Parse(archive)
+	if err != nil {
+		t.Fatalf("Failed to parse EPUB: %v", err)
+	}
+
+	expected := map[string]string{
+		"schema_type": "Book",
+		"title":       "Évangile selon Jean",
+		"author":      "Jean",
+		"language":    "fr",
+		"publisher":   "Éditions du Cerf",
+		"isbn":        "978-2-204-12345-6",
+	}
+	for key, value := range expected {
+		if doc.Metadata[key] != value {
+			t.Errorf("Metadata %s: expected %q, got %q", key, value, doc.Metadata[key])
+		}
+	}
+
+	if len(doc.Structure) != 2 {
+		t.Fatalf("Expected 2 top-level chapters, got %+v", doc.Structure)
+	}
+	prologue := doc.Structure[0]
+	if prologue.Type != "chapter" || prologue.Attributes["title"] != "Prologue" || prologue.Attributes["position"] != "1" {
+		t.Errorf("Unexpected first chapter: %+v", prologue.Attributes)
+	}
+	// Le fichier absent de la table des matières prolonge le prologue
+	if len(prologue.Children) != 3 || prologue.Children[1].Content != "Et le Verbe était Dieu." {
+		t.Fatalf("Unexpected prologue content: %+v", prologue.Children)
+	}
+	nested := prologue.Children[2]
+	if nested.Type != "chapter" || nested.Attributes["title"] != "Le témoignage" || nested.Attributes["level"] != "2" {
+		t.Errorf("Expected nested chapter, got %+v", nested)
+	}
+	if doc.Structure[1].Attributes["position"] != "3" || doc.Structure[1].Content != "Il y eut des noces à Cana." {
+		t.Errorf("Unexpected last chapter: %+v", doc.Structure[1])
+	}
+}
+
+func TestEPUBParserNCX(t *testing.T) {
+	archive := buildZip(t, epubFiles(
+		`<dc:title>Recueil</dc:title><dc:identifier>urn:isbn:9782070360024</dc:identifier>`,
+		`<item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+<item id="a" href="a.html" media-type="application/xhtml+xml"/>
+<item id="b" href="b.html" media-type="application/xhtml+xml"/>`,
+		`<spine toc="ncx"><itemref idref="a"/><itemref idref="b"/></spine>`,
+		map[string]string{
+			"OEBPS/toc.ncx": `<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/"><navMap>
+<navPoint id="p1"><navLabel><text>Premier</text></navLabel><content src="a.html"/></navPoint>
+<navPoint id="p2"><navLabel><text>Second</text></navLabel><content src="b.html"/></navPoint></navMap></ncx>`,
+			"OEBPS/a.html": chapterHTML("A", `<p>Un.</p>`),
+			"OEBPS/b.html": chapterHTML("B", `<p>Deux.</p>`),
+		},
+	))
+
+	doc, err := NewEPUBParser().Parse(archive)
+	if err != nil {
+		t.Fatalf("Failed to parse EPUB: %v", err)
+	}
+	if doc.Metadata["isbn"] != "9782070360024" {
+		t.Errorf("Expected ISBN from URN identifier, got %q", doc.Metadata["isbn"])
+	}
+	if len(doc.Structure) != 2 || doc.Structure[1].Attributes["title"] != "Second" {
+		t.Errorf("Unexpected chapters: %+v", doc.Structure)
+	}
+}
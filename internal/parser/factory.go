@@ -12,6 +12,28 @@ func NewParser(fileType string) (Parser, error) {
 		return NewPDFParser(), nil
 	case "html":
 		return NewHTMLParser(), nil
+	case "docx":
+		return NewDOCXParser(), nil
+	case "odt":
+		return NewODTParser(), nil
+	case "epub":
+		return NewEPUBParser(), nil
+	case "srt":
+		return NewSRTParser(), nil
+	case "vtt":
+		return NewVTTParser(), nil
+	case "transcript":
+		return NewTranscriptParser(), nil
+	case "eml":
+		return NewEMLParser(), nil
+	case "mbox":
+		return NewMBOXParser(), nil
+	case "csv":
+		return NewCSVParser(), nil
+	case "tsv":
+		return NewTSVParser(), nil
+	case "xlsx":
+		return NewXLSXParser(), nil
 	default:
 		return nil, fmt.Errorf("unsupported file type: %s", fileType)
 	}
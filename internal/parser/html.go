@@ -2,6 +2,7 @@ package parser
 
 import (
 	"io"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/html"
@@ -13,42 +14,384 @@ func NewHTMLParser() *HTMLParser {
 	return &HTMLParser{}
 }
 
+// Parse décode la page selon son encodage (voir decodeText), en tenant compte de
+// la balise meta charset
 func (p *HTMLParser) Parse(r io.Reader) (*Document, error) {
-	doc, err := html.Parse(r)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(strings.NewReader(decodeText(data, declaredCharset(data))))
 	if err != nil {
 		return nil, err
 	}
 
-	var contentBuilder strings.Builder
-	var structure []DocumentElement
+	structure := nestSections(htmlBlocks(doc))
 
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		if n.Type == html.TextNode {
-			contentBuilder.WriteString(n.Data)
-		}
+	return &Document{
+		Content:        TextOf(structure),
+		Metadata:       htmlMetadata(doc),
+		Structure:      structure,
+		StructuredData: htmlStructuredData(doc),
+	}, nil
+}
+
+// htmlMetaKeys associe les clés de métadonnées aux balises meta qui les
+// renseignent, par ordre de préférence
+var htmlMetaKeys = []struct {
+	key   string
+	names []string
+}{
+	{"title", []string{"og:title", "twitter:title"}},
+	{"description", []string{"description", "og:description", "twitter:description"}},
+	{"keywords", []string{"keywords", "news_keywords"}},
+	{"author", []string{"author", "article:author", "twitter:creator"}},
+	{"publisher", []string{"og:site_name", "publisher"}},
+	{"url", []string{"og:url", "twitter:url"}},
+	{"image", []string{"og:image", "twitter:image"}},
+	{"date", []string{"article:published_time", "date", "dc.date"}},
+	{"modified", []string{"article:modified_time", "og:updated_time"}},
+	{"language", []string{"og:locale", "content-language"}},
+}
+
+// htmlMetadata lit le titre, la langue, l'URL canonique et les balises meta
+// (description, OpenGraph, Twitter). Les balises og:, twitter: et article: sont
+// aussi conservées sous leur propre nom.
+func htmlMetadata(doc *html.Node) map[string]string {
+	metadata := make(map[string]string)
+	meta := make(map[string]string)
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
 		if n.Type == html.ElementNode {
-			element := DocumentElement{
-				Type: n.Data,
+			switch n.Data {
+			case "html":
+				if lang := htmlAttr(n, "lang"); lang != "" {
+					metadata["language"] = lang
+				}
+			case "title":
+				if text := collapseWhitespace(htmlRawText(n)); text != "" && metadata["title"] == "" {
+					metadata["title"] = text
+				}
+			case "link":
+				if strings.EqualFold(htmlAttr(n, "rel"), "canonical") && htmlAttr(n, "href") != "" {
+					metadata["url"] = htmlAttr(n, "href")
+				}
+			case "meta":
+				name := htmlAttr(n, "property")
+				if name == "" {
+					name = htmlAttr(n, "name")
+				}
+				if name == "" {
+					name = htmlAttr(n, "http-equiv")
+				}
+				name = strings.ToLower(name)
+				content := strings.TrimSpace(htmlAttr(n, "content"))
+				if name != "" && content != "" && meta[name] == "" {
+					meta[name] = content
+				}
+			case "body":
+				return
 			}
-			for c := n.FirstChild; c != nil; c = c.NextSibling {
-				f(c)
-				if c.Type == html.TextNode {
-					element.Content += c.Data
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	for _, entry := range htmlMetaKeys {
+		if metadata[entry.key] != "" {
+			continue
+		}
+		for _, name := range entry.names {
+			if meta[name] != "" {
+				metadata[entry.key] = meta[name]
+				break
+			}
+		}
+	}
+	for name, content := range meta {
+		if strings.HasPrefix(name, "og:") || strings.HasPrefix(name, "twitter:") || strings.HasPrefix(name, "article:") {
+			metadata[name] = content
+		}
+	}
+	return metadata
+}
+
+// isHTMLHidden indique si un élément n'est pas affiché : scripts, styles,
+// modèles et éléments marqués hidden
+func isHTMLHidden(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.Data {
+	case "script", "style", "noscript", "template":
+		return true
+	}
+	return htmlHasAttr(n, "hidden") || htmlAttr(n, "aria-hidden") == "true"
+}
+
+// htmlTransparentTags sont les conteneurs sans sémantique propre : leurs blocs
+// sont remontés au niveau du parent
+var htmlTransparentTags = map[string]bool{
+	"html": true, "body": true, "div": true, "section": true,
+	"article": true, "main": true, "header": true, "footer": true, "nav": true,
+	"aside": true, "figure": true, "form": true, "dl": true, "details": true,
+	"thead": true, "tbody": true, "tfoot": true,
+}
+
+// htmlParagraphTags sont les blocs dont le contenu est du texte
+var htmlParagraphTags = map[string]bool{
+	"p": true, "figcaption": true, "dt": true, "dd": true, "summary": true,
+	"address": true, "caption": true,
+}
+
+func isHTMLBlock(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.Data {
+	case "head", "ul", "ol", "li", "table", "tr", "td", "th", "blockquote", "pre", "hr":
+		return true
+	}
+	_, isHeading := htmlHeadingLevel(n)
+	return isHeading || htmlTransparentTags[n.Data] || htmlParagraphTags[n.Data]
+}
+
+func htmlHeadingLevel(n *html.Node) (int, bool) {
+	if len(n.Data) == 2 && n.Data[0] == 'h' && n.Data[1] >= '1' && n.Data[1] <= '6' {
+		return int(n.Data[1] - '0'), true
+	}
+	return 0, false
+}
+
+func htmlAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// htmlBlocks convertit les enfants d'un nœud en blocs ; le texte et les éléments
+// en ligne situés entre deux blocs forment un paragraphe
+func htmlBlocks(parent *html.Node) []DocumentElement {
+	var elements []DocumentElement
+	var pending []*html.Node
+
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		content, inline := htmlInline(pending)
+		if content != "" || len(inline) > 0 {
+			elements = append(elements, DocumentElement{Type: "paragraph", Content: content, Children: inline})
+		}
+		pending = nil
+	}
+
+	for c := parent.FirstChild; c != nil; c = c.NextSibling {
+		if isHTMLHidden(c) {
+			continue
+		}
+		if isHTMLBlock(c) {
+			flush()
+			elements = append(elements, htmlBlock(c)...)
+			continue
+		}
+		if c.Type == html.TextNode || c.Type == html.ElementNode {
+			pending = append(pending, c)
+		}
+	}
+	flush()
+	return elements
+}
+
+func htmlBlock(n *html.Node) []DocumentElement {
+	if level, ok := htmlHeadingLevel(n); ok {
+		content, inline := htmlInline(childNodes(n))
+		if content == "" {
+			return nil
+		}
+		return []DocumentElement{{
+			Type:       "heading",
+			Content:    content,
+			Attributes: map[string]string{"level": strconv.Itoa(level)},
+			Children:   inline,
+		}}
+	}
+	if htmlTransparentTags[n.Data] {
+		return htmlBlocks(n)
+	}
+	if htmlParagraphTags[n.Data] {
+		content, inline := htmlInline(childNodes(n))
+		if content == "" && len(inline) == 0 {
+			return nil
+		}
+		return []DocumentElement{{Type: "paragraph", Content: content, Children: inline}}
+	}
+
+	switch n.Data {
+	case "head":
+		// L'en-tête ne contient que des métadonnées, lues par htmlMetadata
+		return nil
+	case "ul", "ol":
+		var items []DocumentElement
+		for _, el := range htmlBlocks(n) {
+			if el.Type != "listitem" {
+				el = newContainer("listitem", nil, []DocumentElement{el})
+			}
+			items = append(items, el)
+		}
+		attributes := map[string]string{"ordered": strconv.FormatBool(n.Data == "ol")}
+		if n.Data == "ol" {
+			start := htmlAttr(n, "start")
+			if start == "" {
+				start = "1"
+			}
+			attributes["start"] = start
+		}
+		return []DocumentElement{newContainer("list", attributes, items)}
+	case "li":
+		return []DocumentElement{newContainer("listitem", nil, htmlBlocks(n))}
+	case "blockquote":
+		var attributes map[string]string
+		if cite := htmlAttr(n, "cite"); cite != "" {
+			attributes = map[string]string{"cite": cite}
+		}
+		return []DocumentElement{newContainer("blockquote", attributes, htmlBlocks(n))}
+	case "table":
+		var rows []DocumentElement
+		for _, el := range htmlBlocks(n) {
+			if el.Type == "row" {
+				rows = append(rows, el)
+			}
+		}
+		return []DocumentElement{newContainer("table", nil, rows)}
+	case "tr":
+		var cells []DocumentElement
+		var texts []string
+		header := true
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || (c.Data != "td" && c.Data != "th") {
+				continue
+			}
+			header = header && c.Data == "th"
+			cell := htmlBlock(c)[0]
+			cells = append(cells, cell)
+			texts = append(texts, cell.Content)
+		}
+		if len(cells) == 0 {
+			return nil
+		}
+		row := DocumentElement{Type: "row", Content: strings.Join(texts, " | "), Children: cells}
+		if header {
+			row.Attributes = map[string]string{"header": "true"}
+		}
+		return []DocumentElement{row}
+	case "td", "th":
+		content, inline := htmlInline(childNodes(n))
+		return []DocumentElement{{Type: "cell", Content: content, Children: inline}}
+	case "pre":
+		el := DocumentElement{Type: "code", Content: strings.Trim(htmlRawText(n), "\n")}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.Data == "code" {
+				for _, class := range strings.Fields(htmlAttr(c, "class")) {
+					if strings.HasPrefix(class, "language-") {
+						el.Attributes = map[string]string{"language": strings.TrimPrefix(class, "language-")}
+					}
 				}
 			}
-			structure = append(structure, element)
-		} else {
-			for c := n.FirstChild; c != nil; c = c.NextSibling {
-				f(c)
+		}
+		return []DocumentElement{el}
+	}
+	return nil
+}
+
+func childNodes(n *html.Node) []*html.Node {
+	var nodes []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		nodes = append(nodes, c)
+	}
+	return nodes
+}
+
+// htmlInline retourne le texte d'une suite de nœuds ainsi que leurs liens et images
+func htmlInline(nodes []*html.Node) (string, []DocumentElement) {
+	var sb strings.Builder
+	var elements []DocumentElement
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			sb.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if isHTMLHidden(n) {
+				return
+			}
+		default:
+			return
+		}
+
+		switch n.Data {
+		case "br":
+			sb.WriteString(" ")
+			return
+		case "img":
+			alt := htmlAttr(n, "alt")
+			sb.WriteString(" " + alt + " ")
+			elements = append(elements, DocumentElement{
+				Type:       "image",
+				Content:    alt,
+				Attributes: map[string]string{"src": htmlAttr(n, "src"), "alt": alt},
+			})
+			return
+		case "a":
+			label, _ := htmlInline(childNodes(n))
+			sb.WriteString(label)
+			if href := htmlAttr(n, "href"); href != "" {
+				elements = append(elements, DocumentElement{
+					Type:       "link",
+					Content:    label,
+					Attributes: map[string]string{"href": href},
+				})
 			}
+			return
+		}
+
+		if isHTMLBlock(n) {
+			sb.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if isHTMLBlock(n) {
+			sb.WriteString(" ")
 		}
 	}
-	f(doc)
+	for _, n := range nodes {
+		walk(n)
+	}
 
-	return &Document{
-		Content:   contentBuilder.String(),
-		Metadata:  make(map[string]string),
-		Structure: structure,
-	}, nil
+	return collapseWhitespace(sb.String()), elements
+}
+
+// htmlRawText retourne le texte d'un nœud sans normaliser les espaces
+func htmlRawText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
 }
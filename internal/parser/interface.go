@@ -8,14 +8,30 @@ type Document struct {
 	Content   string
 	Metadata  map[string]string
 	Structure []DocumentElement
+	// StructuredData contient les objets JSON-LD déjà déclarés par le document
+	// source (JSON-LD, microdata ou RDFa d'une page HTML)
+	StructuredData []map[string]interface{}
 }
 
+// DocumentElement est un nœud de l'arbre du document. Les conteneurs ("section",
+// "list", "listitem", "table", "blockquote", "page") portent leurs blocs dans
+// Children ; les blocs ("heading", "paragraph", "row", "cell", "code") portent
+// leur texte dans Content et éventuellement leurs éléments en ligne ("link",
+// "image") dans Children. Attributes contient par exemple "level", "href", "alt".
 type DocumentElement struct {
-	Type     string // e.g., "paragraph", "heading", "list", etc.
-	Content  string
-	Children []DocumentElement
+	Type       string // e.g., "paragraph", "heading", "list", etc.
+	Content    string
+	Attributes map[string]string
+	Children   []DocumentElement
 }
 
 type Parser interface {
 	Parse(r io.Reader) (*Document, error)
 }
+
+// MultiParser est implémenté par les parseurs de fichiers regroupant plusieurs
+// documents (boîtes mbox) : ParseAll retourne un document par élément
+type MultiParser interface {
+	Parser
+	ParseAll(r io.Reader) ([]*Document, error)
+}
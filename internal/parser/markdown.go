@@ -2,9 +2,13 @@ package parser
 
 import (
 	"io"
+	"strconv"
+	"strings"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
 	"github.com/yuin/goldmark/text"
 )
 
@@ -15,47 +19,161 @@ func NewMarkdownParser() *MarkdownParser {
 }
 
 func (p *MarkdownParser) Parse(r io.Reader) (*Document, error) {
-	content, err := io.ReadAll(r)
+	source, err := readText(r)
 	if err != nil {
 		return nil, err
 	}
+	content := []byte(source)
 
-	md := goldmark.New()
+	md := goldmark.New(goldmark.WithExtensions(extension.Table))
 	reader := text.NewReader(content)
 	doc := md.Parser().Parse(reader)
 
-	var structure []DocumentElement
-	err = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
-		if !entering {
-			return ast.WalkContinue, nil
+	b := &markdownBuilder{source: content}
+	structure := nestSections(b.blocks(doc))
+
+	return &Document{
+		Content:   string(content),
+		Metadata:  make(map[string]string),
+		Structure: structure,
+	}, nil
+}
+
+// markdownBuilder convertit l'AST goldmark en arbre de DocumentElement
+type markdownBuilder struct {
+	source []byte
+}
+
+func (b *markdownBuilder) blocks(parent ast.Node) []DocumentElement {
+	var elements []DocumentElement
+	for n := parent.FirstChild(); n != nil; n = n.NextSibling() {
+		if el, ok := b.block(n); ok {
+			elements = append(elements, el)
 		}
+	}
+	return elements
+}
 
-		switch n.Kind() {
-		case ast.KindHeading:
-			heading := n.(*ast.Heading)
-			structure = append(structure, DocumentElement{
-				Type:    "heading",
-				Content: string(heading.Text(content)),
-			})
-		case ast.KindParagraph:
-			paragraph := n.(*ast.Paragraph)
-			structure = append(structure, DocumentElement{
-				Type:    "paragraph",
-				Content: string(paragraph.Text(content)),
-			})
-			// Add more cases for other Markdown elements as needed
+func (b *markdownBuilder) block(n ast.Node) (DocumentElement, bool) {
+	switch node := n.(type) {
+	case *ast.Heading:
+		content, inline := b.inline(node)
+		return DocumentElement{
+			Type:       "heading",
+			Content:    content,
+			Attributes: map[string]string{"level": strconv.Itoa(node.Level)},
+			Children:   inline,
+		}, true
+	case *ast.Paragraph, *ast.TextBlock:
+		content, inline := b.inline(node)
+		if content == "" && len(inline) == 0 {
+			return DocumentElement{}, false
+		}
+		return DocumentElement{Type: "paragraph", Content: content, Children: inline}, true
+	case *ast.List:
+		attributes := map[string]string{"ordered": strconv.FormatBool(node.IsOrdered())}
+		if node.IsOrdered() {
+			attributes["start"] = strconv.Itoa(node.Start)
+		}
+		return newContainer("list", attributes, b.blocks(node)), true
+	case *ast.ListItem:
+		return newContainer("listitem", nil, b.blocks(node)), true
+	case *ast.Blockquote:
+		return newContainer("blockquote", nil, b.blocks(node)), true
+	case *ast.FencedCodeBlock:
+		el := DocumentElement{Type: "code", Content: b.lines(node)}
+		if language := string(node.Language(b.source)); language != "" {
+			el.Attributes = map[string]string{"language": language}
+		}
+		return el, true
+	case *ast.CodeBlock:
+		return DocumentElement{Type: "code", Content: b.lines(node)}, true
+	case *east.Table:
+		return newContainer("table", nil, b.blocks(node)), true
+	case *east.TableHeader, *east.TableRow:
+		var cells []DocumentElement
+		var texts []string
+		for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+			content, inline := b.inline(c)
+			cells = append(cells, DocumentElement{Type: "cell", Content: content, Children: inline})
+			texts = append(texts, content)
+		}
+		row := DocumentElement{Type: "row", Content: strings.Join(texts, " | "), Children: cells}
+		if _, isHeader := node.(*east.TableHeader); isHeader {
+			row.Attributes = map[string]string{"header": "true"}
 		}
+		return row, true
+	}
+	return DocumentElement{}, false
+}
+
+// lines retourne le texte brut d'un bloc de code
+func (b *markdownBuilder) lines(n ast.Node) string {
+	var sb strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		sb.Write(segment.Value(b.source))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
 
-		return ast.WalkContinue, nil
-	})
+// inline retourne le texte d'un bloc ainsi que ses liens et images
+func (b *markdownBuilder) inline(n ast.Node) (string, []DocumentElement) {
+	var sb strings.Builder
+	var elements []DocumentElement
 
-	if err != nil {
-		return nil, err
+	var walk func(n ast.Node)
+	walk = func(n ast.Node) {
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			switch node := c.(type) {
+			case *ast.Text:
+				sb.Write(node.Segment.Value(b.source))
+				if node.SoftLineBreak() || node.HardLineBreak() {
+					sb.WriteString(" ")
+				}
+			case *ast.String:
+				sb.Write(node.Value)
+			case *ast.CodeSpan:
+				sb.WriteString(b.plain(node))
+			case *ast.AutoLink:
+				url := string(node.URL(b.source))
+				sb.WriteString(url)
+				elements = append(elements, DocumentElement{
+					Type:       "link",
+					Content:    url,
+					Attributes: map[string]string{"href": url},
+				})
+			case *ast.Link:
+				label := b.plain(node)
+				sb.WriteString(label)
+				elements = append(elements, DocumentElement{
+					Type:       "link",
+					Content:    label,
+					Attributes: map[string]string{"href": string(node.Destination)},
+				})
+			case *ast.Image:
+				alt := b.plain(node)
+				sb.WriteString(alt)
+				elements = append(elements, DocumentElement{
+					Type:       "image",
+					Content:    alt,
+					Attributes: map[string]string{"src": string(node.Destination), "alt": alt},
+				})
+			case *ast.RawHTML:
+				// Balises HTML en ligne ignorées
+			default:
+				walk(c)
+			}
+		}
 	}
+	walk(n)
 
-	return &Document{
-		Content:   string(content),
-		Metadata:  make(map[string]string),
-		Structure: structure,
-	}, nil
+	return collapseWhitespace(sb.String()), elements
+}
+
+// plain retourne le texte des descendants d'un nœud en ligne
+func (b *markdownBuilder) plain(n ast.Node) string {
+	text, _ := b.inline(n)
+	return text
 }
@@ -0,0 +1,277 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ODTParser lit les documents texte OpenDocument (LibreOffice) directement
+// depuis l'archive zip
+type ODTParser struct{}
+
+func NewODTParser() *ODTParser {
+	return &ODTParser{}
+}
+
+type odtBuilder struct {
+	// ordered indique, par style de liste et par niveau, si la liste est numérotée
+	ordered   map[string]map[int]bool
+	footnotes []DocumentElement
+}
+
+func (p *ODTParser) Parse(r io.Reader) (*Document, error) {
+	archive, err := openZip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := readZipXML(archive, "content.xml")
+	if err != nil {
+		return nil, err
+	}
+	if content == nil {
+		return nil, fmt.Errorf("invalid ODT file: missing content.xml")
+	}
+	styles, err := readZipXML(archive, "styles.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	b := &odtBuilder{ordered: make(map[string]map[int]bool)}
+	b.loadListStyles(styles)
+	b.loadListStyles(content)
+
+	structure := nestSections(b.blocks(content.find("body").child("text"), "", 0))
+	if len(b.footnotes) > 0 {
+		structure = append(structure, newContainer("footnotes", nil, b.footnotes))
+	}
+
+	meta, err := readZipXML(archive, "meta.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Document{
+		Content:   TextOf(structure),
+		Metadata:  odtMetadata(meta),
+		Structure: structure,
+	}, nil
+}
+
+func odtMetadata(root *xmlNode) map[string]string {
+	metadata := make(map[string]string)
+	names := map[string]string{
+		"title":           "title",
+		"subject":         "subject",
+		"description":     "description",
+		"initial-creator": "author",
+		"creator":         "last_modified_by",
+		"creation-date":   "created",
+		"date":            "modified",
+		"language":        "language",
+	}
+	var keywords []string
+	for _, prop := range root.find("meta").Children {
+		value := strings.TrimSpace(prop.text())
+		if value == "" {
+			continue
+		}
+		if prop.Name == "keyword" {
+			keywords = append(keywords, value)
+		} else if key, ok := names[prop.Name]; ok {
+			metadata[key] = value
+		}
+	}
+	if len(keywords) > 0 {
+		metadata["keywords"] = strings.Join(keywords, ", ")
+	}
+	if metadata["author"] == "" && metadata["last_modified_by"] != "" {
+		metadata["author"] = metadata["last_modified_by"]
+	}
+	return metadata
+}
+
+func (b *odtBuilder) loadListStyles(root *xmlNode) {
+	for _, style := range root.findAll("list-style") {
+		levels := make(map[int]bool)
+		for _, level := range style.Children {
+			n, err := strconv.Atoi(level.attr("level"))
+			if err != nil {
+				continue
+			}
+			levels[n] = level.Name == "list-level-style-number"
+		}
+		b.ordered[style.attr("name")] = levels
+	}
+}
+
+// blocks convertit les éléments d'un conteneur ; listStyle et listLevel
+// décrivent la liste englobante éventuelle
+func (b *odtBuilder) blocks(parent *xmlNode, listStyle string, listLevel int) []DocumentElement {
+	if parent == nil {
+		return nil
+	}
+
+	var elements []DocumentElement
+	for _, node := range parent.Children {
+		switch node.Name {
+		case "h":
+			level, err := strconv.Atoi(node.attr("outline-level"))
+			if err != nil || level < 1 {
+				level = 1
+			}
+			content, inline := b.inline(node)
+			if content != "" {
+				elements = append(elements, DocumentElement{
+					Type:       "heading",
+					Content:    content,
+					Attributes: map[string]string{"level": strconv.Itoa(level)},
+					Children:   inline,
+				})
+			}
+		case "p":
+			content, inline := b.inline(node)
+			if content != "" || len(inline) > 0 {
+				elements = append(elements, DocumentElement{Type: "paragraph", Content: content, Children: inline})
+			}
+		case "list":
+			style := node.attr("style-name")
+			if style == "" {
+				style = listStyle
+			}
+			elements = append(elements, b.list(node, style, listLevel+1))
+		case "table":
+			elements = append(elements, b.table(node))
+		case "section":
+			elements = append(elements, b.blocks(node, listStyle, listLevel)...)
+		}
+	}
+	return elements
+}
+
+func (b *odtBuilder) list(node *xmlNode, style string, level int) DocumentElement {
+	var items []DocumentElement
+	for _, item := range node.Children {
+		if item.Name == "list-item" || item.Name == "list-header" {
+			items = append(items, newContainer("listitem", nil, b.blocks(item, style, level)))
+		}
+	}
+	ordered := b.ordered[style][level]
+	return newContainer("list", map[string]string{"ordered": strconv.FormatBool(ordered)}, items)
+}
+
+func (b *odtBuilder) table(node *xmlNode) DocumentElement {
+	var rows []DocumentElement
+	var walk func(n *xmlNode, header bool)
+	walk = func(n *xmlNode, header bool) {
+		for _, c := range n.Children {
+			switch c.Name {
+			case "table-header-rows":
+				walk(c, true)
+			case "table-rows", "table-row-group":
+				walk(c, header)
+			case "table-row":
+				var cells []DocumentElement
+				var texts []string
+				for _, cell := range c.Children {
+					if cell.Name != "table-cell" {
+						continue
+					}
+					children := b.blocks(cell, "", 0)
+					content := collapseWhitespace(TextOf(children))
+					cells = append(cells, DocumentElement{Type: "cell", Content: content, Children: children})
+					texts = append(texts, content)
+				}
+				row := DocumentElement{Type: "row", Content: strings.Join(texts, " | "), Children: cells}
+				if header {
+					row.Attributes = map[string]string{"header": "true"}
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+	walk(node, false)
+
+	var attributes map[string]string
+	if name := node.attr("name"); name != "" {
+		attributes = map[string]string{"name": name}
+	}
+	return newContainer("table", attributes, rows)
+}
+
+// inline retourne le texte d'un paragraphe ainsi que ses liens et images ; les
+// notes sont remplacées par leur numéro entre crochets
+func (b *odtBuilder) inline(p *xmlNode) (string, []DocumentElement) {
+	var sb strings.Builder
+	var elements []DocumentElement
+
+	var walk func(n *xmlNode)
+	walk = func(n *xmlNode) {
+		for _, part := range n.parts {
+			if part.isText() {
+				sb.WriteString(part.Text)
+				continue
+			}
+			switch part.Name {
+			case "s", "tab", "line-break":
+				sb.WriteString(" ")
+			case "note":
+				sb.WriteString(b.note(part))
+			case "a":
+				label, _ := b.inline(part)
+				sb.WriteString(label)
+				if href := part.attr("href"); href != "" {
+					elements = append(elements, DocumentElement{
+						Type:       "link",
+						Content:    label,
+						Attributes: map[string]string{"href": href},
+					})
+				}
+			case "frame":
+				image := part.child("image")
+				if image == nil {
+					continue
+				}
+				alt := strings.TrimSpace(part.child("title").text())
+				if alt == "" {
+					alt = strings.TrimSpace(part.child("desc").text())
+				}
+				elements = append(elements, DocumentElement{
+					Type:       "image",
+					Content:    alt,
+					Attributes: map[string]string{"src": image.attr("href"), "alt": alt},
+				})
+			case "annotation", "tracked-changes", "bookmark-ref":
+				// Commentaires et suivi des modifications ignorés
+			default:
+				walk(part)
+			}
+		}
+	}
+	walk(p)
+
+	return collapseWhitespace(sb.String()), elements
+}
+
+// note enregistre une note de bas de page ou de fin et retourne son appel
+func (b *odtBuilder) note(node *xmlNode) string {
+	var texts []string
+	for _, p := range node.child("note-body").findAll("p") {
+		if text, _ := b.inline(p); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	kind := node.attr("note-class")
+	if kind == "" {
+		kind = "footnote"
+	}
+	number := strconv.Itoa(len(b.footnotes) + 1)
+	b.footnotes = append(b.footnotes, DocumentElement{
+		Type:       "footnote",
+		Content:    "[" + number + "] " + strings.Join(texts, " "),
+		Attributes: map[string]string{"id": number, "kind": kind},
+	})
+	return "[" + number + "]"
+}
@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+func buildZip(t *testing.T, files map[string]string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+const wordNS = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+
+func TestDOCXParser(t *testing.T) {
+	archive := buildZip(t, map[string]string{
+		"word/document.xml": `<w:document ` + wordNS + `><w:body>
+<w:p><w:pPr><w:pStyle w:val="Titre1"/></w:pPr><w:r><w:t>Rapport annuel</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Le chiffre d'affaires </w:t></w:r><w:r><w:t>progresse.</w:t></w:r><w:r><w:footnoteReference w:id="2"/></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Premier point</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Détail</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Second point</w:t></w:r></w:p>
+<w:tbl><w:tr><w:trPr><w:tblHeader/></w:trPr><w:tc><w:p><w:r><w:t>Année</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Total</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>2023</w:t></w:r></w:p></w:tc><w:tc><w:p><w:hyperlink r:id="rId5"><w:r><w:t>42</w:t></w:r></w:hyperlink></w:p></w:tc></w:tr></w:tbl>
+</w:body></w:document>`,
+		"word/styles.xml": `<w:styles ` + wordNS + `><w:style w:styleId="Titre1"><w:name w:val="heading 1"/></w:style></w:styles>`,
+		"word/numbering.xml": `<w:numbering ` + wordNS + `><w:abstractNum w:abstractNumId="7"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/></w:lvl><w:lvl w:ilvl="1"><w:numFmt w:val="bullet"/></w:lvl></w:abstractNum>
+<w:num w:numId="1"><w:abstractNumId w:val="7"/></w:num></w:numbering>`,
+		"word/footnotes.xml":           `<w:footnotes ` + wordNS + `><w:footnote w:type="separator" w:id="0"><w:p/></w:footnote><w:footnote w:id="2"><w:p><w:r><w:t>Source : bilan 2023.</w:t></w:r></w:p></w:footnote></w:footnotes>`,
+		"word/_rels/document.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId5" Target="https://example.com/bilan" TargetMode="External"/></Relationships>`,
+		"docProps/core.xml": `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/">
+<dc:title>Rapport 2023</dc:title><dc:creator>Marie Curie</dc:creator><dcterms:created>2024-01-15T10:00:00Z</dcterms:created></cp:coreProperties>`,
+	})
+
+	doc, err := NewDOCXParser().Parse(archive)
+	if err != nil {
+		t.Fatalf("Failed to parse DOCX: %v", err)
+	}
+
+	if doc.Metadata["title"] != "Rapport 2023" || doc.Metadata["author"] != "Marie Curie" || doc.Metadata["created"] != "2024-01-15T10:00:00Z" {
+		t.Errorf("Unexpected metadata: %v", doc.Metadata)
+	}
+	if len(doc.Structure) != 2 || doc.Structure[0].Type != "section" || doc.Structure[1].Type != "footnotes" {
+		t.Fatalf("Expected a section followed by footnotes, got %+v", doc.Structure)
+	}
+
+	section := doc.Structure[0]
+	if section.Attributes["title"] != "Rapport annuel" || len(section.Children) != 4 {
+		t.Fatalf("Unexpected section: %+v", section)
+	}
+	if section.Children[1].Content != "Le chiffre d'affaires progresse.[1]" {
+		t.Errorf("Unexpected paragraph: %q", section.Children[1].Content)
+	}
+
+	list := section.Children[2]
+	if list.Type != "list" || list.Attributes["ordered"] != "true" || len(list.Children) != 2 {
+		t.Fatalf("Unexpected list: %+v", list)
+	}
+	if nested := list.Children[0].Children[1]; nested.Type != "list" || nested.Attributes["ordered"] != "false" || nested.Content != "Détail" {
+		t.Errorf("Expected nested bullet list, got %+v", nested)
+	}
+
+	table := section.Children[3]
+	if len(table.Children) != 2 || table.Children[0].Attributes["header"] != "true" || table.Children[1].Content != "2023 | 42" {
+		t.Errorf("Unexpected table: %+v", table)
+	}
+	if link := table.Children[1].Children[1].Children[0].Children[0]; link.Attributes["href"] != "https://example.com/bilan" {
+		t.Errorf("Unexpected link: %+v", link)
+	}
+	if doc.Structure[1].Children[0].Content != "[1] Source : bilan 2023." {
+		t.Errorf("Unexpected footnote: %+v", doc.Structure[1].Children[0])
+	}
+}
+
+const odtNS = `xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:xlink="http://www.w3.org/1999/xlink"`
+
+func TestODTParser(t *testing.T) {
+	archive := buildZip(t, map[string]string{
+		"content.xml": `<office:document-content ` + odtNS + `>
+<office:automatic-styles><text:list-style style:name="L1" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0"><text:list-level-style-number text:level="1"/></text:list-style></office:automatic-styles>
+<office:body><office:text>
+<text:h text:outline-level="1">Introduction</text:h>
+<text:p>Voir <text:a xlink:href="https://example.org">le site</text:a><text:note text:note-class="footnote"><text:note-citation>1</text:note-citation><text:note-body><text:p>Consulté en 2024.</text:p></text:note-body></text:note>.</text:p>
+<text:h text:outline-level="2">Étapes</text:h>
+<text:list text:style-name="L1"><text:list-item><text:p>Préparer</text:p></text:list-item><text:list-item><text:p>Cuire</text:p></text:list-item></text:list>
+<table:table table:name="Ingrédients"><table:table-header-rows><table:table-row><table:table-cell><text:p>Nom</text:p></table:table-cell></table:table-row></table:table-header-rows>
+<table:table-row><table:table-cell><text:p>Farine</text:p></table:table-cell></table:table-row></table:table>
+</office:text></office:body></office:document-content>`,
+		"meta.xml": `<office:document-meta ` + odtNS + ` xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0" xmlns:dc="http://purl.org/dc/elements/1.1/"><office:meta>
+<dc:title>Recette</dc:title><meta:initial-creator>Paul Bocuse</meta:initial-creator><meta:creation-date>2024-03-01T08:00:00</meta:creation-date><meta:keyword>cuisine</meta:keyword><meta:keyword>pain</meta:keyword>
+</office:meta></office:document-meta>`,
+	})
+
+	doc, err := NewODTParser().Parse(archive)
+	if err != nil {
+		t.Fatalf("Failed to parse ODT: %v", err)
+	}
+
+	if doc.Metadata["title"] != "Recette" || doc.Metadata["author"] != "Paul Bocuse" || doc.Metadata["created"] != "2024-03-01T08:00:00" || doc.Metadata["keywords"] != "cuisine, pain" {
+		t.Errorf("Unexpected metadata: %v", doc.Metadata)
+	}
+	if len(doc.Structure) != 2 || doc.Structure[1].Type != "footnotes" {
+		t.Fatalf("Expected a section followed by footnotes, got %+v", doc.Structure)
+	}
+
+	intro := doc.Structure[0]
+	paragraph := intro.Children[1]
+	if paragraph.Content != "Voir le site[1]." || paragraph.Children[0].Attributes["href"] != "https://example.org" {
+		t.Errorf("Unexpected paragraph: %+v", paragraph)
+	}
+
+	steps := intro.Children[2]
+	if steps.Type != "section" || steps.Attributes["level"] != "2" || len(steps.Children) != 3 {
+		t.Fatalf("Unexpected subsection: %+v", steps)
+	}
+	if list := steps.Children[1]; list.Attributes["ordered"] != "true" || len(list.Children) != 2 {
+		t.Errorf("Unexpected list: %+v", list)
+	}
+	if table := steps.Children[2]; table.Attributes["name"] != "Ingrédients" || table.Children[0].Attributes["header"] != "true" || table.Children[1].Content != "Farine" {
+		t.Errorf("Unexpected table: %+v", table)
+	}
+	if doc.Structure[1].Children[0].Content != "[1] Consulté en 2024." {
+		t.Errorf("Unexpected footnote: %+v", doc.Structure[1].Children[0])
+	}
+}
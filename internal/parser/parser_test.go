@@ -27,12 +27,170 @@ func TestMarkdownParser(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to parse markdown: %v", err)
 	}
+	if len(doc.Structure) != 1 || doc.Structure[0].Type != "section" {
+		t.Fatalf("Expected a single section, got %+v", doc.Structure)
+	}
+	section := doc.Structure[0]
+	if len(section.Children) != 2 || section.Children[0].Type != "heading" || section.Children[1].Type != "paragraph" {
+		t.Errorf("Unexpected section children: %+v", section.Children)
+	}
+	if section.Attributes["title"] != "Heading" || section.Attributes["level"] != "1" {
+		t.Errorf("Unexpected section attributes: %v", section.Attributes)
+	}
+}
+
+func TestMarkdownParserTree(t *testing.T) {
+	input := `# Titre
+
+## Partie
+
+- un [lien](https://example.com)
+- deux
+
+> citation
+
+` + "```go\nfmt.Println()\n```" + `
+
+| A | B |
+|---|---|
+| 1 | 2 |
+
+# Autre
+`
+	doc, err := NewMarkdownParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse markdown: %v", err)
+	}
 	if len(doc.Structure) != 2 {
-		t.Errorf("Expected 2 elements (heading and paragraph), got %d", len(doc.Structure))
+		t.Fatalf("Expected 2 top-level sections, got %d", len(doc.Structure))
+	}
+	part := doc.Structure[0].Children[1]
+	if part.Type != "section" || part.Attributes["level"] != "2" {
+		t.Fatalf("Expected nested level 2 section, got %+v", part)
+	}
+
+	types := []string{"heading", "list", "blockquote", "code", "table"}
+	if len(part.Children) != len(types) {
+		t.Fatalf("Expected %d children, got %+v", len(types), part.Children)
+	}
+	for i, typ := range types {
+		if part.Children[i].Type != typ {
+			t.Errorf("Child %d: expected %s, got %s", i, typ, part.Children[i].Type)
+		}
 	}
-	if doc.Structure[0].Type != "heading" || doc.Structure[1].Type != "paragraph" {
-		t.Errorf("Unexpected structure types")
+
+	item := part.Children[1].Children[0]
+	link := item.Children[0].Children[0]
+	if item.Type != "listitem" || link.Type != "link" || link.Attributes["href"] != "https://example.com" {
+		t.Errorf("Unexpected list item: %+v", item)
+	}
+	if part.Children[3].Attributes["language"] != "go" || part.Children[3].Content != "fmt.Println()" {
+		t.Errorf("Unexpected code block: %+v", part.Children[3])
+	}
+	table := part.Children[4]
+	if len(table.Children) != 2 || table.Children[0].Attributes["header"] != "true" || table.Children[1].Children[1].Content != "2" {
+		t.Errorf("Unexpected table: %+v", table)
 	}
 }
 
-// Add similar tests for PDF and HTML parsers
+func TestHTMLParserTree(t *testing.T) {
+	input := `<html><body>
+<h1>Titre</h1>
+<div><p>Texte avec <a href="/page">un lien</a>.</p><img src="a.png" alt="Schéma"></div>
+<h2>Liste</h2>
+<ol start="3"><li>Trois</li><li>Quatre<ul><li>Sous-point</li></ul></li></ol>
+<table><tr><th>Nom</th><th>Âge</th></tr><tr><td>Jean</td><td>30</td></tr></table>
+</body></html>`
+	doc, err := NewHTMLParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	if len(doc.Structure) != 1 || doc.Structure[0].Type != "section" {
+		t.Fatalf("Expected a single section, got %+v", doc.Structure)
+	}
+
+	section := doc.Structure[0]
+	paragraph := section.Children[1]
+	if paragraph.Content != "Texte avec un lien." || paragraph.Children[0].Attributes["href"] != "/page" {
+		t.Errorf("Unexpected paragraph: %+v", paragraph)
+	}
+	image := section.Children[2].Children[0]
+	if image.Type != "image" || image.Attributes["alt"] != "Schéma" {
+		t.Errorf("Unexpected image: %+v", section.Children[2])
+	}
+
+	sub := section.Children[3]
+	list := sub.Children[1]
+	if list.Type != "list" || list.Attributes["start"] != "3" || len(list.Children) != 2 {
+		t.Fatalf("Unexpected list: %+v", list)
+	}
+	if nested := list.Children[1].Children[1]; nested.Type != "list" || nested.Content != "Sous-point" {
+		t.Errorf("Expected nested list in second item, got %+v", nested)
+	}
+	table := sub.Children[2]
+	if table.Type != "table" || len(table.Children) != 2 || table.Children[1].Content != "Jean | 30" {
+		t.Errorf("Unexpected table: %+v", table)
+	}
+	if !strings.Contains(doc.Content, "Texte avec un lien.\nSchéma") {
+		t.Errorf("Unexpected content: %q", doc.Content)
+	}
+}
+
+func TestHTMLParserStructuredData(t *testing.T) {
+	input := `<html lang="fr"><head>
+<title>Recette</title>
+<meta name="description" content="Une tarte simple">
+<meta property="og:image" content="https://example.com/tarte.jpg">
+<meta name="twitter:creator" content="@chef">
+<link rel="canonical" href="https://example.com/tarte">
+<script type="application/ld+json">{"@context": "https://schema.org", "@type": "Recipe", "name": "Tarte aux pommes"}</script>
+<style>body { color: red }</style>
+</head><body>
+<script>var tracking = true;</script>
+<div itemscope itemtype="https://schema.org/Person"><span itemprop="name">Marie</span>
+<div itemprop="address" itemscope itemtype="https://schema.org/PostalAddress"><span itemprop="addressLocality">Lyon</span></div>
+<a itemprop="sameAs" href="https://a.example">a</a><a itemprop="sameAs" href="https://b.example">b</a></div>
+<p vocab="https://schema.org/" typeof="Event"><span property="name">Fête</span> le <time property="startDate" datetime="2024-06-21">21 juin</time></p>
+<noscript>Activez JavaScript</noscript>
+</body></html>`
+	doc, err := NewHTMLParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	if strings.Contains(doc.Content, "tracking") || strings.Contains(doc.Content, "color") || strings.Contains(doc.Content, "JavaScript") {
+		t.Errorf("Hidden content should be skipped: %q", doc.Content)
+	}
+	expected := map[string]string{
+		"title":           "Recette",
+		"description":     "Une tarte simple",
+		"language":        "fr",
+		"url":             "https://example.com/tarte",
+		"image":           "https://example.com/tarte.jpg",
+		"author":          "@chef",
+		"twitter:creator": "@chef",
+	}
+	for key, value := range expected {
+		if doc.Metadata[key] != value {
+			t.Errorf("Metadata %s: expected %q, got %q", key, value, doc.Metadata[key])
+		}
+	}
+
+	if len(doc.StructuredData) != 3 {
+		t.Fatalf("Expected 3 structured items, got %+v", doc.StructuredData)
+	}
+	recipe, person, event := doc.StructuredData[0], doc.StructuredData[1], doc.StructuredData[2]
+	if recipe["@type"] != "Recipe" || recipe["@context"] != nil {
+		t.Errorf("Unexpected JSON-LD item: %v", recipe)
+	}
+	address, _ := person["address"].(map[string]interface{})
+	if person["@type"] != "Person" || person["name"] != "Marie" || address["addressLocality"] != "Lyon" {
+		t.Errorf("Unexpected microdata item: %v", person)
+	}
+	if sameAs, ok := person["sameAs"].([]interface{}); !ok || len(sameAs) != 2 || sameAs[1] != "https://b.example" {
+		t.Errorf("Expected repeated property as a list, got %v", person["sameAs"])
+	}
+	if event["@type"] != "Event" || event["name"] != "Fête" || event["startDate"] != "2024-06-21" {
+		t.Errorf("Unexpected RDFa item: %v", event)
+	}
+}
@@ -1,8 +1,16 @@
 package parser
 
 import (
+	"bytes"
+	"fmt"
 	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/ledongthuc/pdf"
 )
@@ -13,46 +21,540 @@ func NewPDFParser() *PDFParser {
 	return &PDFParser{}
 }
 
+// Parse extrait le texte de chaque page dans l'ordre de lecture (colonne par
+// colonne), retire les en-têtes, pieds de page et numéros de page répétés, et
+// place les entrées du sommaire (signets) comme titres. Le dictionnaire Info
+// renseigne les métadonnées.
 func (p *PDFParser) Parse(r io.Reader) (*Document, error) {
 	content, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
 
-	// Créer un io.ReaderAt à partir du contenu
-	contentReader := strings.NewReader(string(content))
-	size := int64(len(content))
-
-	reader, err := pdf.NewReader(contentReader, size)
+	reader, err := pdf.NewReader(bytes.NewReader(content), int64(len(content)))
 	if err != nil {
 		return nil, err
 	}
 
-	var contentBuilder strings.Builder
-	var structure []DocumentElement
-
-	numPages := reader.NumPage()
-
-	for pageIndex := 1; pageIndex <= numPages; pageIndex++ {
+	var pages []pdfPage
+	for pageIndex := 1; pageIndex <= reader.NumPage(); pageIndex++ {
 		page := reader.Page(pageIndex)
 		if page.V.IsNull() {
 			continue
 		}
-
-		text, err := page.GetPlainText(nil)
+		lines, err := pdfPageLines(page)
 		if err != nil {
 			return nil, err
 		}
-		contentBuilder.WriteString(text)
-		structure = append(structure, DocumentElement{
-			Type:    "page",
-			Content: text,
-		})
+		pages = append(pages, pdfPage{number: pageIndex, lines: lines})
+	}
+
+	stripPageFurniture(pages)
+	markOutlineHeadings(pages, pdfOutlineHeadings(reader.Outline(), 1))
+
+	var structure []DocumentElement
+	var texts []string
+	for _, page := range pages {
+		el := newContainer("page", map[string]string{"number": strconv.Itoa(page.number)}, pdfBlocks(page.lines))
+		structure = append(structure, el)
+		if el.Content != "" {
+			texts = append(texts, el.Content)
+		}
 	}
 
+	metadata := pdfMetadata(reader.Trailer().Key("Info"))
+	metadata["pages"] = strconv.Itoa(reader.NumPage())
+
 	return &Document{
-		Content:   contentBuilder.String(),
-		Metadata:  make(map[string]string),
+		// Les pages sont séparées par une ligne vide
+		Content:   strings.Join(texts, "\n\n"),
+		Metadata:  metadata,
 		Structure: structure,
 	}, nil
 }
+
+type pdfPage struct {
+	number int
+	lines  []pdfLine
+}
+
+// pdfLine est une ligne de texte dans l'ordre de lecture. breakBefore indique
+// un changement de paragraphe ; level est non nul pour les titres du sommaire.
+type pdfLine struct {
+	text        string
+	breakBefore bool
+	level       int
+}
+
+// pdfChunk est un fragment de texte positionné sur la page
+type pdfChunk struct {
+	x, y float64
+	s    string
+}
+
+// pdfPageLines retourne les lignes d'une page ; si les positions du texte ne
+// peuvent être lues, le texte brut de la page est découpé en paragraphes
+func pdfPageLines(page pdf.Page) ([]pdfLine, error) {
+	if chunks, err := pdfPageChunks(page); err == nil {
+		return pdfLayout(chunks), nil
+	}
+
+	text, err := page.GetPlainText(nil)
+	if err != nil {
+		return nil, err
+	}
+	var lines []pdfLine
+	for _, paragraph := range textParagraphs(text) {
+		for i, line := range strings.Split(paragraph.Content, "\n") {
+			lines = append(lines, pdfLine{text: line, breakBefore: i == 0})
+		}
+	}
+	return lines, nil
+}
+
+// pdfPageChunks regroupe les glyphes positionnés de la page en fragments : un
+// glyphe qui suit le précédent sur la même ligne le prolonge, un espacement
+// d'au moins un quart de corps y insère une espace, et un écart de plus de deux
+// corps ouvre un nouveau fragment
+func pdfPageChunks(page pdf.Page) (chunks []pdfChunk, err error) {
+	// La bibliothèque signale les flux de contenu invalides par un panic
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid page content: %v", r)
+		}
+	}()
+
+	var current *pdfChunk
+	var end, size float64
+	for _, glyph := range page.Content().Text {
+		gap := glyph.X - end
+		sameLine := current != nil && math.Abs(glyph.Y-current.y) < 0.5 && gap > -size/2
+		switch {
+		case sameLine && gap <= 2*size:
+			if gap >= size/4 && !strings.HasSuffix(current.s, " ") {
+				current.s += " "
+			}
+			current.s += glyph.S
+		default:
+			chunks = append(chunks, pdfChunk{x: glyph.X, y: glyph.Y, s: glyph.S})
+			current = &chunks[len(chunks)-1]
+		}
+		end = glyph.X + glyph.W
+		size = glyph.FontSize
+	}
+	return chunks, nil
+}
+
+const (
+	pdfColumnTolerance = 5.0   // écart admis entre le début d'un fragment et celui d'une colonne
+	pdfMinColumnGap    = 150.0 // distance minimale entre les débuts de deux colonnes
+)
+
+type pdfRow struct {
+	y      float64
+	chunks []pdfChunk
+}
+
+// pdfLayout reconstitue l'ordre de lecture d'une page : les fragments sont
+// regroupés en lignes, puis les lignes d'une bande multi-colonnes sont lues
+// colonne par colonne. Une ligne qui déborde sur plusieurs colonnes sans
+// commencer au début de l'une d'elles (titre pleine largeur) interrompt la bande.
+func pdfLayout(chunks []pdfChunk) []pdfLine {
+	byY := make(map[int64]*pdfRow)
+	var rows []*pdfRow
+	for _, c := range chunks {
+		if strings.TrimSpace(c.s) == "" {
+			continue
+		}
+		key := int64(math.Round(c.y))
+		row, ok := byY[key]
+		if !ok {
+			row = &pdfRow{y: float64(key)}
+			byY[key] = row
+			rows = append(rows, row)
+		}
+		row.chunks = append(row.chunks, c)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].y > rows[j].y })
+	for _, row := range rows {
+		sort.SliceStable(row.chunks, func(i, j int) bool { return row.chunks[i].x < row.chunks[j].x })
+	}
+
+	starts := pdfColumnStarts(rows)
+	column := func(x float64) int {
+		col := 0
+		for i, start := range starts {
+			if x >= start-pdfColumnTolerance {
+				col = i
+			}
+		}
+		return col
+	}
+
+	type positioned struct {
+		text string
+		y    float64
+	}
+	var ordered [][]positioned // suites de lignes lues d'un trait (une colonne ou une ligne pleine largeur)
+	var band []*pdfRow
+
+	flushBand := func() {
+		for col := range starts {
+			var run []positioned
+			for _, row := range band {
+				var sb strings.Builder
+				for _, c := range row.chunks {
+					if column(c.x) == col {
+						sb.WriteString(c.s + " ")
+					}
+				}
+				if text := collapseWhitespace(sb.String()); text != "" {
+					run = append(run, positioned{text, row.y})
+				}
+			}
+			if len(run) > 0 {
+				ordered = append(ordered, run)
+			}
+		}
+		band = nil
+	}
+
+	for i, row := range rows {
+		// Un fragment isolé en haut ou en bas de page (en-tête, numéro de page)
+		// reste à sa place dans l'ordre de lecture
+		edge := (i == 0 || i == len(rows)-1) && len(row.chunks) == 1
+		if len(starts) > 1 && (edge || pdfSpansColumns(row, starts, column)) {
+			flushBand()
+			var sb strings.Builder
+			for _, c := range row.chunks {
+				sb.WriteString(c.s + " ")
+			}
+			ordered = append(ordered, []positioned{{collapseWhitespace(sb.String()), row.y}})
+			continue
+		}
+		band = append(band, row)
+	}
+	flushBand()
+
+	// Un interligne nettement plus grand que l'interligne habituel (premier quartile
+	// des écarts entre lignes) sépare deux paragraphes
+	var gaps []float64
+	for _, run := range ordered {
+		for i := 1; i < len(run); i++ {
+			if gap := run[i-1].y - run[i].y; gap > 0 {
+				gaps = append(gaps, gap)
+			}
+		}
+	}
+	threshold := math.Inf(1)
+	if len(gaps) > 0 {
+		sort.Float64s(gaps)
+		threshold = gaps[len(gaps)/4] * 1.6
+	}
+
+	var lines []pdfLine
+	for _, run := range ordered {
+		for i, line := range run {
+			breakBefore := i == 0 || run[i-1].y-line.y > threshold
+			lines = append(lines, pdfLine{text: line.text, breakBefore: breakBefore})
+		}
+	}
+	return lines
+}
+
+// pdfColumnStarts retourne l'abscisse de début de chaque colonne. Une colonne
+// supplémentaire est retenue lorsqu'un nombre suffisant de lignes portent à la
+// fois du texte à sa gauche et un fragment commençant à cette abscisse.
+func pdfColumnStarts(rows []*pdfRow) []float64 {
+	if len(rows) == 0 {
+		return nil
+	}
+	left := math.Inf(1)
+	for _, row := range rows {
+		left = math.Min(left, row.chunks[0].x)
+	}
+
+	type candidate struct {
+		x   float64
+		row int
+	}
+	var candidates []candidate
+	for i, row := range rows {
+		for _, c := range row.chunks[1:] {
+			if c.x-row.chunks[0].x >= pdfMinColumnGap {
+				candidates = append(candidates, candidate{c.x, i})
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].x < candidates[j].x })
+
+	minRows := len(rows) / 4
+	if minRows < 3 {
+		minRows = 3
+	}
+	starts := []float64{left}
+	// Les débuts de fragments proches sont regroupés ; un groupe présent sur
+	// assez de lignes distinctes marque une colonne
+	for i := 0; i < len(candidates); {
+		j := i
+		seen := make(map[int]bool)
+		for j < len(candidates) && (j == i || candidates[j].x-candidates[j-1].x <= pdfColumnTolerance) {
+			seen[candidates[j].row] = true
+			j++
+		}
+		if x := candidates[i].x; len(seen) >= minRows && x-starts[len(starts)-1] >= pdfMinColumnGap {
+			starts = append(starts, x)
+		}
+		i = j
+	}
+	return starts
+}
+
+// pdfSpansColumns indique si une ligne déborde d'une colonne sur la suivante
+// sans qu'aucun de ses fragments ne commence au début de celle-ci
+func pdfSpansColumns(row *pdfRow, starts []float64, column func(float64) int) bool {
+	for col := 1; col < len(starts); col++ {
+		inColumn, atStart := false, false
+		for _, c := range row.chunks {
+			if column(c.x) == col {
+				inColumn = true
+				atStart = atStart || math.Abs(c.x-starts[col]) <= 2*pdfColumnTolerance
+			}
+		}
+		if inColumn && !atStart {
+			return true
+		}
+	}
+	return false
+}
+
+// pageNumberPattern reconnaît les lignes ne contenant qu'un numéro de page
+var pageNumberPattern = regexp.MustCompile(`(?i)^[-–—\s]*(page\s*)?\d+(\s*(/|sur|of|de)\s*\d+)?[-–—\s]*$`)
+
+// pdfFurnitureLines est le nombre de lignes examinées en haut et en bas de page
+const pdfFurnitureLines = 2
+
+// stripPageFurniture retire les en-têtes et pieds de page : lignes répétées en
+// haut ou en bas d'au moins la moitié des pages (aux chiffres près) et numéros
+// de page isolés
+func stripPageFurniture(pages []pdfPage) {
+	key := func(text string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return '#'
+			}
+			return unicode.ToLower(r)
+		}, text)
+	}
+
+	// Positions examinées : 0 et 1 depuis le haut, -1 et -2 depuis le bas
+	positions := []int{0, 1, -1, -2}
+	index := func(lines []pdfLine, position int) int {
+		if position < 0 {
+			return len(lines) + position
+		}
+		return position
+	}
+
+	counts := make(map[int]map[string]int)
+	for _, position := range positions {
+		counts[position] = make(map[string]int)
+		for _, page := range pages {
+			if i := index(page.lines, position); i >= 0 && i < len(page.lines) && len(page.lines) > pdfFurnitureLines {
+				counts[position][key(page.lines[i].text)]++
+			}
+		}
+	}
+
+	minPages := (len(pages) + 1) / 2
+	if minPages < 2 {
+		minPages = 2
+	}
+	for p := range pages {
+		lines := pages[p].lines
+		remove := make(map[int]bool)
+		for _, position := range positions {
+			i := index(lines, position)
+			if i < 0 || i >= len(lines) {
+				continue
+			}
+			repeated := len(lines) > pdfFurnitureLines && counts[position][key(lines[i].text)] >= minPages
+			edge := i == 0 || i == len(lines)-1
+			if repeated || (edge && pageNumberPattern.MatchString(lines[i].text)) {
+				remove[i] = true
+			}
+		}
+
+		var kept []pdfLine
+		for i, line := range lines {
+			if remove[i] {
+				continue
+			}
+			if len(kept) == 0 {
+				line.breakBefore = true
+			}
+			kept = append(kept, line)
+		}
+		pages[p].lines = kept
+	}
+}
+
+type pdfHeading struct {
+	title string
+	level int
+}
+
+// pdfOutlineHeadings aplatit le sommaire du document dans l'ordre de lecture
+func pdfOutlineHeadings(outline pdf.Outline, level int) []pdfHeading {
+	var headings []pdfHeading
+	for _, child := range outline.Child {
+		if title := collapseWhitespace(child.Title); title != "" {
+			headings = append(headings, pdfHeading{title: title, level: level})
+		}
+		headings = append(headings, pdfOutlineHeadings(child, level+1)...)
+	}
+	return headings
+}
+
+// markOutlineHeadings repère dans le texte, dans l'ordre, la ligne de chaque
+// entrée du sommaire et la marque comme titre. Les signets ne donnant pas leur
+// position dans la page, une entrée introuvable est ignorée.
+func markOutlineHeadings(pages []pdfPage, headings []pdfHeading) {
+	normalize := func(s string) string {
+		return strings.ToLower(collapseWhitespace(s))
+	}
+	page, line := 0, 0
+	for _, heading := range headings {
+		title := normalize(heading.title)
+		found := false
+		for p := page; p < len(pages) && !found; p++ {
+			start := 0
+			if p == page {
+				start = line
+			}
+			for l := start; l < len(pages[p].lines); l++ {
+				if text := normalize(pages[p].lines[l].text); text == title || strings.HasPrefix(text, title+" ") {
+					pages[p].lines[l].level = heading.level
+					page, line = p, l+1
+					found = true
+					break
+				}
+			}
+		}
+	}
+}
+
+// pdfBlocks regroupe les lignes d'une page en titres et paragraphes ; un mot
+// coupé en fin de ligne est recollé
+func pdfBlocks(lines []pdfLine) []DocumentElement {
+	var elements []DocumentElement
+	var paragraph string
+	flush := func() {
+		if paragraph != "" {
+			elements = append(elements, DocumentElement{Type: "paragraph", Content: paragraph})
+		}
+		paragraph = ""
+	}
+
+	for _, line := range lines {
+		if line.level > 0 {
+			flush()
+			elements = append(elements, DocumentElement{
+				Type:       "heading",
+				Content:    line.text,
+				Attributes: map[string]string{"level": strconv.Itoa(line.level)},
+			})
+			continue
+		}
+		if line.breakBefore {
+			flush()
+		}
+		switch {
+		case paragraph == "":
+			paragraph = line.text
+		case strings.HasSuffix(paragraph, "-") && startsLower(line.text):
+			paragraph = strings.TrimSuffix(paragraph, "-") + line.text
+		default:
+			paragraph += " " + line.text
+		}
+	}
+	flush()
+	return elements
+}
+
+func startsLower(s string) bool {
+	for _, r := range s {
+		return unicode.IsLower(r)
+	}
+	return false
+}
+
+// pdfInfoKeys associe les entrées du dictionnaire Info aux clés de métadonnées
+var pdfInfoKeys = map[string]string{
+	"Title":        "title",
+	"Author":       "author",
+	"Subject":      "subject",
+	"Keywords":     "keywords",
+	"Creator":      "creator",
+	"Producer":     "producer",
+	"CreationDate": "created",
+	"ModDate":      "modified",
+}
+
+// pdfMetadata lit le dictionnaire Info ; les dates sont converties en RFC 3339
+func pdfMetadata(info pdf.Value) map[string]string {
+	metadata := make(map[string]string)
+	if info.IsNull() {
+		return metadata
+	}
+	for name, key := range pdfInfoKeys {
+		value := strings.TrimSpace(info.Key(name).Text())
+		if value == "" {
+			continue
+		}
+		if key == "created" || key == "modified" {
+			if t, err := parsePDFDate(value); err == nil {
+				value = t.Format(time.RFC3339)
+			}
+		}
+		metadata[key] = value
+	}
+	return metadata
+}
+
+// parsePDFDate lit une date PDF de la forme D:YYYYMMDDHHmmSSOHH'mm', dont
+// toutes les parties après l'année sont facultatives
+func parsePDFDate(s string) (time.Time, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "D:")
+	digits := len(s) - len(strings.TrimLeftFunc(s, unicode.IsDigit))
+	layouts := map[int]string{4: "2006", 6: "200601", 8: "20060102", 10: "2006010215", 12: "200601021504", 14: "20060102150405"}
+	layout, ok := layouts[digits]
+	if !ok {
+		return time.Time{}, &time.ParseError{Layout: "D:YYYYMMDDHHmmSS", Value: s}
+	}
+
+	location := time.UTC
+	if zone := strings.TrimRight(s[digits:], "'"); zone != "" && zone != "Z" {
+		sign := 1
+		switch zone[0] {
+		case '-':
+			sign = -1
+		case '+':
+		default:
+			return time.Time{}, &time.ParseError{Layout: "OHH'mm'", Value: zone}
+		}
+		parts := strings.SplitN(zone[1:], "'", 2)
+		hours, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return time.Time{}, err
+		}
+		minutes := 0
+		if len(parts) == 2 && parts[1] != "" {
+			if minutes, err = strconv.Atoi(parts[1]); err != nil {
+				return time.Time{}, err
+			}
+		}
+		location = time.FixedZone("", sign*(hours*3600+minutes*60))
+	}
+	return time.ParseInLocation(layout, s[:digits], location)
+}
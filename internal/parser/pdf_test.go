@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// pdfText est une ligne de texte placée sur une page de test
+type pdfText struct {
+	x, y float64
+	s    string
+}
+
+// buildPDF assemble un PDF minimal : une police standard, une page par liste de
+// textes, le dictionnaire Info et le sommaire fournis sous forme d'objets PDF.
+// Dans les entrées du sommaire, PARENT désigne la racine et #n la n-ième entrée.
+func buildPDF(t *testing.T, info string, outline []string, pages [][]pdfText) *bytes.Reader {
+	t.Helper()
+	var objects []string
+	add := func(body string) int {
+		objects = append(objects, body)
+		return len(objects)
+	}
+
+	catalog := add("")
+	pagesObj := add("")
+	font := add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+	var kids []string
+	for _, texts := range pages {
+		var stream strings.Builder
+		for _, text := range texts {
+			fmt.Fprintf(&stream, "BT /F1 10 Tf %g %g Td (%s) Tj ET\n", text.x, text.y, text.s)
+		}
+		content := add(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", stream.Len(), stream.String()))
+		page := add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 612 842] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>", pagesObj, font, content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+	objects[pagesObj-1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))
+
+	outlines := add("")
+	first := len(objects) + 1
+	for _, entry := range outline {
+		entry = strings.ReplaceAll(entry, "PARENT", fmt.Sprintf("%d 0 R", outlines))
+		for i := len(outline); i >= 1; i-- {
+			entry = strings.ReplaceAll(entry, fmt.Sprintf("#%d", i), fmt.Sprintf("%d 0 R", first+i-1))
+		}
+		add(entry)
+	}
+	objects[outlines-1] = fmt.Sprintf("<< /Type /Outlines /First %d 0 R >>", first)
+	objects[catalog-1] = fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R /Outlines %d 0 R >>", pagesObj, outlines)
+	infoObj := add(info)
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, catalog, infoObj, xref)
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestPDFParser(t *testing.T) {
+	header := pdfText{72, 800, "Rapport annuel 2024"}
+	pages := [][]pdfText{
+		{
+			header,
+			{72, 760, "Introduction"},
+			{72, 700, "Gauche un"}, {320, 700, "Droite un"},
+			{72, 688, "gauche deux"}, {320, 688, "droite deux"},
+			{72, 676, "gauche trois"}, {320, 676, "droite trois"},
+			{300, 30, "1"},
+		},
+		{
+			header,
+			{72, 760, "Methode"},
+			{72, 740, "La conver-"},
+			{72, 728, "sion du texte."},
+			{72, 690, "Second paragraphe."},
+			{300, 30, "Page 2 sur 3"},
+		},
+		{
+			header,
+			{72, 760, "Conclusion du rapport."},
+			{72, 748, "Fin."},
+			{300, 30, "Page 3 sur 3"},
+		},
+	}
+	outline := []string{
+		"<< /Title (Introduction) /Parent PARENT /First #2 /Next #3 >>",
+		"<< /Title (Methode) /Parent #1 >>",
+		"<< /Title (Absent du texte) /Parent PARENT >>",
+	}
+	info := "<< /Title (Rapport) /Author (Marie Curie) /CreationDate (D:20240315103000+01'00') >>"
+
+	doc, err := NewPDFParser().Parse(buildPDF(t, info, outline, pages))
+	if err != nil {
+		t.Fatalf("Failed to parse PDF: %v", err)
+	}
+
+	if doc.Metadata["title"] != "Rapport" || doc.Metadata["author"] != "Marie Curie" || doc.Metadata["pages"] != "3" {
+		t.Errorf("Unexpected metadata: %v", doc.Metadata)
+	}
+	if doc.Metadata["created"] != "2024-03-15T10:30:00+01:00" {
+		t.Errorf("Unexpected creation date: %q", doc.Metadata["created"])
+	}
+	if strings.Contains(doc.Content, "Rapport annuel") || strings.Contains(doc.Content, "Page 2") {
+		t.Errorf("Headers and footers should be stripped: %q", doc.Content)
+	}
+	if !strings.Contains(doc.Content, "droite trois\n\nMethode") {
+		t.Errorf("Pages should be separated by a blank line: %q", doc.Content)
+	}
+
+	first := doc.Structure[0]
+	if len(first.Children) != 3 {
+		t.Fatalf("Expected heading and two columns on page 1, got %+v", first.Children)
+	}
+	if first.Children[0].Type != "heading" || first.Children[0].Attributes["level"] != "1" {
+		t.Errorf("Expected outline entry as heading, got %+v", first.Children[0])
+	}
+	if first.Children[1].Content != "Gauche un gauche deux gauche trois" || first.Children[2].Content != "Droite un droite deux droite trois" {
+		t.Errorf("Columns should be read one after the other: %+v", first.Children[1:])
+	}
+
+	second := doc.Structure[1]
+	if second.Children[0].Attributes["level"] != "2" || second.Children[1].Content != "La conversion du texte." || second.Children[2].Content != "Second paragraphe." {
+		t.Errorf("Unexpected page 2: %+v", second.Children)
+	}
+}
+
+func TestParsePDFDate(t *testing.T) {
+	for input, expected := range map[string]string{
+		"D:2024":                  "2024-01-01T00:00:00Z",
+		"D:20240315103000Z":       "2024-03-15T10:30:00Z",
+		"D:20240315103000-05'30'": "2024-03-15T10:30:00-05:30",
+		"20240315":                "2024-03-15T00:00:00Z",
+	} {
+		date, err := parsePDFDate(input)
+		if err != nil {
+			t.Errorf("%s: %v", input, err)
+			continue
+		}
+		if got := date.Format("2006-01-02T15:04:05Z07:00"); got != expected {
+			t.Errorf("%s: expected %s, got %s", input, expected, got)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// htmlStructuredData extrait les données structurées déclarées par une page :
+// blocs JSON-LD, items microdata (itemscope/itemprop) et items RDFa
+// (typeof/property). Chaque item est retourné sous forme d'objet JSON-LD.
+func htmlStructuredData(doc *html.Node) []map[string]interface{} {
+	var items []map[string]interface{}
+	items = append(items, htmlJSONLD(doc)...)
+	items = append(items, htmlItems(doc, microdataSyntax)...)
+	items = append(items, htmlItems(doc, rdfaSyntax)...)
+	return items
+}
+
+// htmlJSONLD lit les scripts application/ld+json ; un tableau ou un @graph
+// donne un item par élément. Les blocs invalides sont ignorés.
+func htmlJSONLD(doc *html.Node) []map[string]interface{} {
+	var items []map[string]interface{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "script" &&
+			strings.EqualFold(strings.TrimSpace(htmlAttr(n, "type")), "application/ld+json") {
+			var value interface{}
+			if err := json.Unmarshal([]byte(htmlRawText(n)), &value); err == nil {
+				items = append(items, jsonLDItems(value)...)
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return items
+}
+
+func jsonLDItems(value interface{}) []map[string]interface{} {
+	var items []map[string]interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		for _, element := range v {
+			items = append(items, jsonLDItems(element)...)
+		}
+	case map[string]interface{}:
+		if graph, ok := v["@graph"]; ok {
+			return jsonLDItems(graph)
+		}
+		if context, ok := v["@context"].(string); ok && isSchemaOrg(context) {
+			delete(v, "@context")
+		}
+		normalizeTypes(v)
+		items = append(items, v)
+	}
+	return items
+}
+
+// normalizeTypes réduit récursivement les types Schema.org à leur nom court
+func normalizeTypes(item map[string]interface{}) {
+	for key, value := range item {
+		switch v := value.(type) {
+		case string:
+			if key == "@type" {
+				item[key] = schemaTypeName(v)
+			}
+		case []interface{}:
+			for i, element := range v {
+				if key == "@type" {
+					if s, ok := element.(string); ok {
+						v[i] = schemaTypeName(s)
+					}
+				} else if nested, ok := element.(map[string]interface{}); ok {
+					normalizeTypes(nested)
+				}
+			}
+		case map[string]interface{}:
+			normalizeTypes(v)
+		}
+	}
+}
+
+func isSchemaOrg(iri string) bool {
+	iri = strings.TrimSuffix(strings.TrimSpace(iri), "/")
+	return iri == "http://schema.org" || iri == "https://schema.org"
+}
+
+// schemaTypeName retourne "Article" pour "https://schema.org/Article" ou
+// "schema:Article" ; les autres vocabulaires sont conservés tels quels
+func schemaTypeName(t string) string {
+	t = strings.TrimSpace(t)
+	for _, prefix := range []string{"https://schema.org/", "http://schema.org/", "schema:"} {
+		if strings.HasPrefix(t, prefix) {
+			return strings.TrimPrefix(t, prefix)
+		}
+	}
+	return t
+}
+
+// itemSyntax décrit les attributs HTML d'une syntaxe de données structurées
+type itemSyntax struct {
+	isScope func(n *html.Node) bool
+	types   func(n *html.Node) []string
+	id      string // attribut portant l'identifiant de l'item
+	prop    string // attribut portant le nom des propriétés
+}
+
+var microdataSyntax = itemSyntax{
+	isScope: func(n *html.Node) bool { return htmlHasAttr(n, "itemscope") },
+	types:   func(n *html.Node) []string { return strings.Fields(htmlAttr(n, "itemtype")) },
+	id:      "itemid",
+	prop:    "itemprop",
+}
+
+var rdfaSyntax = itemSyntax{
+	isScope: func(n *html.Node) bool { return htmlHasAttr(n, "typeof") },
+	types:   func(n *html.Node) []string { return strings.Fields(htmlAttr(n, "typeof")) },
+	id:      "resource",
+	prop:    "property",
+}
+
+func htmlHasAttr(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlItems retourne les items de premier niveau d'une syntaxe, les items
+// imbriqués devenant les propriétés de leur parent
+func htmlItems(doc *html.Node, syntax itemSyntax) []map[string]interface{} {
+	var items []map[string]interface{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && syntax.isScope(n) {
+			items = append(items, htmlItem(n, syntax))
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return items
+}
+
+// htmlItem construit l'objet JSON-LD d'un élément ouvrant un item ; les
+// propriétés répétées deviennent des listes
+func htmlItem(scope *html.Node, syntax itemSyntax) map[string]interface{} {
+	item := make(map[string]interface{})
+	var types []interface{}
+	for _, t := range syntax.types(scope) {
+		types = append(types, schemaTypeName(t))
+	}
+	switch len(types) {
+	case 0:
+	case 1:
+		item["@type"] = types[0]
+	default:
+		item["@type"] = types
+	}
+	if id := htmlAttr(scope, syntax.id); id != "" {
+		item["@id"] = id
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			names := strings.Fields(htmlAttr(c, syntax.prop))
+			var value interface{}
+			if syntax.isScope(c) {
+				value = htmlItem(c, syntax)
+			} else if len(names) > 0 {
+				value = htmlItemValue(c)
+			}
+			for _, name := range names {
+				addItemProperty(item, schemaTypeName(name), value)
+			}
+			if !syntax.isScope(c) {
+				walk(c)
+			}
+		}
+	}
+	walk(scope)
+	return item
+}
+
+func addItemProperty(item map[string]interface{}, name string, value interface{}) {
+	existing, ok := item[name]
+	switch {
+	case !ok:
+		item[name] = value
+	case isList(existing):
+		item[name] = append(existing.([]interface{}), value)
+	default:
+		item[name] = []interface{}{existing, value}
+	}
+}
+
+func isList(value interface{}) bool {
+	_, ok := value.([]interface{})
+	return ok
+}
+
+// htmlItemValue retourne la valeur d'une propriété selon l'élément qui la porte
+func htmlItemValue(n *html.Node) interface{} {
+	if content := htmlAttr(n, "content"); content != "" {
+		return content
+	}
+	switch n.Data {
+	case "a", "link", "area":
+		if href := htmlAttr(n, "href"); href != "" {
+			return href
+		}
+	case "img", "audio", "video", "source", "iframe", "embed":
+		if src := htmlAttr(n, "src"); src != "" {
+			return src
+		}
+	case "object":
+		return htmlAttr(n, "data")
+	case "time":
+		if datetime := htmlAttr(n, "datetime"); datetime != "" {
+			return datetime
+		}
+	case "data", "meter":
+		return htmlAttr(n, "value")
+	}
+	if resource := htmlAttr(n, "resource"); resource != "" {
+		return resource
+	}
+	return collapseWhitespace(htmlRawText(n))
+}
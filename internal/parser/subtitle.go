@@ -0,0 +1,347 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Les parseurs de sous-titres et de transcriptions produisent une suite
+// d'éléments "cue" dont les attributs "start" et "end" (HH:MM:SS.mmm) et
+// "speaker" conservent le minutage et l'orateur. Le contenu de chaque cue est
+// préfixé de son horodatage pour que le LLM puisse dater les mentions.
+
+// SRTParser lit les sous-titres SubRip (.srt)
+type SRTParser struct{}
+
+func NewSRTParser() *SRTParser {
+	return &SRTParser{}
+}
+
+// VTTParser lit les sous-titres WebVTT (.vtt)
+type VTTParser struct{}
+
+func NewVTTParser() *VTTParser {
+	return &VTTParser{}
+}
+
+// TranscriptParser lit les transcriptions dont chaque intervention est de la
+// forme "[00:01:23] Orateur : texte" (horodatage et orateur facultatifs)
+type TranscriptParser struct{}
+
+func NewTranscriptParser() *TranscriptParser {
+	return &TranscriptParser{}
+}
+
+type cue struct {
+	id      string
+	start   time.Duration
+	end     time.Duration
+	speaker string
+	text    []string
+}
+
+var (
+	cueTimingPattern = regexp.MustCompile(`^\s*((?:\d+:)?\d{1,2}:\d{2}(?:[.,]\d{1,3})?)\s*-->\s*((?:\d+:)?\d{1,2}:\d{2}(?:[.,]\d{1,3})?)`)
+	voiceTagPattern  = regexp.MustCompile(`^<v(?:\.[^ >]*)?\s+([^>]+)>`)
+	markupPattern    = regexp.MustCompile(`<[^>]*>`)
+	speakerPattern   = regexp.MustCompile(`^\s*-?\s*(\p{Lu}[\p{L}\p{N}.'’-]*(?:\s[\p{L}\p{N}.'’-]+){0,2})\s*:\s+(.*)$`)
+	// Dans le texte d'un sous-titre, seul un nom en capitales désigne l'orateur
+	captionSpeakerPattern = regexp.MustCompile(`^\s*-?\s*(\p{Lu}[\p{Lu}\p{N}.'’-]*(?:\s[\p{Lu}\p{N}.'’-]+){0,2})\s*:\s+(.*)$`)
+	transcriptPattern     = regexp.MustCompile(`^\s*[\[(]((?:\d+:)?\d{1,2}:\d{2}(?:[.,]\d{1,3})?)[\])]\s*(.*)$`)
+)
+
+func (p *SRTParser) Parse(r io.Reader) (*Document, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var cues []cue
+	for _, block := range splitBlocks(lines) {
+		i := 0
+		id := ""
+		if i < len(block) && !cueTimingPattern.MatchString(block[i]) {
+			id = strings.TrimSpace(block[i])
+			i++
+		}
+		if i >= len(block) {
+			continue
+		}
+		c, ok := parseCueTiming(block[i])
+		if !ok {
+			continue
+		}
+		c.id = id
+		c.text = block[i+1:]
+		cues = append(cues, c)
+	}
+	if len(cues) == 0 {
+		return nil, fmt.Errorf("no subtitle cue found in SRT file")
+	}
+
+	return cueDocument(cues, map[string]string{"schema_type": "VideoObject"}, true), nil
+}
+
+func (p *VTTParser) Parse(r io.Reader) (*Document, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.HasPrefix(strings.TrimPrefix(lines[0], "\ufeff"), "WEBVTT") {
+		return nil, fmt.Errorf("invalid WebVTT file: missing WEBVTT header")
+	}
+
+	metadata := map[string]string{"schema_type": "VideoObject"}
+	var cues []cue
+	for i, block := range splitBlocks(lines) {
+		if i == 0 {
+			// En-tête : WEBVTT suivi d'éventuelles lignes "Clé: valeur"
+			for _, line := range block[1:] {
+				if key, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(key), "language") {
+					metadata["language"] = strings.TrimSpace(value)
+				}
+			}
+			continue
+		}
+		first := strings.TrimSpace(block[0])
+		if strings.HasPrefix(first, "NOTE") || first == "STYLE" || first == "REGION" {
+			continue
+		}
+
+		j := 0
+		id := ""
+		if !cueTimingPattern.MatchString(block[j]) {
+			id = first
+			j++
+		}
+		if j >= len(block) {
+			continue
+		}
+		c, ok := parseCueTiming(block[j])
+		if !ok {
+			continue
+		}
+		c.id = id
+		for _, line := range block[j+1:] {
+			if m := voiceTagPattern.FindStringSubmatch(line); m != nil && c.speaker == "" {
+				c.speaker = strings.TrimSpace(m[1])
+			}
+			c.text = append(c.text, line)
+		}
+		cues = append(cues, c)
+	}
+	if len(cues) == 0 {
+		return nil, fmt.Errorf("no subtitle cue found in WebVTT file")
+	}
+
+	return cueDocument(cues, metadata, true), nil
+}
+
+func (p *TranscriptParser) Parse(r io.Reader) (*Document, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var cues []cue
+	timed := false
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var start time.Duration
+		hasTime := false
+		if m := transcriptPattern.FindStringSubmatch(line); m != nil {
+			if d, err := ParseTimestamp(m[1]); err == nil {
+				start, hasTime, line = d, true, m[2]
+			}
+		}
+		speaker := ""
+		if m := speakerPattern.FindStringSubmatch(line); m != nil {
+			speaker, line = strings.TrimSpace(m[1]), m[2]
+		}
+
+		if !hasTime && speaker == "" && len(cues) > 0 {
+			// Suite de l'intervention précédente
+			last := &cues[len(cues)-1]
+			last.text = append(last.text, line)
+			continue
+		}
+		if !hasTime && len(cues) > 0 {
+			start = cues[len(cues)-1].start
+		}
+		timed = timed || hasTime
+		cues = append(cues, cue{start: start, speaker: speaker, text: []string{line}})
+	}
+	if len(cues) == 0 {
+		return nil, fmt.Errorf("empty transcript")
+	}
+
+	// La fin d'une intervention est le début de la suivante
+	for i := 0; i < len(cues)-1; i++ {
+		cues[i].end = cues[i+1].start
+	}
+	cues[len(cues)-1].end = cues[len(cues)-1].start
+
+	return cueDocument(cues, map[string]string{"schema_type": "PodcastEpisode"}, timed), nil
+}
+
+func parseCueTiming(line string) (cue, bool) {
+	m := cueTimingPattern.FindStringSubmatch(line)
+	if m == nil {
+		return cue{}, false
+	}
+	start, err := ParseTimestamp(m[1])
+	if err != nil {
+		return cue{}, false
+	}
+	end, err := ParseTimestamp(m[2])
+	if err != nil {
+		return cue{}, false
+	}
+	return cue{start: start, end: end}, true
+}
+
+// cueDocument construit le document à partir des cues ; la durée du média est la
+// fin de la dernière cue. Sans horodatage (timed à false), seuls les orateurs
+// sont conservés.
+func cueDocument(cues []cue, metadata map[string]string, timed bool) *Document {
+	var structure []DocumentElement
+	var duration time.Duration
+	for _, c := range cues {
+		if c.speaker == "" {
+			// Orateur indiqué en début de texte ("JEAN : ...")
+			if m := captionSpeakerPattern.FindStringSubmatch(strings.Join(c.text, " ")); m != nil {
+				c.speaker, c.text = strings.TrimSpace(m[1]), []string{m[2]}
+			}
+		}
+		content := cueContent(c, timed)
+		if content == "" {
+			continue
+		}
+
+		attributes := make(map[string]string)
+		if timed {
+			attributes["start"] = FormatTimestamp(c.start)
+			attributes["end"] = FormatTimestamp(c.end)
+		}
+		if c.speaker != "" {
+			attributes["speaker"] = c.speaker
+		}
+		if c.id != "" {
+			attributes["id"] = c.id
+		}
+		structure = append(structure, DocumentElement{Type: "cue", Content: content, Attributes: attributes})
+		if c.end > duration {
+			duration = c.end
+		}
+	}
+
+	if duration > 0 {
+		metadata["duration"] = ISODuration(duration)
+	}
+	return &Document{
+		Content:   TextOf(structure),
+		Metadata:  metadata,
+		Structure: structure,
+	}
+}
+
+// cueContent retourne le texte d'une cue sans balises, préfixé de son
+// horodatage et de son orateur
+func cueContent(c cue, withTime bool) string {
+	text := collapseWhitespace(markupPattern.ReplaceAllString(strings.Join(c.text, " "), ""))
+	if text == "" {
+		return ""
+	}
+	if c.speaker != "" {
+		text = c.speaker + ": " + text
+	}
+	if withTime {
+		text = "[" + FormatTimestamp(c.start)[:8] + "] " + text
+	}
+	return text
+}
+
+// ParseTimestamp lit un horodatage "HH:MM:SS,mmm", "HH:MM:SS.mmm" ou "MM:SS"
+func ParseTimestamp(s string) (time.Duration, error) {
+	s = strings.Replace(strings.TrimSpace(s), ",", ".", 1)
+	fraction := time.Duration(0)
+	if main, frac, ok := strings.Cut(s, "."); ok {
+		ms, err := strconv.Atoi((frac + "00")[:3])
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp: %s", s)
+		}
+		fraction = time.Duration(ms) * time.Millisecond
+		s = main
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp: %s", s)
+	}
+	total := time.Duration(0)
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid timestamp: %s", s)
+		}
+		total = total*60 + time.Duration(n)*time.Second
+	}
+	return total + fraction, nil
+}
+
+// FormatTimestamp formate une durée en "HH:MM:SS.mmm"
+func FormatTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// ISODuration formate une durée au format ISO 8601 utilisé par Schema.org (PT1H2M3S)
+func ISODuration(d time.Duration) string {
+	seconds := int(d.Round(time.Second).Seconds())
+	result := "PT"
+	if h := seconds / 3600; h > 0 {
+		result += strconv.Itoa(h) + "H"
+	}
+	if m := seconds / 60 % 60; m > 0 {
+		result += strconv.Itoa(m) + "M"
+	}
+	if s := seconds % 60; s > 0 || result == "PT" {
+		result += strconv.Itoa(s) + "S"
+	}
+	return result
+}
+
+func readLines(r io.Reader) ([]string, error) {
+	text, err := readText(r)
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(text), nil
+}
+
+// splitBlocks découpe les lignes en blocs séparés par des lignes vides
+func splitBlocks(lines []string) [][]string {
+	var blocks [][]string
+	var current []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, current)
+	}
+	return blocks
+}
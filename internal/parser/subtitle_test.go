@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSRTParser(t *testing.T) {
+	input := "1\r\n00:00:01,000 --> 00:00:04,500\r\nJEAN: Au commencement\r\nétait le Verbe.\r\n\r\n2\r\n00:00:05,000 --> 00:01:02,250\r\n<i>Et le Verbe était Dieu.</i>\r\n"
+	doc, err := NewSRTParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse SRT: %v", err)
+	}
+	if len(doc.Structure) != 2 {
+		t.Fatalf("Expected 2 cues, got %d", len(doc.Structure))
+	}
+
+	first := doc.Structure[0]
+	if first.Type != "cue" || first.Attributes["start"] != "00:00:01.000" || first.Attributes["end"] != "00:00:04.500" || first.Attributes["speaker"] != "JEAN" {
+		t.Errorf("Unexpected first cue attributes: %v", first.Attributes)
+	}
+	if first.Content != "[00:00:01] JEAN: Au commencement était le Verbe." {
+		t.Errorf("Unexpected first cue content: %q", first.Content)
+	}
+	if doc.Structure[1].Content != "[00:00:05] Et le Verbe était Dieu." {
+		t.Errorf("Markup should be removed, got %q", doc.Structure[1].Content)
+	}
+	if doc.Metadata["schema_type"] != "VideoObject" || doc.Metadata["duration"] != "PT1M2S" {
+		t.Errorf("Unexpected metadata: %v", doc.Metadata)
+	}
+}
+
+func TestVTTParser(t *testing.T) {
+	input := `WEBVTT
+Language: fr
+
+NOTE commentaire ignoré
+
+intro
+01:02.000 --> 01:05.000 align:start
+<v Marie Curie>Le radium est découvert en 1898.
+
+01:05.000 --> 01:09.000
+- Et le polonium ?
+`
+	doc, err := NewVTTParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse WebVTT: %v", err)
+	}
+	if len(doc.Structure) != 2 {
+		t.Fatalf("Expected 2 cues, got %+v", doc.Structure)
+	}
+	first := doc.Structure[0]
+	if first.Attributes["id"] != "intro" || first.Attributes["speaker"] != "Marie Curie" || first.Attributes["start"] != "00:01:02.000" {
+		t.Errorf("Unexpected cue attributes: %v", first.Attributes)
+	}
+	if first.Content != "[00:01:02] Marie Curie: Le radium est découvert en 1898." {
+		t.Errorf("Unexpected cue content: %q", first.Content)
+	}
+	if doc.Metadata["language"] != "fr" {
+		t.Errorf("Expected language from header, got %v", doc.Metadata)
+	}
+
+	if _, err := NewVTTParser().Parse(strings.NewReader("1\n00:00:01.000 --> 00:00:02.000\nTexte")); err == nil {
+		t.Error("Expected error for missing WEBVTT header")
+	}
+}
+
+func TestTranscriptParser(t *testing.T) {
+	input := `[00:00:00] Professeur : La jurisprudence est-elle une source de droit ?
+On a beaucoup hésité sur ce point.
+[00:01:30] Étudiant : Et l'arrêt de 1950 ?
+[00:02:10] Professeur : J'y reviens.`
+	doc, err := NewTranscriptParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse transcript: %v", err)
+	}
+	if len(doc.Structure) != 3 {
+		t.Fatalf("Expected 3 interventions, got %+v", doc.Structure)
+	}
+	first := doc.Structure[0]
+	if first.Attributes["speaker"] != "Professeur" || first.Attributes["end"] != "00:01:30.000" {
+		t.Errorf("Unexpected attributes: %v", first.Attributes)
+	}
+	if !strings.HasSuffix(first.Content, "source de droit ? On a beaucoup hésité sur ce point.") {
+		t.Errorf("Continuation line should be appended, got %q", first.Content)
+	}
+	if doc.Structure[1].Attributes["speaker"] != "Étudiant" || doc.Metadata["schema_type"] != "PodcastEpisode" {
+		t.Errorf("Unexpected second intervention: %+v", doc.Structure[1])
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	cases := map[string]time.Duration{
+		"00:00:01,500": 1500 * time.Millisecond,
+		"01:02:03.04":  time.Hour + 2*time.Minute + 3*time.Second + 40*time.Millisecond,
+		"02:05":        2*time.Minute + 5*time.Second,
+	}
+	for input, expected := range cases {
+		d, err := ParseTimestamp(input)
+		if err != nil || d != expected {
+			t.Errorf("ParseTimestamp(%q) = %v, %v; expected %v", input, d, err, expected)
+		}
+	}
+	if _, err := ParseTimestamp("abc"); err == nil {
+		t.Error("Expected error for invalid timestamp")
+	}
+}
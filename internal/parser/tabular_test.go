@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCSVParser(t *testing.T) {
+	input := "Nom;Prix;Ville\n\"Pain; complet\";2,50;Lyon\n\n;;\nCroissant;1,10;Paris\n"
+	doc, err := NewCSVParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if doc.Metadata["tabular"] != "true" || len(doc.Structure) != 1 {
+		t.Fatalf("Expected a single table, got %+v", doc)
+	}
+
+	headers, rows := TableData(doc.Structure[0])
+	if strings.Join(headers, ",") != "Nom,Prix,Ville" {
+		t.Errorf("Unexpected headers: %v", headers)
+	}
+	if len(rows) != 2 || rows[0][0] != "Pain; complet" || rows[1][2] != "Paris" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+	if cell := doc.Structure[0].Children[1].Children[1]; cell.Attributes["column"] != "Prix" {
+		t.Errorf("Expected cell column attribute, got %+v", cell)
+	}
+}
+
+func TestXLSXParser(t *testing.T) {
+	archive := buildZip(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Produits" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><si><t>Nom</t></si><si><t>Prix</t></si><si><r><t>Pain </t></r><r><t>complet</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>2.5</v></c></row>
+<row r="3"><c r="A3" t="inlineStr"><is><t>Croissant</t></is></c><c r="C3"><v>1.1</v></c></row>
+</sheetData></worksheet>`,
+	})
+
+	doc, err := NewXLSXParser().Parse(archive)
+	if err != nil {
+		t.Fatalf("Failed to parse XLSX: %v", err)
+	}
+	if len(doc.Structure) != 1 || doc.Structure[0].Attributes["name"] != "Produits" || doc.Metadata["rows"] != "2" {
+		t.Fatalf("Unexpected document: %+v", doc)
+	}
+	headers, rows := TableData(doc.Structure[0])
+	if len(headers) != 3 || headers[1] != "column_2" || headers[2] != "Prix" {
+		t.Errorf("Unexpected headers: %v", headers)
+	}
+	if rows[0][0] != "Pain complet" || rows[0][2] != "2.5" || rows[1][0] != "Croissant" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+}
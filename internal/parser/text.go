@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"bufio"
 	"io"
 	"strings"
 )
@@ -12,13 +11,18 @@ func NewTextParser() *TextParser {
 	return &TextParser{}
 }
 
+// Parse lit le texte quel que soit son encodage (voir decodeText) et produit un
+// paragraphe par ligne, sans limite de longueur de ligne
 func (p *TextParser) Parse(r io.Reader) (*Document, error) {
-	scanner := bufio.NewScanner(r)
+	text, err := readText(r)
+	if err != nil {
+		return nil, err
+	}
+
 	var content strings.Builder
 	var structure []DocumentElement
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	for _, line := range splitLines(text) {
 		content.WriteString(line + "\n")
 		structure = append(structure, DocumentElement{
 			Type:    "paragraph",
@@ -26,10 +30,6 @@ func (p *TextParser) Parse(r io.Reader) (*Document, error) {
 		})
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
 	return &Document{
 		Content:   content.String(),
 		Metadata:  make(map[string]string),
@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// textBlockTypes sont les éléments dont Content contient tout le texte ; leurs
+// enfants éventuels sont des éléments en ligne
+var textBlockTypes = map[string]bool{
+	"heading":   true,
+	"paragraph": true,
+	"row":       true,
+	"cell":      true,
+	"code":      true,
+}
+
+// IsTextBlock indique si le texte de l'élément est entièrement porté par Content,
+// sans qu'il soit nécessaire de parcourir ses enfants
+func (e DocumentElement) IsTextBlock() bool {
+	return len(e.Children) == 0 || textBlockTypes[e.Type]
+}
+
+// HeadingLevel retourne le niveau d'un titre (1 à 6)
+func (e DocumentElement) HeadingLevel() (int, bool) {
+	if e.Type != "heading" {
+		return 0, false
+	}
+	level, err := strconv.Atoi(e.Attributes["level"])
+	if err != nil || level < 1 {
+		return 1, true
+	}
+	return level, true
+}
+
+// Walk parcourt l'arbre en profondeur, parents avant enfants
+func Walk(elements []DocumentElement, fn func(el DocumentElement, depth int)) {
+	var walk func(elements []DocumentElement, depth int)
+	walk = func(elements []DocumentElement, depth int) {
+		for _, el := range elements {
+			fn(el, depth)
+			walk(el.Children, depth+1)
+		}
+	}
+	walk(elements, 0)
+}
+
+// TextOf reconstitue le texte d'une suite d'éléments, un bloc par ligne
+func TextOf(elements []DocumentElement) string {
+	var lines []string
+	for _, el := range elements {
+		if el.IsTextBlock() {
+			if text := strings.TrimSpace(el.Content); text != "" {
+				lines = append(lines, text)
+			}
+			continue
+		}
+		if text := TextOf(el.Children); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newContainer crée un conteneur dont le contenu est le texte de ses enfants
+func newContainer(elementType string, attributes map[string]string, children []DocumentElement) DocumentElement {
+	return DocumentElement{
+		Type:       elementType,
+		Content:    TextOf(children),
+		Attributes: attributes,
+		Children:   children,
+	}
+}
+
+// nestSections regroupe une suite plate de blocs en sections : chaque titre ouvre
+// une section (attributs "level" et "title") qui contient le titre puis les blocs
+// suivants, jusqu'au prochain titre de niveau égal ou supérieur.
+func nestSections(elements []DocumentElement) []DocumentElement {
+	type node struct {
+		element  DocumentElement
+		level    int
+		children []*node
+	}
+
+	root := &node{}
+	stack := []*node{root}
+	for _, el := range elements {
+		level, isHeading := el.HeadingLevel()
+		if !isHeading {
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, &node{element: el})
+			continue
+		}
+
+		for len(stack) > 1 && stack[len(stack)-1].level >= level {
+			stack = stack[:len(stack)-1]
+		}
+		section := &node{
+			element: DocumentElement{
+				Type: "section",
+				Attributes: map[string]string{
+					"level": strconv.Itoa(level),
+					"title": el.Content,
+				},
+			},
+			level:    level,
+			children: []*node{{element: el}},
+		}
+		parent := stack[len(stack)-1]
+		parent.children = append(parent.children, section)
+		stack = append(stack, section)
+	}
+
+	var build func(nodes []*node) []DocumentElement
+	build = func(nodes []*node) []DocumentElement {
+		result := make([]DocumentElement, 0, len(nodes))
+		for _, n := range nodes {
+			if n.element.Type == "section" && n.level > 0 {
+				result = append(result, newContainer("section", n.element.Attributes, build(n.children)))
+			} else {
+				result = append(result, n.element)
+			}
+		}
+		return result
+	}
+	return build(root.children)
+}
+
+// collapseWhitespace remplace les suites d'espaces par une seule espace
+func collapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// textParagraphs découpe un texte brut en paragraphes séparés par des lignes vides
+func textParagraphs(text string) []DocumentElement {
+	var paragraphs []DocumentElement
+	var lines []string
+	flush := func() {
+		if content := collapseWhitespace(strings.Join(lines, " ")); content != "" {
+			paragraphs = append(paragraphs, DocumentElement{Type: "paragraph", Content: content})
+		}
+		lines = nil
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return paragraphs
+}
@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// XLSXParser lit les classeurs Excel (Office Open XML). Chaque feuille devient un
+// élément "table" (attribut "name") dont la première ligne est l'en-tête.
+type XLSXParser struct{}
+
+func NewXLSXParser() *XLSXParser {
+	return &XLSXParser{}
+}
+
+func (p *XLSXParser) Parse(r io.Reader) (*Document, error) {
+	archive, err := openZip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	workbook, err := readZipXML(archive, "xl/workbook.xml")
+	if err != nil {
+		return nil, err
+	}
+	if workbook == nil {
+		return nil, fmt.Errorf("invalid XLSX file: missing xl/workbook.xml")
+	}
+
+	targets := make(map[string]string)
+	rels, err := readZipXML(archive, "xl/_rels/workbook.xml.rels")
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range rels.findAll("Relationship") {
+		target := rel.attr("Target")
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		targets[rel.attr("Id")] = target
+	}
+
+	var sharedStrings []string
+	shared, err := readZipXML(archive, "xl/sharedStrings.xml")
+	if err != nil {
+		return nil, err
+	}
+	for _, si := range shared.findAll("si") {
+		sharedStrings = append(sharedStrings, xlsxText(si))
+	}
+
+	var structure []DocumentElement
+	totalRows := 0
+	for _, sheet := range workbook.findAll("sheet") {
+		name := sheet.attr("name")
+		sheetXML, err := readZipXML(archive, targets[sheet.attr("id")])
+		if err != nil {
+			return nil, err
+		}
+		if sheetXML == nil {
+			continue
+		}
+
+		records := xlsxRecords(sheetXML, sharedStrings)
+		if len(records) == 0 {
+			continue
+		}
+		structure = append(structure, tableElement(records, map[string]string{"name": name}))
+		totalRows += len(records) - 1
+	}
+	if len(structure) == 0 {
+		return nil, fmt.Errorf("no data found in XLSX file")
+	}
+
+	return &Document{
+		Content:   TextOf(structure),
+		Metadata:  map[string]string{"tabular": "true", "rows": strconv.Itoa(totalRows)},
+		Structure: structure,
+	}, nil
+}
+
+// xlsxRecords lit les lignes d'une feuille ; les cellules absentes sont vides
+func xlsxRecords(sheet *xmlNode, sharedStrings []string) [][]string {
+	var records [][]string
+	for _, row := range sheet.find("sheetData").Children {
+		if row.Name != "row" {
+			continue
+		}
+		var record []string
+		for _, c := range row.Children {
+			if c.Name != "c" {
+				continue
+			}
+			column := len(record)
+			if ref := c.attr("r"); ref != "" {
+				column = columnIndex(ref)
+			}
+			for len(record) < column {
+				record = append(record, "")
+			}
+
+			value := c.child("v").text()
+			switch c.attr("t") {
+			case "s":
+				if i, err := strconv.Atoi(value); err == nil && i >= 0 && i < len(sharedStrings) {
+					value = sharedStrings[i]
+				}
+			case "inlineStr":
+				value = xlsxText(c.child("is"))
+			case "b":
+				value = strconv.FormatBool(value == "1")
+			}
+			record = append(record, value)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// xlsxText retourne le texte d'une chaîne, éventuellement composée de plusieurs
+// segments mis en forme (les indications phonétiques sont ignorées)
+func xlsxText(si *xmlNode) string {
+	if si == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, c := range si.Children {
+		switch c.Name {
+		case "t":
+			sb.WriteString(c.text())
+		case "r":
+			sb.WriteString(c.child("t").text())
+		}
+	}
+	return sb.String()
+}
+
+// columnIndex convertit une référence de cellule ("C12") en indice de colonne (2)
+func columnIndex(ref string) int {
+	index := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+	}
+	return index - 1
+}
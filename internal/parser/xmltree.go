@@ -0,0 +1,187 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// xmlNode est une représentation minimale d'un document XML, utilisée par les
+// parseurs de formats bureautiques. Les noms sont comparés sans espace de noms.
+type xmlNode struct {
+	Name     string
+	Attr     []xml.Attr
+	Children []*xmlNode
+	// Text contient le texte situé directement dans l'élément
+	Text string
+	// parts conserve l'ordre du texte et des éléments enfants (nil pour un élément)
+	parts []*xmlNode
+}
+
+func parseXMLTree(r io.Reader) (*xmlNode, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.CharsetReader = charset.NewReaderLabel
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: t.Name.Local, Attr: t.Attr}
+			parent.Children = append(parent.Children, node)
+			parent.parts = append(parent.parts, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			text := string(t)
+			parent.Text += text
+			parent.parts = append(parent.parts, &xmlNode{Text: text})
+		}
+	}
+	return root, nil
+}
+
+// attr retourne la valeur d'un attribut, quel que soit son espace de noms
+func (n *xmlNode) attr(local string) string {
+	if n == nil {
+		return ""
+	}
+	for _, a := range n.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// child retourne le premier enfant direct portant ce nom
+func (n *xmlNode) child(local string) *xmlNode {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Children {
+		if c.Name == local {
+			return c
+		}
+	}
+	return nil
+}
+
+// find retourne le premier descendant portant ce nom
+func (n *xmlNode) find(local string) *xmlNode {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Children {
+		if c.Name == local {
+			return c
+		}
+		if found := c.find(local); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// findAll retourne tous les descendants portant ce nom
+func (n *xmlNode) findAll(local string) []*xmlNode {
+	if n == nil {
+		return nil
+	}
+	var result []*xmlNode
+	for _, c := range n.Children {
+		if c.Name == local {
+			result = append(result, c)
+		}
+		result = append(result, c.findAll(local)...)
+	}
+	return result
+}
+
+// isText indique si la partie est un nœud texte
+func (n *xmlNode) isText() bool {
+	return n.Name == ""
+}
+
+// text retourne le texte de tous les descendants
+func (n *xmlNode) text() string {
+	if n == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, part := range n.parts {
+		if part.isText() {
+			sb.WriteString(part.Text)
+		} else {
+			sb.WriteString(part.text())
+		}
+	}
+	return sb.String()
+}
+
+// openZip lit entièrement une archive zip (les formats bureautiques ne peuvent
+// être lus qu'avec un accès aléatoire)
+func openZip(r io.Reader) (*zip.Reader, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+	return archive, nil
+}
+
+// readZipFile retourne le contenu d'un fichier de l'archive ; il retourne nil si
+// le fichier est absent
+func readZipFile(archive *zip.Reader, name string) ([]byte, error) {
+	for _, f := range archive.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("error opening %s: %w", name, err)
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", name, err)
+		}
+		return content, nil
+	}
+	return nil, nil
+}
+
+// readZipXML analyse un fichier XML de l'archive ; il retourne nil si le fichier
+// est absent
+func readZipXML(archive *zip.Reader, name string) (*xmlNode, error) {
+	content, err := readZipFile(archive, name)
+	if err != nil || content == nil {
+		return nil, err
+	}
+	root, err := parseXMLTree(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", name, err)
+	}
+	return root, nil
+}
@@ -0,0 +1,65 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+)
+
+// checkpoint conserve les résultats déjà obtenus pour une entrée afin qu'une
+// conversion interrompue reprenne au segment suivant
+type checkpoint struct {
+	Key       string                     `json:"key"`       // identifie l'entrée et les options de conversion
+	Documents [][]map[string]interface{} `json:"documents"` // résultats des documents terminés (boîte mbox)
+	Segments  [][]map[string]interface{} `json:"segments"`  // résultats des segments terminés du document en cours
+	Context   *llm.AnalysisContext       `json:"context"`   // contexte d'analyse après le dernier segment
+
+	path string // fichier du point de reprise, vide s'il n'y en a pas
+}
+
+// loadCheckpoint lit le point de reprise ; il est ignoré s'il est absent, illisible
+// ou s'il a été produit pour une autre entrée ou d'autres options
+func loadCheckpoint(path, key string) *checkpoint {
+	cp := &checkpoint{Key: key, path: path}
+	if path == "" {
+		return cp
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cp
+	}
+	var saved checkpoint
+	if err := json.Unmarshal(data, &saved); err != nil || saved.Key != key {
+		return cp
+	}
+	saved.path = path
+	return &saved
+}
+
+// save écrit le point de reprise dans un fichier temporaire renommé ensuite, pour
+// qu'une interruption pendant l'écriture ne laisse pas de fichier tronqué
+func (cp *checkpoint) save(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("error marshaling checkpoint: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating checkpoint directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("error writing checkpoint: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// resumed indique si le point de reprise contient des résultats
+func (cp *checkpoint) resumed() bool {
+	return len(cp.Documents) > 0 || len(cp.Segments) > 0
+}
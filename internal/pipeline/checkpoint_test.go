@@ -0,0 +1,50 @@
+package pipeline
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/chrlesur/json-ld-converter/internal/config"
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+)
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "doc.json")
+
+	cp := loadCheckpoint(path, "k1")
+	if cp.resumed() {
+		t.Fatal("missing checkpoint should be empty")
+	}
+	cp.Segments = append(cp.Segments, []map[string]interface{}{{"@type": "Article"}})
+	cp.Context = &llm.AnalysisContext{Segment: 1}
+	if err := cp.save(path); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	resumed := loadCheckpoint(path, "k1")
+	if !resumed.resumed() || resumed.Segments[0][0]["@type"] != "Article" || resumed.Context.Segment != 1 {
+		t.Errorf("resumed checkpoint = %+v", resumed)
+	}
+	if loadCheckpoint(path, "k2").resumed() {
+		t.Error("checkpoint with another key should be ignored")
+	}
+}
+
+func TestOptionsHash(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Conversion.Model = "m1"
+	p := &Pipeline{cfg: cfg}
+	base := p.OptionsHash()
+
+	cfg.Conversion.APIKey = "secret"
+	if p.OptionsHash() != base {
+		t.Error("credentials should not change the options hash")
+	}
+	if p.WithOptions(Options{Instructions: "résumer"}).OptionsHash() == base {
+		t.Error("instructions should change the options hash")
+	}
+	cfg.Conversion.Model = "m2"
+	if p.OptionsHash() == base {
+		t.Error("model should change the options hash")
+	}
+}
@@ -0,0 +1,652 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chrlesur/json-ld-converter/internal/config"
+	"github.com/chrlesur/json-ld-converter/internal/examples"
+	"github.com/chrlesur/json-ld-converter/internal/jsonld"
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+	"github.com/chrlesur/json-ld-converter/internal/parser"
+	"github.com/chrlesur/json-ld-converter/internal/schema"
+	"github.com/chrlesur/json-ld-converter/internal/segmentation"
+	"github.com/chrlesur/json-ld-converter/pkg/tokenizer"
+)
+
+// Options paramètre une conversion
+type Options struct {
+	InputFormat  string // format d'entrée imposé ; détecté d'après le contenu et le nom si vide
+	Instructions string // instructions supplémentaires transmises au LLM
+	MappingFile  string // correspondance colonnes-propriétés des entrées tabulaires
+
+	// ContextFile reçoit le contexte d'analyse après chaque segment. Sans
+	// CheckpointFile, il s'accompagne d'un point de reprise (ContextFile +
+	// ".checkpoint") : une conversion interrompue de la même entrée reprend au
+	// segment suivant avec le contexte enregistré ; sinon la conversion part
+	// d'un contexte vide et le fichier est remplacé.
+	ContextFile string
+
+	// CheckpointFile conserve les résultats de chaque segment pour reprendre une
+	// conversion interrompue ; il est supprimé lorsque la conversion aboutit.
+	// CheckpointKey identifie l'entrée et les options : un point de reprise
+	// enregistré avec une autre clé est ignoré.
+	CheckpointFile string
+	CheckpointKey  string
+
+	// Stream reçoit chaque nœud JSON-LD dès qu'il est produit : les nœuds de
+	// chaque segment, puis, en fin de document, le nœud du document (sans le
+	// contenu de ses parties, déjà transmis) et les données structurées
+	// déclarées. Une erreur interrompt la conversion.
+	Stream func(node map[string]interface{}) error
+}
+
+// Pipeline enchaîne la détection du format, l'analyse, la segmentation et la
+// conversion d'un document ; il est partagé par le CLI et le serveur
+type Pipeline struct {
+	cfg       *config.Config
+	client    llm.LLMClient
+	schemaOrg *schema.SchemaOrg
+	opts      Options
+	stats     jsonld.Stats
+	examples  *examples.Library
+}
+
+// New crée le client LLM et charge le vocabulaire Schema.org
+func New(cfg *config.Config, opts Options) (*Pipeline, error) {
+	logger.Debug(fmt.Sprintf("Configuration loaded: %+v", cfg))
+
+	// Création du client LLM
+	client, err := llm.NewLLMClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating LLM client: %w", err)
+	}
+	logger.Debug("LLM client created successfully")
+
+	// Chargement du schéma Schema.org
+	schemaOrg, err := schema.LoadSchemaOrg(cfg.Schema.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading Schema.org: %w", err)
+	}
+	logger.Debug("Schema.org loaded successfully")
+
+	// Chargement des exemples qui guident l'extraction des propriétés
+	var lib *examples.Library
+	if cfg.Examples.Dir != "" {
+		lib, err = examples.Load(cfg.Examples.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("error loading examples: %w", err)
+		}
+		logger.Debug(fmt.Sprintf("%d examples loaded from %s", lib.Len(), cfg.Examples.Dir))
+	}
+
+	return &Pipeline{cfg: cfg, client: client, schemaOrg: schemaOrg, opts: opts, examples: lib}, nil
+}
+
+// ConvertFile convertit un fichier et écrit le JSON-LD obtenu
+func (p *Pipeline) ConvertFile(ctx context.Context, inputFilePath, outputFilePath string) error {
+	// Lecture et analyse du fichier d'entrée
+	file, err := os.Open(inputFilePath)
+	if err != nil {
+		return fmt.Errorf("error opening input file: %w", err)
+	}
+	defer file.Close()
+	logger.Debug("Input file opened successfully")
+
+	jsonString, err := p.ConvertReader(ctx, file, inputFilePath)
+	if err != nil {
+		return err
+	}
+
+	// Écriture du résultat dans le fichier de sortie
+	err = os.WriteFile(outputFilePath, jsonString, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing output file: %w", err)
+	}
+	logger.Debug("JSON-LD written to output file successfully")
+	return nil
+}
+
+// ConvertReader convertit le contenu d'un flux (name sert à la détection du
+// format) et retourne le JSON-LD sérialisé
+func (p *Pipeline) ConvertReader(ctx context.Context, r io.Reader, name string) ([]byte, error) {
+	p.stats = jsonld.Stats{}
+	docs, err := p.Parse(r, name)
+	if err != nil {
+		return nil, err
+	}
+
+	combinedResult, err := p.Convert(ctx, docs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sérialisation du JSON-LD combiné
+	jsonString, err := json.MarshalIndent(combinedResult, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling combined JSON-LD: %w", err)
+	}
+	return jsonString, nil
+}
+
+// Parse analyse un flux avec le parseur du format imposé ou, à défaut, du format
+// détecté d'après son contenu et son nom. Un fichier peut regrouper plusieurs
+// documents (boîte mbox).
+func (p *Pipeline) Parse(r io.Reader, name string) ([]*parser.Document, error) {
+	format := p.opts.InputFormat
+	if format == "" {
+		var err error
+		format, r, err = parser.Detect(r, name)
+		if err != nil {
+			return nil, &jsonld.ConversionError{Stage: "détection du format", Err: err}
+		}
+		logger.Debug(fmt.Sprintf("Detected input format: %s", format))
+	}
+
+	// Création du parseur de document
+	prs, err := parser.NewParser(format)
+	if err != nil {
+		return nil, &jsonld.ConversionError{Stage: "détection du format", Err: err}
+	}
+	logger.Debug(fmt.Sprintf("Parser created for file type: %s", format))
+
+	var docs []*parser.Document
+	if mp, ok := prs.(parser.MultiParser); ok {
+		docs, err = mp.ParseAll(r)
+	} else {
+		var doc *parser.Document
+		doc, err = prs.Parse(r)
+		docs = []*parser.Document{doc}
+	}
+	if err != nil {
+		return nil, &jsonld.ConversionError{Stage: "analyse du document", Err: err}
+	}
+	logger.Debug(fmt.Sprintf("Document parsed successfully (%d documents)", len(docs)))
+	return docs, nil
+}
+
+// Convert convertit des documents analysés et retourne le graphe JSON-LD combiné
+func (p *Pipeline) Convert(ctx context.Context, docs []*parser.Document) (map[string]interface{}, error) {
+	// Création du convertisseur
+	conv := p.newConverter(p.opts.Instructions)
+	defer func() { p.stats = conv.Stats() }()
+	logger.Debug("Converter created successfully")
+
+	// Reprise des segments déjà convertis lors d'une exécution interrompue, avec
+	// le contexte d'analyse enregistré après le dernier d'entre eux
+	checkpointFile, checkpointKey := p.checkpointFor(docs)
+	cp := loadCheckpoint(checkpointFile, checkpointKey)
+	if cp.resumed() {
+		conv.SetAnalysisContext(cp.Context)
+		logger.Info(fmt.Sprintf("Resuming from checkpoint %s (%d documents and %d segments already converted)", checkpointFile, len(cp.Documents), len(cp.Segments)))
+	}
+
+	// Segmentation du document
+	segmenter, err := newSegmenter(p.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating segmenter: %w", err)
+	}
+
+	var allResults []map[string]interface{}
+	for i, doc := range docs {
+		if i < len(cp.Documents) {
+			allResults = append(allResults, cp.Documents[i]...)
+			if err := p.emit(cp.Documents[i]...); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if len(docs) > 1 {
+			logger.Info(fmt.Sprintf("Converting document %d of %d", i+1, len(docs)))
+		}
+		results, err := p.convertDocument(ctx, conv, segmenter, doc, cp)
+		if err != nil {
+			if len(docs) > 1 {
+				return nil, fmt.Errorf("document %d: %w", i+1, err)
+			}
+			return nil, err
+		}
+		allResults = append(allResults, results...)
+
+		cp.Documents = append(cp.Documents, results)
+		cp.Segments = nil
+		cp.Context = conv.AnalysisContext()
+		p.saveCheckpoint(cp)
+	}
+
+	if checkpointFile != "" {
+		if err := os.Remove(checkpointFile); err != nil && !os.IsNotExist(err) {
+			logger.Warning(fmt.Sprintf("Unable to remove checkpoint: %v", err))
+		}
+	}
+
+	// Les courriels d'une boîte mbox sont regroupés en fils de discussion
+	if len(docs) > 1 && docs[0].Metadata["schema_type"] == "EmailMessage" {
+		allResults = jsonld.EmailThreads(docs, allResults)
+	}
+
+	// Combinaison de tous les résultats
+	return graph(allResults), nil
+}
+
+// newConverter crée un convertisseur avec les instructions données
+func (p *Pipeline) newConverter(instructions string) *jsonld.Converter {
+	conv := jsonld.NewConverter(p.schemaOrg, p.client, p.cfg.Conversion.MaxTokens, instructions)
+	conv.SetContextLimits(p.cfg.Conversion.ContextBudget, p.cfg.Conversion.SummaryInterval)
+	if p.examples != nil {
+		budget := p.cfg.Examples.MaxTokens
+		if budget <= 0 {
+			budget = defaultExampleBudget
+		}
+		conv.SetExamples(p.examples, budget)
+	}
+	return conv
+}
+
+// defaultExampleBudget est le nombre de tokens réservés par défaut aux exemples
+// dans le prompt d'extraction des propriétés
+const defaultExampleBudget = 800
+
+// graph rassemble des nœuds en un graphe JSON-LD
+func graph(nodes []map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"@context": "https://schema.org",
+		"@graph":   nodes,
+	}
+}
+
+// convertDocument segmente et convertit un document. Lorsque le type du document
+// est connu (livre EPUB, vidéo, courriel), les résultats sont rattachés à un nœud
+// racine unique ; sinon un nœud est retourné par segment. Les données structurées
+// déclarées par le document sont fusionnées avec les nœuds produits. Les segments
+// présents dans le point de reprise ne sont pas reconvertis.
+func (p *Pipeline) convertDocument(ctx context.Context, conv *jsonld.Converter, segmenter segmentation.Segmenter, doc *parser.Document, cp *checkpoint) ([]map[string]interface{}, error) {
+	if doc.Metadata["tabular"] == "true" {
+		return p.convertTables(ctx, conv, doc)
+	}
+
+	segments, err := segmenter.Segment(ctx, doc)
+	if err != nil {
+		return nil, &jsonld.ConversionError{Stage: "segmentation", Err: err}
+	}
+	logger.Debug(fmt.Sprintf("Document segmented into %d parts", len(segments)))
+
+	// Les données structurées déjà déclarées par la page amorcent le contexte
+	// (déjà amorcé si la conversion reprend en cours de document)
+	if len(cp.Segments) == 0 {
+		conv.SeedStructuredData(doc.StructuredData)
+	}
+
+	var allResults []map[string]interface{}
+	var parts []jsonld.Part
+	logger.SetTotalChunks(len(segments))
+	// Conversion de chaque segment en JSON-LD
+	for i, segment := range segments {
+		if i < len(cp.Segments) {
+			allResults = append(allResults, cp.Segments[i]...)
+			parts = addToPart(parts, segment.Metadata, cp.Segments[i])
+			if err := p.emit(cp.Segments[i]...); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		logger.Debug(fmt.Sprintf("Processing segment %d of %d", i+1, len(segments)))
+
+		results, err := convertSegment(ctx, conv, segment.Content, segmentMetadata(doc, segment), p.cfg.Conversion.OverflowStrategy, 0)
+		if err != nil {
+			return nil, fmt.Errorf("error converting segment %d to JSON-LD: %w", i+1, err)
+		}
+
+		allResults = append(allResults, results...)
+		parts = addToPart(parts, segment.Metadata, results)
+
+		cp.Segments = append(cp.Segments, results)
+		cp.Context = conv.AnalysisContext()
+		p.saveCheckpoint(cp)
+
+		if err := p.emit(results...); err != nil {
+			return nil, err
+		}
+
+		if p.opts.ContextFile != "" {
+			if err := llm.SaveAnalysisContext(p.opts.ContextFile, conv.AnalysisContext()); err != nil {
+				logger.Warning(fmt.Sprintf("Unable to save analysis context: %v", err))
+			}
+		}
+	}
+
+	// Rattachement des résultats au nœud du document lorsque son type est connu
+	// (livre EPUB et ses chapitres, vidéo et ses extraits, courriel)
+	if doc.Metadata["schema_type"] != "" {
+		allResults = []map[string]interface{}{jsonld.DocumentNode(doc.Metadata, parts)}
+		if err := p.emit(streamedDocumentNode(doc.Metadata, parts)); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.emit(doc.StructuredData...); err != nil {
+		return nil, err
+	}
+	return jsonld.MergeStructuredData(doc.StructuredData, allResults), nil
+}
+
+// emit transmet des nœuds au flux des options, s'il y en a un
+func (p *Pipeline) emit(nodes ...map[string]interface{}) error {
+	if p.opts.Stream == nil {
+		return nil
+	}
+	for _, node := range nodes {
+		if err := p.opts.Stream(node); err != nil {
+			return fmt.Errorf("error streaming JSON-LD: %w", err)
+		}
+	}
+	return nil
+}
+
+// streamedDocumentNode construit le nœud d'un document dont les parties ont
+// déjà été transmises segment par segment : seules leur description et leur
+// position sont reprises
+func streamedDocumentNode(metadata map[string]string, parts []jsonld.Part) map[string]interface{} {
+	var outline []jsonld.Part
+	for _, part := range parts {
+		if part.Name == "" && part.Position == 0 {
+			continue
+		}
+		part.Nodes = nil
+		outline = append(outline, part)
+	}
+	node := jsonld.DocumentNode(metadata, outline)
+	if hasPart, ok := node["hasPart"].([]map[string]interface{}); ok {
+		for _, partNode := range hasPart {
+			delete(partNode, "hasPart")
+		}
+	}
+	return node
+}
+
+// checkpointFor retourne le point de reprise d'une conversion : celui des
+// options ou, à défaut, celui qui accompagne le fichier de contexte, identifié
+// par le contenu des documents et les options
+func (p *Pipeline) checkpointFor(docs []*parser.Document) (string, string) {
+	if p.opts.CheckpointFile != "" || p.opts.ContextFile == "" {
+		return p.opts.CheckpointFile, p.opts.CheckpointKey
+	}
+	h := sha256.New()
+	for _, doc := range docs {
+		fmt.Fprintf(h, "%d:%s", len(doc.Content), doc.Content)
+	}
+	return p.opts.ContextFile + ".checkpoint", hex.EncodeToString(h.Sum(nil)) + ":" + p.OptionsHash()
+}
+
+// saveCheckpoint enregistre le point de reprise si la conversion en prévoit un ;
+// un échec d'écriture n'interrompt pas la conversion
+func (p *Pipeline) saveCheckpoint(cp *checkpoint) {
+	if err := cp.save(cp.path); err != nil {
+		logger.Warning(fmt.Sprintf("Unable to save checkpoint: %v", err))
+	}
+}
+
+// convertTables convertit chaque ligne des tableaux d'un document tabulaire en
+// nœud typé selon une correspondance colonnes-propriétés, lue depuis le fichier
+// de correspondance ou proposée par le LLM à partir de l'en-tête et de quelques lignes
+func (p *Pipeline) convertTables(ctx context.Context, conv *jsonld.Converter, doc *parser.Document) ([]map[string]interface{}, error) {
+	mappingFile := p.opts.MappingFile
+	var mapping *jsonld.TableMapping
+	if mappingFile != "" {
+		if _, err := os.Stat(mappingFile); err == nil {
+			mapping, err = jsonld.LoadTableMapping(mappingFile)
+			if err != nil {
+				return nil, err
+			}
+			logger.Info(fmt.Sprintf("Using table mapping from %s", mappingFile))
+		}
+	}
+
+	var results []map[string]interface{}
+	for _, el := range doc.Structure {
+		if el.Type != "table" {
+			continue
+		}
+		headers, rows := parser.TableData(el)
+		if len(rows) == 0 {
+			continue
+		}
+
+		tableMapping := mapping
+		if tableMapping == nil {
+			sample := rows
+			if len(sample) > 5 {
+				sample = sample[:5]
+			}
+			var err error
+			tableMapping, err = conv.ProposeTableMapping(ctx, headers, sample)
+			if err != nil {
+				return nil, fmt.Errorf("error proposing table mapping: %w", err)
+			}
+			if mappingFile != "" {
+				if err := jsonld.SaveTableMapping(mappingFile, tableMapping); err != nil {
+					logger.Warning(fmt.Sprintf("Unable to save table mapping: %v", err))
+				} else {
+					mapping = tableMapping
+					logger.Info(fmt.Sprintf("Table mapping saved to %s", mappingFile))
+				}
+			}
+		}
+
+		logger.Info(fmt.Sprintf("Converting %d rows of table %s", len(rows), el.Attributes["name"]))
+		nodes, err := conv.ConvertTable(ctx, tableMapping, headers, rows)
+		if err != nil {
+			return nil, fmt.Errorf("error converting table: %w", err)
+		}
+		results = append(results, nodes...)
+		if err := p.emit(nodes...); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// segmentMetadata combine les métadonnées du document et celles du segment,
+// préfixées par "segment_"
+func segmentMetadata(doc *parser.Document, segment segmentation.Segment) map[string]string {
+	metadata := make(map[string]string)
+	for k, v := range doc.Metadata {
+		metadata[k] = v
+	}
+	for k, v := range segment.Metadata {
+		metadata["segment_"+k] = v
+	}
+	return metadata
+}
+
+// addToPart ajoute les résultats d'un segment à la partie dont il provient : les
+// segments consécutifs d'un même chapitre forment une seule partie, et chaque
+// segment minuté (sous-titres, transcription) forme un extrait
+func addToPart(parts []jsonld.Part, metadata map[string]string, results []map[string]interface{}) []jsonld.Part {
+	if start, err := parser.ParseTimestamp(metadata["start_time"]); err == nil {
+		clip := jsonld.Part{
+			Position:   len(parts) + 1,
+			Properties: map[string]interface{}{"startOffset": start.Seconds()},
+			Nodes:      results,
+		}
+		if end, err := parser.ParseTimestamp(metadata["end_time"]); err == nil {
+			clip.Properties["endOffset"] = end.Seconds()
+		}
+		return append(parts, clip)
+	}
+
+	chapter := metadata["chapter"]
+	if len(parts) == 0 || parts[len(parts)-1].Name != chapter {
+		position := 0
+		if chapter != "" {
+			position = 1
+			for _, part := range parts {
+				if part.Name != "" {
+					position++
+				}
+			}
+		}
+		parts = append(parts, jsonld.Part{Name: chapter, Position: position})
+	}
+	parts[len(parts)-1].Nodes = append(parts[len(parts)-1].Nodes, results...)
+	return parts
+}
+
+// newSegmenter construit la stratégie de segmentation configurée, avec une taille
+// de segment compatible avec la limite du convertisseur et la fenêtre du modèle
+func newSegmenter(cfg *config.Config) (segmentation.Segmenter, error) {
+	maxTokens := cfg.Conversion.MaxTokens
+	if cfg.Segmentation.MaxTokens > 0 && cfg.Segmentation.MaxTokens < maxTokens {
+		maxTokens = cfg.Segmentation.MaxTokens
+	}
+	maxTokens = segmentSizeForModel(maxTokens, llm.ResolveCapabilities(cfg))
+
+	opts := segmentation.Options{
+		MaxTokens:           maxTokens,
+		Overlap:             cfg.Segmentation.Overlap,
+		TargetTokens:        cfg.Segmentation.TargetBatchSize,
+		SimilarityThreshold: cfg.Segmentation.SimilarityThreshold,
+	}
+	if cfg.Segmentation.Strategy == "semantic" {
+		embedder, err := llm.NewEmbedder(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("error creating embedder: %w", err)
+		}
+		opts.Embedder = embedder
+	}
+
+	return segmentation.NewSegmenter(cfg.Segmentation.Strategy, opts)
+}
+
+// maxResegmentDepth limite le nombre de redécoupages successifs d'un même segment
+const maxResegmentDepth = 3
+
+// segmentBudgetRatio est la part du budget d'entrée du modèle (fenêtre moins la
+// réponse réservée) accordée au segment : la moitié reste au prompt
+// (instructions, propriétés, exemples), et le tokenizer comptant des mots
+// (environ 4/3 de token chacun), cette moitié est ramenée à 3/8
+const segmentBudgetRatio = 3.0 / 8
+
+// segmentSizeForModel réduit la taille des segments si elle ne laisse pas assez de
+// place dans la fenêtre du modèle pour les instructions et la liste des propriétés.
+func segmentSizeForModel(maxTokens int, caps llm.ModelCapabilities) int {
+	limit := int(float64(caps.InputBudget()) * segmentBudgetRatio)
+	if limit > 0 && maxTokens > limit {
+		logger.Warning(fmt.Sprintf("Segment size %d exceeds what the model context window allows, using %d instead", maxTokens, limit))
+		return limit
+	}
+	return maxTokens
+}
+
+// convertSegment convertit un segment. Si le prompt ne tient pas dans la fenêtre du
+// modèle et que la stratégie de débordement est "resegment", le segment est redécoupé
+// en deux et chaque moitié est convertie séparément ; sinon l'erreur est retournée.
+func convertSegment(ctx context.Context, conv *jsonld.Converter, content string, metadata map[string]string, overflowStrategy string, depth int) ([]map[string]interface{}, error) {
+	jsonLD, err := conv.Convert(ctx, &parser.Document{
+		Content:  content,
+		Metadata: metadata,
+	})
+	if err == nil {
+		return []map[string]interface{}{jsonLD}, nil
+	}
+
+	var overflow *llm.ContextOverflowError
+	if !errors.As(err, &overflow) || overflowStrategy != "resegment" || depth >= maxResegmentDepth {
+		return nil, err
+	}
+
+	half := tokenizer.CountTokens(content) / 2
+	if half == 0 {
+		return nil, err
+	}
+	logger.Warning(fmt.Sprintf("Segment too large for model context (%v), re-segmenting into parts of %d tokens", overflow, half))
+
+	subSegments, serr := segmentation.SegmentDocument(&parser.Document{
+		Structure: []parser.DocumentElement{{Type: "paragraph", Content: content}},
+	}, half)
+	if serr != nil {
+		return nil, fmt.Errorf("error re-segmenting oversized segment: %w", serr)
+	}
+
+	var results []map[string]interface{}
+	for _, sub := range subSegments {
+		subResults, err := convertSegment(ctx, conv, sub.Content, metadata, overflowStrategy, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, subResults...)
+	}
+	return results, nil
+}
+
+// OptionsHash retourne l'empreinte des options et de la configuration qui
+// influent sur le résultat d'une conversion (moteur, modèle, instructions,
+// segmentation) ; les identifiants de connexion n'y figurent pas
+func (p *Pipeline) OptionsHash() string {
+	conversion := p.cfg.Conversion
+	conversion.APIKey, conversion.AIYOUEmail, conversion.AIYOUPassword = "", "", ""
+	conversion.Timeout, conversion.NumThreads, conversion.RequestsPerMinute = 0, 0, 0
+
+	data, _ := json.Marshal(struct {
+		InputFormat   string
+		Instructions  string
+		MappingFile   string
+		Conversion    interface{}
+		Segmentation  interface{}
+		SchemaVersion string
+		Examples      interface{}
+	}{p.opts.InputFormat, p.opts.Instructions, p.opts.MappingFile, conversion, p.cfg.Segmentation, p.cfg.Schema.Version, p.cfg.Examples})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Stats retourne les statistiques de la dernière conversion du pipeline ; un
+// pipeline partagé entre plusieurs conversions simultanées doit être dupliqué
+// avec WithOptions ou WithClient
+func (p *Pipeline) Stats() jsonld.Stats {
+	return p.stats
+}
+
+// Validate vérifie un graphe JSON-LD par rapport au vocabulaire Schema.org du
+// pipeline et retourne les problèmes relevés
+func (p *Pipeline) Validate(doc map[string]interface{}) []string {
+	return jsonld.Validate(p.schemaOrg, doc)
+}
+
+// Examples retourne la bibliothèque d'exemples du pipeline, nil si aucun
+// répertoire d'exemples n'est configuré
+func (p *Pipeline) Examples() *examples.Library {
+	return p.examples
+}
+
+// Client retourne le client LLM du pipeline
+func (p *Pipeline) Client() llm.LLMClient {
+	return p.client
+}
+
+// WithClient retourne un pipeline utilisant un autre client LLM (un client
+// limité en débit ou mesurant la consommation d'un fichier par exemple)
+func (p *Pipeline) WithClient(client llm.LLMClient) *Pipeline {
+	clone := *p
+	clone.client = client
+	return &clone
+}
+
+// Options retourne les options du pipeline
+func (p *Pipeline) Options() Options {
+	return p.opts
+}
+
+// WithOptions retourne un pipeline partageant le client LLM et le vocabulaire
+// Schema.org, avec d'autres options (une requête du serveur par exemple)
+func (p *Pipeline) WithOptions(opts Options) *Pipeline {
+	clone := *p
+	clone.opts = opts
+	return &clone
+}
@@ -0,0 +1,164 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chrlesur/json-ld-converter/internal/config"
+	"github.com/chrlesur/json-ld-converter/internal/jsonld"
+	"github.com/chrlesur/json-ld-converter/internal/llm"
+	"github.com/chrlesur/json-ld-converter/internal/schema"
+)
+
+func TestEmit(t *testing.T) {
+	var streamed []map[string]interface{}
+	p := &Pipeline{opts: Options{Stream: func(node map[string]interface{}) error {
+		streamed = append(streamed, node)
+		return nil
+	}}}
+	if err := p.emit(map[string]interface{}{"@type": "Article"}, map[string]interface{}{"@type": "Person"}); err != nil {
+		t.Fatalf("emit() error = %v", err)
+	}
+	if len(streamed) != 2 || streamed[1]["@type"] != "Person" {
+		t.Errorf("streamed = %v", streamed)
+	}
+
+	closed := errors.New("broken pipe")
+	p.opts.Stream = func(map[string]interface{}) error { return closed }
+	if err := p.emit(map[string]interface{}{}); !errors.Is(err, closed) {
+		t.Errorf("emit() error = %v, want %v", err, closed)
+	}
+	if err := (&Pipeline{}).emit(map[string]interface{}{}); err != nil {
+		t.Errorf("emit() without stream error = %v", err)
+	}
+}
+
+func TestStreamedDocumentNode(t *testing.T) {
+	metadata := map[string]string{"schema_type": "Book", "title": "Le Horla"}
+	parts := []jsonld.Part{
+		{Nodes: []map[string]interface{}{{"@type": "WebPage"}}},
+		{Name: "Chapitre 1", Position: 1, Nodes: []map[string]interface{}{{"@type": "Article"}}},
+	}
+
+	node := streamedDocumentNode(metadata, parts)
+	if node["@type"] != "Book" || node["name"] != "Le Horla" {
+		t.Errorf("node = %v", node)
+	}
+	hasPart, _ := node["hasPart"].([]map[string]interface{})
+	if len(hasPart) != 1 || hasPart[0]["name"] != "Chapitre 1" || hasPart[0]["position"] != 1 {
+		t.Fatalf("hasPart = %v", node["hasPart"])
+	}
+	if _, ok := hasPart[0]["hasPart"]; ok {
+		t.Error("streamed parts should not repeat their nodes")
+	}
+	if len(parts[1].Nodes) != 1 {
+		t.Error("parts should not be modified")
+	}
+}
+
+func TestSegmentSizeForModel(t *testing.T) {
+	small := llm.ModelCapabilities{ContextWindow: 8192, MaxOutputTokens: 2048}
+	large := llm.ModelCapabilities{ContextWindow: 200000, MaxOutputTokens: 8192}
+
+	if got := segmentSizeForModel(4000, small); got != 2304 {
+		t.Errorf("segmentSizeForModel(4000, 8k context) = %d, want 2304", got)
+	}
+	if got := segmentSizeForModel(4000, large); got != 4000 {
+		t.Errorf("segmentSizeForModel(4000, 200k context) = %d, want 4000", got)
+	}
+	if got := segmentSizeForModel(4000, llm.ModelCapabilities{}); got != 4000 {
+		t.Errorf("segmentSizeForModel() with unknown capabilities = %d, want 4000", got)
+	}
+}
+
+// recordingClient conserve les prompts et le nombre de segments du contexte reçu
+// à chaque requête ; les requêtes échouent à partir du segment failFrom s'il est
+// positif
+type recordingClient struct {
+	prompts  []string
+	segments []int
+	failFrom int
+}
+
+func (c *recordingClient) Analyze(ctx context.Context, content string, ac *llm.AnalysisContext) (string, *llm.AnalysisContext, error) {
+	if c.failFrom > 0 && ac.Segment >= c.failFrom {
+		return "", ac, errors.New("LLM unavailable")
+	}
+	c.prompts = append(c.prompts, content)
+	c.segments = append(c.segments, ac.Segment)
+	return `{"@type": "Thing", "name": "Segment"}`, ac, nil
+}
+
+func TestConvertContextFileResumes(t *testing.T) {
+	schemaOrg, err := schema.LoadSchemaOrg("../schema/testdata/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Conversion.MaxTokens = 8
+	contextFile := filepath.Join(t.TempDir(), "context.json")
+
+	run := func(client *recordingClient) (map[string]interface{}, error) {
+		p := &Pipeline{cfg: cfg, client: client, schemaOrg: schemaOrg, opts: Options{InputFormat: "text", ContextFile: contextFile}}
+		docs, err := p.Parse(strings.NewReader("Premier paragraphe du document.\n\nSecond paragraphe, un peu plus long que le premier."), "notes.txt")
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		return p.Convert(context.Background(), docs)
+	}
+	contextSegments := func() int {
+		ac, err := llm.LoadAnalysisContext(contextFile)
+		if err != nil {
+			t.Fatalf("LoadAnalysisContext() error = %v", err)
+		}
+		return ac.Segment
+	}
+
+	// Interruption au second segment
+	if _, err := run(&recordingClient{failFrom: 1}); err == nil {
+		t.Fatal("Convert() should fail on the second segment")
+	}
+	if n := contextSegments(); n != 1 {
+		t.Fatalf("Context after the interrupted run covers %d segments, want 1", n)
+	}
+
+	// La reprise ne reconvertit pas le premier segment et repart de son contexte
+	client := &recordingClient{}
+	result, err := run(client)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	prompts := strings.Join(client.prompts, "\n")
+	if strings.Contains(prompts, "Premier paragraphe") || !strings.Contains(prompts, "plus long que le premier") {
+		t.Errorf("Resumed run should only convert the second segment:\n%s", prompts)
+	}
+	if len(client.segments) == 0 || client.segments[0] != 1 {
+		t.Errorf("Resumed run should start from the saved context, got segments %v", client.segments)
+	}
+	if nodes, _ := result["@graph"].([]map[string]interface{}); len(nodes) != 2 {
+		t.Errorf("Resumed result = %v, want the nodes of both segments", result)
+	}
+	if n := contextSegments(); n != 2 {
+		t.Errorf("Context after the resumed run covers %d segments, want 2", n)
+	}
+	if _, err := os.Stat(contextFile + ".checkpoint"); !os.IsNotExist(err) {
+		t.Errorf("The checkpoint should be removed once the conversion completes: %v", err)
+	}
+
+	// Une nouvelle conversion complète repart d'un contexte vide : le contexte
+	// enregistré n'est pas enrichi une seconde fois des mêmes segments
+	client = &recordingClient{}
+	if _, err := run(client); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if len(client.segments) == 0 || client.segments[0] != 0 {
+		t.Errorf("A new conversion should start from an empty context, got segments %v", client.segments)
+	}
+	if n := contextSegments(); n != 2 {
+		t.Errorf("Context after a second complete run covers %d segments, want 2", n)
+	}
+}
@@ -0,0 +1,143 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/chrlesur/json-ld-converter/internal/jsonld"
+	"github.com/chrlesur/json-ld-converter/internal/parser"
+	"github.com/chrlesur/json-ld-converter/internal/segmentation"
+)
+
+// SessionSegment est un segment d'un document chargé dans une session, avec les
+// nœuds JSON-LD produits par sa dernière conversion. Les tableaux d'un document
+// tabulaire forment un seul segment.
+type SessionSegment struct {
+	segmentation.Segment
+	Document  int // indice du document dans le fichier (message d'une boîte mbox)
+	Converted bool
+	Results   []map[string]interface{}
+}
+
+// Session garde un fichier analysé et segmenté entre plusieurs commandes du mode
+// interactif : les segments peuvent être convertis ensemble ou un par un, et le
+// convertisseur conserve son contexte d'analyse d'une conversion à l'autre
+type Session struct {
+	Name     string
+	Segments []*SessionSegment
+
+	p    *Pipeline
+	conv *jsonld.Converter
+	docs []*parser.Document
+}
+
+// NewSession analyse et segmente le contenu d'un flux (name sert à la détection
+// du format) sans le convertir
+func (p *Pipeline) NewSession(ctx context.Context, r io.Reader, name string) (*Session, error) {
+	docs, err := p.Parse(r, name)
+	if err != nil {
+		return nil, err
+	}
+	segmenter, err := newSegmenter(p.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating segmenter: %w", err)
+	}
+
+	s := &Session{Name: name, p: p, conv: p.newConverter(p.opts.Instructions), docs: docs}
+	for i, doc := range docs {
+		s.conv.SeedStructuredData(doc.StructuredData)
+		if doc.Metadata["tabular"] == "true" {
+			s.Segments = append(s.Segments, &SessionSegment{
+				Segment:  segmentation.Segment{Content: doc.Content, Metadata: map[string]string{"tabular": "true"}},
+				Document: i,
+			})
+			continue
+		}
+		segments, err := segmenter.Segment(ctx, doc)
+		if err != nil {
+			return nil, &jsonld.ConversionError{Stage: "segmentation", Err: err}
+		}
+		for _, segment := range segments {
+			s.Segments = append(s.Segments, &SessionSegment{Segment: segment, Document: i})
+		}
+	}
+	return s, nil
+}
+
+// WithPipeline fait convertir les segments suivants par un autre pipeline (après
+// un changement de moteur ou de modèle) ; le contexte d'analyse est conservé
+func (s *Session) WithPipeline(p *Pipeline) {
+	ac := s.conv.AnalysisContext()
+	s.p = p
+	s.conv = p.newConverter(p.opts.Instructions)
+	s.conv.SetAnalysisContext(ac)
+}
+
+// ConvertSegment convertit (ou reconvertit) le segment d'indice i. Les
+// instructions s'ajoutent à celles des options pour cette seule conversion.
+func (s *Session) ConvertSegment(ctx context.Context, i int, instructions string) error {
+	if i < 0 || i >= len(s.Segments) {
+		return fmt.Errorf("segment %d does not exist (%d segments)", i+1, len(s.Segments))
+	}
+	segment := s.Segments[i]
+	doc := s.docs[segment.Document]
+
+	conv := s.conv
+	if instructions != "" {
+		conv = s.p.newConverter(strings.TrimSpace(s.p.opts.Instructions + "\n" + instructions))
+		conv.SetAnalysisContext(s.conv.AnalysisContext())
+	}
+
+	var results []map[string]interface{}
+	var err error
+	if segment.Metadata["tabular"] == "true" {
+		results, err = s.p.convertTables(ctx, conv, doc)
+	} else {
+		results, err = convertSegment(ctx, conv, segment.Content, segmentMetadata(doc, segment.Segment), s.p.cfg.Conversion.OverflowStrategy, 0)
+	}
+	if err != nil {
+		return fmt.Errorf("error converting segment %d to JSON-LD: %w", i+1, err)
+	}
+	segment.Results = results
+	segment.Converted = true
+	return nil
+}
+
+// Metadata retourne les métadonnées du document d'un segment, complétées par
+// celles du segment préfixées par "segment_"
+func (s *Session) Metadata(i int) map[string]string {
+	segment := s.Segments[i]
+	return segmentMetadata(s.docs[segment.Document], segment.Segment)
+}
+
+// Result assemble le graphe JSON-LD des segments convertis, comme le ferait une
+// conversion complète du fichier
+func (s *Session) Result() map[string]interface{} {
+	var allResults []map[string]interface{}
+	for i, doc := range s.docs {
+		var results []map[string]interface{}
+		var parts []jsonld.Part
+		for _, segment := range s.Segments {
+			if segment.Document != i {
+				continue
+			}
+			results = append(results, segment.Results...)
+			parts = addToPart(parts, segment.Metadata, segment.Results)
+		}
+		if doc.Metadata["tabular"] == "true" {
+			allResults = append(allResults, results...)
+			continue
+		}
+		if doc.Metadata["schema_type"] != "" {
+			results = []map[string]interface{}{jsonld.DocumentNode(doc.Metadata, parts)}
+		}
+		allResults = append(allResults, jsonld.MergeStructuredData(doc.StructuredData, results)...)
+	}
+
+	if len(s.docs) > 1 && s.docs[0].Metadata["schema_type"] == "EmailMessage" {
+		allResults = jsonld.EmailThreads(s.docs, allResults)
+	}
+	return graph(allResults)
+}
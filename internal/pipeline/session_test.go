@@ -0,0 +1,35 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chrlesur/json-ld-converter/internal/config"
+	"github.com/chrlesur/json-ld-converter/internal/schema"
+)
+
+func TestSession(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Conversion.MaxTokens = 8
+	p := &Pipeline{cfg: cfg, schemaOrg: &schema.SchemaOrg{}, opts: Options{InputFormat: "text"}}
+
+	s, err := p.NewSession(context.Background(), strings.NewReader("Premier paragraphe du document.\n\nSecond paragraphe, un peu plus long que le premier."), "notes.txt")
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	if len(s.Segments) < 2 {
+		t.Fatalf("Expected several segments, got %d", len(s.Segments))
+	}
+	if err := s.ConvertSegment(context.Background(), len(s.Segments), ""); err == nil {
+		t.Error("ConvertSegment() should fail for a missing segment")
+	}
+
+	s.Segments[1].Results = []map[string]interface{}{{"@type": "Article", "name": "Second"}}
+	s.Segments[1].Converted = true
+	result := s.Result()
+	nodes, _ := result["@graph"].([]map[string]interface{})
+	if result["@context"] != "https://schema.org" || len(nodes) != 1 || nodes[0]["name"] != "Second" {
+		t.Errorf("Result() = %v", result)
+	}
+}
@@ -0,0 +1,335 @@
+package review
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chrlesur/json-ld-converter/internal/examples"
+)
+
+// Status est l'état d'un nœud ou d'une propriété soumis à relecture
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+)
+
+// Actions d'une décision de relecture
+const (
+	ActionApprove = "approve"
+	ActionReject  = "reject"
+	ActionEdit    = "edit"
+)
+
+// Segment est l'extrait du document dont proviennent des nœuds
+type Segment struct {
+	Number    int    `json:"number"`
+	Content   string `json:"content"`
+	Language  string `json:"language,omitempty"`
+	Chapter   string `json:"chapter,omitempty"`
+	StartTime string `json:"start_time,omitempty"`
+}
+
+// Property est une propriété d'un nœud produite par le LLM ; Original garde la
+// valeur produite lorsqu'elle a été corrigée, et Added signale une propriété
+// ajoutée par un relecteur
+type Property struct {
+	Name     string      `json:"name"`
+	Value    interface{} `json:"value"`
+	Original interface{} `json:"original,omitempty"`
+	Added    bool        `json:"added,omitempty"`
+	Status   Status      `json:"status"`
+}
+
+// Node est un nœud JSON-LD soumis à relecture, avec le segment dont il provient.
+// Type est le ou les types du nœud ; Keywords conserve ses autres mots-clés
+// JSON-LD (@id).
+type Node struct {
+	ID           string                 `json:"id"`
+	Type         []string               `json:"type"`
+	OriginalType []string               `json:"original_type,omitempty"`
+	Segment      int                    `json:"segment"`
+	Status       Status                 `json:"status"`
+	Keywords     map[string]interface{} `json:"keywords,omitempty"`
+	Properties   []*Property            `json:"properties"`
+}
+
+// Decision est une décision d'un relecteur sur un nœud ou, si Property est
+// renseigné, sur une de ses propriétés. Modifier "@type" change le type du nœud ;
+// modifier une propriété absente l'ajoute.
+type Decision struct {
+	Node     string      `json:"node"`
+	Property string      `json:"property,omitempty"`
+	Action   string      `json:"action"`
+	Value    interface{} `json:"value,omitempty"`
+	Reviewer string      `json:"reviewer,omitempty"`
+	Comment  string      `json:"comment,omitempty"`
+	At       time.Time   `json:"at"`
+}
+
+// Review est une conversion soumise à relecture : ses nœuds, les segments dont
+// ils proviennent et l'historique des décisions
+type Review struct {
+	ID        string     `json:"id"`
+	Source    string     `json:"source"`
+	CreatedAt time.Time  `json:"created_at"`
+	Segments  []Segment  `json:"segments"`
+	Nodes     []*Node    `json:"nodes"`
+	Decisions []Decision `json:"decisions"`
+}
+
+// New crée une relecture à partir des nœuds produits pour chaque segment
+// (results[i] pour segments[i]) ; tous les éléments sont en attente
+func New(source string, segments []Segment, results [][]map[string]interface{}) *Review {
+	r := &Review{ID: newID(), Source: source, CreatedAt: time.Now(), Segments: segments}
+	for i, nodes := range results {
+		for _, node := range nodes {
+			r.Nodes = append(r.Nodes, newNode(fmt.Sprintf("n%d", len(r.Nodes)+1), segments[i].Number, node))
+		}
+	}
+	return r
+}
+
+// newNode décompose un nœud JSON-LD en propriétés relisibles, triées par nom
+func newNode(id string, segment int, node map[string]interface{}) *Node {
+	n := &Node{ID: id, Type: typeNames(node["@type"]), Segment: segment, Status: StatusPending}
+
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch {
+		case key == "@type":
+		case strings.HasPrefix(key, "@"):
+			if n.Keywords == nil {
+				n.Keywords = make(map[string]interface{})
+			}
+			n.Keywords[key] = node[key]
+		default:
+			n.Properties = append(n.Properties, &Property{Name: key, Value: node[key], Status: StatusPending})
+		}
+	}
+	return n
+}
+
+// Node retourne le nœud d'identifiant id
+func (r *Review) Node(id string) (*Node, bool) {
+	for _, n := range r.Nodes {
+		if n.ID == id {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// Apply applique une décision et l'ajoute à l'historique. Corriger un nœud ou
+// une propriété les approuve.
+func (r *Review) Apply(d Decision) error {
+	n, ok := r.Node(d.Node)
+	if !ok {
+		return fmt.Errorf("unknown node %q", d.Node)
+	}
+	if d.At.IsZero() {
+		d.At = time.Now()
+	}
+
+	switch {
+	case d.Property == "":
+		switch d.Action {
+		case ActionApprove:
+			n.Status = StatusApproved
+		case ActionReject:
+			n.Status = StatusRejected
+		default:
+			return fmt.Errorf("invalid action %q for a node", d.Action)
+		}
+	case d.Property == "@type":
+		if d.Action != ActionEdit {
+			return fmt.Errorf("invalid action %q for @type", d.Action)
+		}
+		types := typeNames(d.Value)
+		if len(types) == 0 {
+			return fmt.Errorf("@type must be a type name or a list of type names")
+		}
+		if n.OriginalType == nil {
+			n.OriginalType = n.Type
+		}
+		n.Type, n.Status = types, StatusApproved
+	case strings.HasPrefix(d.Property, "@"):
+		return fmt.Errorf("keyword %s cannot be reviewed", d.Property)
+	default:
+		if err := n.applyProperty(d); err != nil {
+			return err
+		}
+	}
+
+	r.Decisions = append(r.Decisions, d)
+	return nil
+}
+
+// applyProperty applique une décision portant sur une propriété du nœud
+func (n *Node) applyProperty(d Decision) error {
+	var property *Property
+	for _, p := range n.Properties {
+		if p.Name == d.Property {
+			property = p
+		}
+	}
+
+	switch d.Action {
+	case ActionApprove, ActionReject:
+		if property == nil {
+			return fmt.Errorf("unknown property %q of node %s", d.Property, n.ID)
+		}
+		property.Status = StatusApproved
+		if d.Action == ActionReject {
+			property.Status = StatusRejected
+		}
+	case ActionEdit:
+		if d.Value == nil {
+			return fmt.Errorf("missing value for property %q", d.Property)
+		}
+		if property == nil {
+			n.Properties = append(n.Properties, &Property{Name: d.Property, Value: d.Value, Added: true, Status: StatusApproved})
+			return nil
+		}
+		if property.Original == nil && !property.Added {
+			property.Original = property.Value
+		}
+		property.Value, property.Status = d.Value, StatusApproved
+	default:
+		return fmt.Errorf("invalid action %q for a property", d.Action)
+	}
+	return nil
+}
+
+// Export retourne le graphe des seules données approuvées : les nœuds approuvés,
+// avec leurs propriétés approuvées ou encore en attente (l'approbation du nœud
+// vaut pour elles) ; les propriétés rejetées sont retirées
+func (r *Review) Export() map[string]interface{} {
+	graph := []map[string]interface{}{}
+	for _, n := range r.Nodes {
+		if n.Status == StatusApproved {
+			graph = append(graph, n.jsonLD())
+		}
+	}
+	return map[string]interface{}{
+		"@context": "https://schema.org",
+		"@graph":   graph,
+	}
+}
+
+// jsonLD reconstitue le nœud JSON-LD sans ses propriétés rejetées
+func (n *Node) jsonLD() map[string]interface{} {
+	node := make(map[string]interface{}, len(n.Properties)+len(n.Keywords)+1)
+	for key, value := range n.Keywords {
+		node[key] = value
+	}
+	if len(n.Type) == 1 {
+		node["@type"] = n.Type[0]
+	} else if len(n.Type) > 1 {
+		node["@type"] = n.Type
+	}
+	for _, p := range n.Properties {
+		if p.Status != StatusRejected {
+			node[p.Name] = p.Value
+		}
+	}
+	return node
+}
+
+// corrected indique si un relecteur a modifié le nœud (type, valeur, ajout ou
+// rejet d'une propriété)
+func (n *Node) corrected() bool {
+	if n.OriginalType != nil {
+		return true
+	}
+	for _, p := range n.Properties {
+		if p.Original != nil || p.Added || p.Status == StatusRejected {
+			return true
+		}
+	}
+	return false
+}
+
+// Examples retourne les nœuds approuvés après correction sous forme d'exemples :
+// l'extrait du segment d'origine associé au nœud corrigé
+func (r *Review) Examples() []examples.Example {
+	var result []examples.Example
+	for _, n := range r.Nodes {
+		if n.Status != StatusApproved || !n.corrected() || len(n.Type) == 0 || n.Segment < 1 || n.Segment > len(r.Segments) {
+			continue
+		}
+		segment := r.Segments[n.Segment-1]
+		result = append(result, examples.Example{
+			Type:     n.Type[0],
+			Language: segment.Language,
+			Input:    segment.Content,
+			Output:   n.jsonLD(),
+		})
+	}
+	return result
+}
+
+// Summary résume l'avancement d'une relecture
+type Summary struct {
+	ID        string    `json:"id"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+	Nodes     int       `json:"nodes"`
+	Pending   int       `json:"pending"`
+	Approved  int       `json:"approved"`
+	Rejected  int       `json:"rejected"`
+}
+
+// Summary retourne l'avancement de la relecture
+func (r *Review) Summary() Summary {
+	s := Summary{ID: r.ID, Source: r.Source, CreatedAt: r.CreatedAt, Nodes: len(r.Nodes)}
+	for _, n := range r.Nodes {
+		switch n.Status {
+		case StatusApproved:
+			s.Approved++
+		case StatusRejected:
+			s.Rejected++
+		default:
+			s.Pending++
+		}
+	}
+	return s
+}
+
+// typeNames retourne le ou les noms de type d'une valeur de @type
+func typeNames(value interface{}) []string {
+	switch t := value.(type) {
+	case string:
+		if t != "" {
+			return []string{t}
+		}
+	case []string:
+		return t
+	case []interface{}:
+		var types []string
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "" {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// newID retourne un identifiant de relecture horodaté
+func newID() string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
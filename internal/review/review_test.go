@@ -0,0 +1,123 @@
+package review
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func newTestReview() *Review {
+	segments := []Segment{{Number: 1, Content: "Marie Curie est née à Varsovie.", Language: "fr"}}
+	results := [][]map[string]interface{}{{
+		{"@type": "Person", "@id": "#marie", "name": "Marie Curie", "birthPlace": "Paris", "jobTitle": "Reine"},
+		{"@type": []interface{}{"Place", "City"}, "name": "Varsovie"},
+	}}
+	return New("curie.txt", segments, results)
+}
+
+func TestReviewApply(t *testing.T) {
+	r := newTestReview()
+	if len(r.Nodes) != 2 || r.Nodes[0].Keywords["@id"] != "#marie" || len(r.Nodes[0].Properties) != 3 {
+		t.Fatalf("Unexpected review nodes: %+v", r.Nodes[0])
+	}
+
+	decisions := []Decision{
+		{Node: "n1", Property: "birthPlace", Action: ActionEdit, Value: "Varsovie", Reviewer: "anne"},
+		{Node: "n1", Property: "jobTitle", Action: ActionReject},
+		{Node: "n1", Property: "nationality", Action: ActionEdit, Value: "polonaise"},
+		{Node: "n1", Action: ActionApprove},
+		{Node: "n2", Action: ActionReject},
+	}
+	for _, d := range decisions {
+		if err := r.Apply(d); err != nil {
+			t.Fatalf("Apply(%+v) error = %v", d, err)
+		}
+	}
+	if len(r.Decisions) != len(decisions) || r.Decisions[0].At.IsZero() {
+		t.Errorf("Decisions should be recorded with their time: %+v", r.Decisions)
+	}
+
+	for _, d := range []Decision{
+		{Node: "n9", Action: ActionApprove},
+		{Node: "n1", Action: ActionEdit},
+		{Node: "n1", Property: "unknown", Action: ActionReject},
+		{Node: "n1", Property: "@id", Action: ActionEdit, Value: "#x"},
+		{Node: "n1", Property: "@type", Action: ActionEdit, Value: 3},
+	} {
+		if err := r.Apply(d); err == nil {
+			t.Errorf("Apply(%+v) should fail", d)
+		}
+	}
+
+	want := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@graph": []map[string]interface{}{{
+			"@id": "#marie", "@type": "Person", "name": "Marie Curie", "birthPlace": "Varsovie", "nationality": "polonaise",
+		}},
+	}
+	if got := r.Export(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Export() = %v, want %v", got, want)
+	}
+
+	examples := r.Examples()
+	if len(examples) != 1 || examples[0].Type != "Person" || examples[0].Language != "fr" || examples[0].Output["birthPlace"] != "Varsovie" {
+		t.Errorf("Examples() = %+v", examples)
+	}
+
+	if s := r.Summary(); s.Approved != 1 || s.Rejected != 1 || s.Pending != 0 {
+		t.Errorf("Summary() = %+v", s)
+	}
+}
+
+func TestReviewEditType(t *testing.T) {
+	r := newTestReview()
+	if err := r.Apply(Decision{Node: "n2", Property: "@type", Action: ActionEdit, Value: []interface{}{"City"}}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	n := r.Nodes[1]
+	if n.Status != StatusApproved || !reflect.DeepEqual(n.Type, []string{"City"}) || !reflect.DeepEqual(n.OriginalType, []string{"Place", "City"}) {
+		t.Errorf("Unexpected node after type change: %+v", n)
+	}
+	if len(r.Examples()) != 1 {
+		t.Error("A node whose type was corrected should become an example")
+	}
+}
+
+func TestStore(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	r := newTestReview()
+	if err := store.Save(r); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if _, err := store.Update(r.ID, func(r *Review) error {
+		return r.Apply(Decision{Node: "n1", Action: ActionApprove})
+	}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := store.Update(r.ID, func(r *Review) error {
+		r.Nodes[1].Status = StatusApproved
+		return r.Apply(Decision{Node: "n9", Action: ActionApprove})
+	}); err == nil {
+		t.Error("Update() should fail for an invalid decision")
+	}
+
+	loaded, err := store.Load(r.ID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Nodes[0].Status != StatusApproved || loaded.Nodes[1].Status != StatusPending {
+		t.Errorf("Only valid updates should be saved: %+v %+v", loaded.Nodes[0], loaded.Nodes[1])
+	}
+
+	summaries, err := store.List()
+	if err != nil || len(summaries) != 1 || summaries[0].Approved != 1 {
+		t.Errorf("List() = %+v, %v", summaries, err)
+	}
+	if _, err := store.Load("../etc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() with an invalid id error = %v, want ErrNotFound", err)
+	}
+}
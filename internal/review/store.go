@@ -0,0 +1,129 @@
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound signale une relecture absente du stock
+var ErrNotFound = errors.New("review not found")
+
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Store conserve les relectures dans un répertoire, un fichier JSON par relecture
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewStore ouvre le stock de relectures du répertoire, créé s'il n'existe pas
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating review directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Save enregistre une relecture
+func (s *Store) Save(r *Review) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save(r)
+}
+
+// Load lit une relecture
+func (s *Store) Load(id string) (*Review, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(id)
+}
+
+// Update applique une modification à une relecture et l'enregistre ; la
+// relecture n'est pas enregistrée si la modification échoue
+func (s *Store) Update(id string, update func(*Review) error) (*Review, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := update(r); err != nil {
+		return nil, err
+	}
+	if err := s.save(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// List résume les relectures du stock, de la plus récente à la plus ancienne
+func (s *Store) List() ([]Summary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading review directory: %w", err)
+	}
+	summaries := []Summary{}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		r, err := s.load(id)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, r.Summary())
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].CreatedAt.After(summaries[j].CreatedAt)
+	})
+	return summaries, nil
+}
+
+func (s *Store) load(id string) (*Review, error) {
+	if !validID.MatchString(id) {
+		return nil, ErrNotFound
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, id+".json"))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading review %s: %w", id, err)
+	}
+	var r Review
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("error parsing review %s: %w", id, err)
+	}
+	return &r, nil
+}
+
+// save écrit la relecture dans un fichier temporaire renommé ensuite, pour
+// qu'une interruption ne laisse pas de fichier tronqué
+func (s *Store) save(r *Review) error {
+	if !validID.MatchString(r.ID) {
+		return fmt.Errorf("invalid review id %q", r.ID)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling review: %w", err)
+	}
+	path := filepath.Join(s.dir, r.ID+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("error writing review: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("error writing review: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"sort"
 	"strings"
 
 	"github.com/chrlesur/json-ld-converter/internal/logger"
@@ -69,25 +70,36 @@ func LoadSchemaOrg(filePath string) (*SchemaOrg, error) {
 			continue
 		}
 
-		itemType, ok := itemMap["@type"].(string)
-		if !ok {
+		// @type est une liste pour les types de données (["schema:DataType", "rdfs:Class"])
+		itemTypes := getIDs(itemMap, "@type")
+		if len(itemTypes) == 0 {
 			continue
 		}
+		itemType := itemTypes[0]
+		for _, t := range itemTypes {
+			if t == "rdfs:Class" {
+				itemType = t
+			}
+		}
 
 		switch itemType {
 		case "rdfs:Class":
 			schemaType := SchemaType{
-				ID:      id,
-				Label:   getStringValue(itemMap, "rdfs:label"),
-				Comment: getStringValue(itemMap, "rdfs:comment"),
+				ID:         id,
+				Label:      getStringValue(itemMap, "rdfs:label"),
+				Comment:    getStringValue(itemMap, "rdfs:comment"),
+				SubClassOf: getIDs(itemMap, "rdfs:subClassOf"),
+				IsPartOf:   strings.Join(getIDs(itemMap, "schema:isPartOf"), " "),
 			}
 			schema.Types[id] = schemaType
 			logger.Debug(fmt.Sprintf("Loaded schema type: %s", id))
 		case "rdf:Property":
 			schemaProperty := SchemaProperty{
-				ID:      id,
-				Label:   getStringValue(itemMap, "rdfs:label"),
-				Comment: getStringValue(itemMap, "rdfs:comment"),
+				ID:             id,
+				Label:          getStringValue(itemMap, "rdfs:label"),
+				Comment:        getStringValue(itemMap, "rdfs:comment"),
+				DomainIncludes: getIDs(itemMap, "schema:domainIncludes"),
+				RangeIncludes:  getIDs(itemMap, "schema:rangeIncludes"),
 			}
 			schema.Properties[id] = schemaProperty
 			logger.Debug(fmt.Sprintf("Loaded schema property: %s", id))
@@ -106,6 +118,21 @@ func LoadSchemaOrg(filePath string) (*SchemaOrg, error) {
 		return nil, fmt.Errorf("no types loaded from Schema.org")
 	}
 
+	// Chaque type reçoit les propriétés dont il fait partie du domaine
+	propertyIDs := make([]string, 0, len(schema.Properties))
+	for id := range schema.Properties {
+		propertyIDs = append(propertyIDs, id)
+	}
+	sort.Strings(propertyIDs)
+	for _, id := range propertyIDs {
+		for _, domain := range schema.Properties[id].DomainIncludes {
+			if t, ok := schema.Types[domain]; ok {
+				t.Properties = append(t.Properties, id)
+				schema.Types[domain] = t
+			}
+		}
+	}
+
 	logger.Info(fmt.Sprintf("Loaded %d types and %d properties from Schema.org", len(schema.Types), len(schema.Properties)))
 
 	return schema, nil
@@ -118,6 +145,27 @@ func getStringValue(m map[string]interface{}, key string) string {
 	return ""
 }
 
+// getIDs retourne les identifiants d'une valeur JSON-LD : une chaîne, une
+// référence {"@id": ...} ou une liste de l'une ou l'autre
+func getIDs(m map[string]interface{}, key string) []string {
+	var ids []string
+	values, ok := m[key].([]interface{})
+	if !ok {
+		values = []interface{}{m[key]}
+	}
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			ids = append(ids, v)
+		case map[string]interface{}:
+			if id, ok := v["@id"].(string); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
 func (s *SchemaOrg) GetType(typeName string) (SchemaType, bool) {
 	// Try with "schema:" prefix
 	if t, ok := s.Types["schema:"+typeName]; ok {
@@ -181,3 +229,44 @@ func (s *SchemaOrg) SuggestProperties(typeName string, content string) []string
 	logger.Info(fmt.Sprintf("Suggested %d properties for type %s", len(suggestedProperties), typeName))
 	return suggestedProperties
 }
+
+// Subtypes retourne les sous-types directs d'un type, triés par nom. Les types
+// encore en discussion (pending.schema.org) sont écartés.
+func (s *SchemaOrg) Subtypes(typeName string) []SchemaType {
+	id := "schema:" + strings.TrimPrefix(typeName, "schema:")
+	var subtypes []SchemaType
+	for _, t := range s.Types {
+		if strings.Contains(t.IsPartOf, "pending.schema.org") {
+			continue
+		}
+		for _, parent := range t.SubClassOf {
+			if parent == id {
+				subtypes = append(subtypes, t)
+				break
+			}
+		}
+	}
+	sort.Slice(subtypes, func(i, j int) bool { return subtypes[i].ID < subtypes[j].ID })
+	return subtypes
+}
+
+// IsSubtypeOf indique si typeName est ancestor ou l'un de ses descendants
+func (s *SchemaOrg) IsSubtypeOf(typeName, ancestor string) bool {
+	id := "schema:" + strings.TrimPrefix(typeName, "schema:")
+	target := "schema:" + strings.TrimPrefix(ancestor, "schema:")
+	seen := make(map[string]bool)
+	queue := []string{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == target {
+			return true
+		}
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		queue = append(queue, s.Types[current].SubClassOf...)
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -35,8 +36,43 @@ func TestLoadSchemaOrg(t *testing.T) {
 	}
 
 	// Test SuggestProperties
-	suggestedProps := schema.SuggestProperties("Person", "John Doe is 30 years old")
+	suggestedProps := schema.SuggestProperties("Person", "John Doe is 30 years old, his birthDate is 1994-05-12")
 	if len(suggestedProps) == 0 {
 		t.Error("No properties suggested for Person")
+	} else if strings.Join(suggestedProps, ",") != "schema:birthDate" {
+		t.Errorf("Suggested properties for Person = %v, want only the property named in the content", suggestedProps)
+	}
+}
+
+func TestSubtypes(t *testing.T) {
+	schema, err := LoadSchemaOrg("testdata/schema.json")
+	if err != nil {
+		t.Fatalf("Failed to load Schema.org: %v", err)
+	}
+
+	var names []string
+	for _, subtype := range schema.Subtypes("Thing") {
+		names = append(names, subtype.Label)
+	}
+	// Taxon (pending.schema.org) est écarté
+	want := []string{"CreativeWork", "Event", "Intangible", "Organization", "Person", "Place", "Product"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("Subtypes(Thing) = %v, want %v", names, want)
+	}
+	if subtypes := schema.Subtypes("schema:Place"); len(subtypes) != 1 || subtypes[0].Label != "LocalBusiness" {
+		t.Errorf("Subtypes(Place) = %v", subtypes)
+	}
+	if person, _ := schema.GetType("Person"); strings.Join(person.Properties, ",") != "schema:birthDate,schema:givenName" {
+		t.Errorf("Person properties = %v, want the properties whose domain includes Person", person.Properties)
+	}
+	if _, ok := schema.GetType("Text"); !ok {
+		t.Error("Data types should be loaded")
+	}
+
+	if !schema.IsSubtypeOf("NewsArticle", "CreativeWork") || !schema.IsSubtypeOf("LocalBusiness", "Place") || !schema.IsSubtypeOf("Person", "Person") {
+		t.Error("IsSubtypeOf() should follow the class hierarchy")
+	}
+	if schema.IsSubtypeOf("Person", "CreativeWork") {
+		t.Error("Person is not a CreativeWork")
 	}
 }
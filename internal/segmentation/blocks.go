@@ -0,0 +1,77 @@
+package segmentation
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/chrlesur/json-ld-converter/internal/parser"
+	"github.com/chrlesur/json-ld-converter/pkg/tokenizer"
+)
+
+// blocks aplatit l'arbre du document en blocs de texte, chacun accompagné du
+// chemin des titres sous lesquels il se trouve, de sa page et de son chapitre
+func blocks(doc *parser.Document) []unit {
+	var result []unit
+	var path []string
+	var levels []int
+	offset := 0
+	pageCount := 0
+
+	var walk func(elements []parser.DocumentElement, page, chapter string)
+	walk = func(elements []parser.DocumentElement, page, chapter string) {
+		for _, el := range elements {
+			if el.Type == "page" {
+				pageCount++
+				page = el.Attributes["number"]
+				if page == "" {
+					page = strconv.Itoa(pageCount)
+				}
+			}
+			if el.Type == "chapter" && el.Attributes["title"] != "" {
+				chapter = el.Attributes["title"]
+			}
+
+			if level, ok := el.HeadingLevel(); ok {
+				for len(levels) > 0 && levels[len(levels)-1] >= level {
+					levels = levels[:len(levels)-1]
+					path = path[:len(path)-1]
+				}
+				levels = append(levels, level)
+				path = append(path, strings.TrimSpace(el.Content))
+			}
+
+			if !el.IsTextBlock() {
+				walk(el.Children, page, chapter)
+				continue
+			}
+
+			text := strings.TrimSpace(el.Content)
+			if text == "" {
+				continue
+			}
+			tokens := tokenizer.CountTokens(text)
+			result = append(result, unit{
+				text:    text,
+				path:    append([]string(nil), path...),
+				page:    page,
+				chapter: chapter,
+				start:   el.Attributes["start"],
+				end:     el.Attributes["end"],
+				tokens:  tokens,
+				offset:  offset,
+			})
+			offset += tokens
+		}
+	}
+	walk(doc.Structure, "", "")
+
+	// Document sans structure : on se rabat sur le contenu brut
+	if len(result) == 0 && strings.TrimSpace(doc.Content) != "" {
+		result = append(result, unit{
+			text:   strings.TrimSpace(doc.Content),
+			tokens: tokenizer.CountTokens(doc.Content),
+		})
+	}
+
+	return result
+}
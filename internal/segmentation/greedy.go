@@ -0,0 +1,18 @@
+package segmentation
+
+import (
+	"context"
+
+	"github.com/chrlesur/json-ld-converter/internal/parser"
+)
+
+// GreedySegmenter remplit chaque segment avec les blocs consécutifs du document
+// jusqu'à la taille maximale ; les blocs trop grands sont découpés par mots.
+type GreedySegmenter struct {
+	opts Options
+}
+
+func (s *GreedySegmenter) Segment(ctx context.Context, doc *parser.Document) ([]Segment, error) {
+	units := blocks(doc)
+	return finalize(pack(units, s.opts.MaxTokens, 0, "\n"), "greedy"), nil
+}
@@ -0,0 +1,72 @@
+package segmentation
+
+import (
+	"context"
+	"strings"
+
+	"github.com/chrlesur/json-ld-converter/internal/parser"
+)
+
+// HeadingSegmenter produit un segment par section du document, une section étant
+// l'ensemble des blocs placés sous un même chemin de titres. Les sections plus
+// grandes que la taille maximale sont découpées ; les sections consécutives plus
+// petites que TargetTokens sont regroupées.
+type HeadingSegmenter struct {
+	opts Options
+}
+
+func (s *HeadingSegmenter) Segment(ctx context.Context, doc *parser.Document) ([]Segment, error) {
+	maxTokens := s.opts.MaxTokens
+	target := s.opts.TargetTokens
+	if target > maxTokens {
+		target = maxTokens
+	}
+
+	var segments []Segment
+	var pending []unit
+	pendingTokens := 0
+
+	flushPending := func() {
+		if len(pending) > 0 {
+			segments = append(segments, pack(pending, maxTokens, 0, "\n")...)
+		}
+		pending = nil
+		pendingTokens = 0
+	}
+
+	for _, section := range sections(blocks(doc)) {
+		sectionTokens := tokensOf(section)
+
+		switch {
+		case sectionTokens > maxTokens:
+			flushPending()
+			segments = append(segments, pack(section, maxTokens, 0, "\n")...)
+		case len(pending) > 0 && pendingTokens+sectionTokens <= target:
+			pending = append(pending, section...)
+			pendingTokens += sectionTokens
+		default:
+			flushPending()
+			pending = section
+			pendingTokens = sectionTokens
+		}
+	}
+	flushPending()
+
+	return finalize(segments, "heading"), nil
+}
+
+// sections regroupe les blocs consécutifs partageant le même chapitre et le
+// même chemin de titres
+func sections(units []unit) [][]unit {
+	var result [][]unit
+	currentKey := ""
+	for i, u := range units {
+		key := u.chapter + "\x01" + strings.Join(u.path, "\x00")
+		if i == 0 || key != currentKey {
+			result = append(result, nil)
+			currentKey = key
+		}
+		result[len(result)-1] = append(result[len(result)-1], u)
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package segmentation
+
+import (
+	"context"
+
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+	"github.com/chrlesur/json-ld-converter/internal/parser"
+)
+
+// PageSegmenter produit un segment par page pour les documents paginés (PDF) ;
+// les pages trop grandes sont découpées.
+type PageSegmenter struct {
+	opts Options
+}
+
+func (s *PageSegmenter) Segment(ctx context.Context, doc *parser.Document) ([]Segment, error) {
+	units := blocks(doc)
+
+	paged := false
+	for _, u := range units {
+		if u.page != "" {
+			paged = true
+			break
+		}
+	}
+	if !paged {
+		logger.Warning("Document has no pages, falling back to greedy segmentation")
+		return (&GreedySegmenter{opts: s.opts}).Segment(ctx, doc)
+	}
+
+	var segments []Segment
+	var page []unit
+	for i, u := range units {
+		if i > 0 && u.page != units[i-1].page {
+			segments = append(segments, pack(page, s.opts.MaxTokens, 0, "\n")...)
+			page = nil
+		}
+		page = append(page, u)
+	}
+	if len(page) > 0 {
+		segments = append(segments, pack(page, s.opts.MaxTokens, 0, "\n")...)
+	}
+
+	return finalize(segments, "page"), nil
+}
@@ -1,104 +1,210 @@
 package segmentation
 
 import (
+	"context"
+	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/chrlesur/json-ld-converter/internal/llm"
 	"github.com/chrlesur/json-ld-converter/internal/parser"
-	"github.com/chrlesur/json-ld-converter/pkg/tokenizer"
 )
 
+// Segment est une portion de document convertie en un seul appel au convertisseur.
+// Metadata décrit sa position : "strategy", "index", "total", "start_token",
+// "end_token", "overlap_tokens", et selon la stratégie et le document
+// "heading_path", "page", "chapter", "start_time" et "end_time".
 type Segment struct {
 	Content  string
 	Metadata map[string]string
 }
 
-type Segmenter struct {
-	maxTokens       int
-	targetBatchSize int
+// Segmenter découpe un document en segments ; ctx borne les appels au moteur
+// d'embeddings de la stratégie semantic
+type Segmenter interface {
+	Segment(ctx context.Context, doc *parser.Document) ([]Segment, error)
 }
 
-func NewSegmenter(maxTokens, targetBatchSize int) *Segmenter {
-	return &Segmenter{
-		maxTokens:       maxTokens,
-		targetBatchSize: targetBatchSize,
+// Options paramètre les stratégies de segmentation
+type Options struct {
+	MaxTokens           int          // taille maximale d'un segment
+	Overlap             int          // tokens repris du segment précédent (stratégies sentence et window)
+	TargetTokens        int          // taille visée : les sections plus petites sont regroupées (stratégie heading)
+	Embedder            llm.Embedder // calcul des embeddings (stratégie semantic, TF-IDF local si nil)
+	SimilarityThreshold float64      // seuil de coupure (stratégie semantic, adaptatif si 0)
+}
+
+// NewSegmenter retourne la stratégie de segmentation demandée
+func NewSegmenter(strategy string, opts Options) (Segmenter, error) {
+	if opts.MaxTokens <= 0 {
+		return nil, fmt.Errorf("invalid segment size: %d", opts.MaxTokens)
+	}
+	if opts.Overlap < 0 || opts.Overlap >= opts.MaxTokens {
+		return nil, fmt.Errorf("overlap (%d) must be between 0 and the segment size (%d)", opts.Overlap, opts.MaxTokens)
+	}
+
+	switch strategy {
+	case "", "greedy":
+		return &GreedySegmenter{opts: opts}, nil
+	case "heading":
+		return &HeadingSegmenter{opts: opts}, nil
+	case "page":
+		return &PageSegmenter{opts: opts}, nil
+	case "sentence":
+		return &SentenceSegmenter{opts: opts}, nil
+	case "window":
+		return &WindowSegmenter{opts: opts}, nil
+	case "semantic":
+		return &SemanticSegmenter{opts: opts}, nil
+	default:
+		return nil, fmt.Errorf("unsupported segmentation strategy: %s", strategy)
 	}
 }
 
+// SegmentDocument découpe un document avec la stratégie par défaut
 func SegmentDocument(doc *parser.Document, maxTokens int) ([]Segment, error) {
-    var segments []Segment
-    var currentSegment strings.Builder
-    currentTokens := 0
-
-    for _, element := range doc.Structure {
-        elementTokens := tokenizer.CountTokens(element.Content)
-
-        if elementTokens > maxTokens {
-            // Si l'élément est trop grand, le diviser en sous-segments
-            subSegments := splitLargeElement(element, maxTokens)
-            segments = append(segments, subSegments...)
-        } else if currentTokens+elementTokens > maxTokens {
-            if currentSegment.Len() > 0 {
-                segments = append(segments, Segment{
-                    Content:  currentSegment.String(),
-                    Metadata: make(map[string]string),
-                })
-                currentSegment.Reset()
-                currentTokens = 0
-            }
-            currentSegment.WriteString(element.Content)
-            currentSegment.WriteString("\n")
-            currentTokens += elementTokens
-        } else {
-            currentSegment.WriteString(element.Content)
-            currentSegment.WriteString("\n")
-            currentTokens += elementTokens
-        }
-
-        if currentTokens >= maxTokens {
-            segments = append(segments, Segment{
-                Content:  currentSegment.String(),
-                Metadata: make(map[string]string),
-            })
-            currentSegment.Reset()
-            currentTokens = 0
-        }
-    }
-
-    if currentSegment.Len() > 0 {
-        segments = append(segments, Segment{
-            Content:  currentSegment.String(),
-            Metadata: make(map[string]string),
-        })
-    }
-
-    return segments, nil
+	return (&GreedySegmenter{opts: Options{MaxTokens: maxTokens}}).Segment(context.Background(), doc)
+}
+
+// unit est la plus petite portion de texte manipulée par une stratégie
+// (bloc, phrase ou mot), avec sa position dans le document
+type unit struct {
+	text    string
+	path    []string
+	page    string
+	chapter string
+	start   string
+	end     string
+	tokens  int
+	offset  int
+}
+
+// pack regroupe des unités consécutives en segments d'au plus maxTokens tokens.
+// Les dernières unités d'un segment totalisant au plus overlap tokens sont
+// reprises au début du segment suivant ; une unité plus grande qu'un segment est
+// coupée par mots pour compléter le segment courant. separator joint les unités.
+func pack(units []unit, maxTokens, overlap int, separator string) []Segment {
+	var segments []Segment
+	var current []unit
+	currentTokens := 0
+	overlapCount := 0
+
+	flush := func() {
+		if len(current) == overlapCount {
+			return
+		}
+		segments = append(segments, newSegment(current, separator, overlapCount))
+
+		// Reprise des dernières unités pour le recouvrement
+		var carried []unit
+		carriedTokens := 0
+		for i := len(current) - 1; i >= 0 && overlap > 0; i-- {
+			if carriedTokens+current[i].tokens > overlap {
+				break
+			}
+			carried = append([]unit{current[i]}, carried...)
+			carriedTokens += current[i].tokens
+		}
+		current = carried
+		currentTokens = carriedTokens
+		overlapCount = len(carried)
+	}
+
+	for _, u := range units {
+		for u.tokens > maxTokens {
+			if currentTokens == maxTokens {
+				flush()
+			}
+			head, rest := splitUnit(u, maxTokens-currentTokens)
+			current = append(current, head)
+			currentTokens += head.tokens
+			flush()
+			u = rest
+		}
+
+		if currentTokens+u.tokens > maxTokens && len(current) > 0 {
+			flush()
+			// Un recouvrement qui ne laisse pas la place à l'unité suivante est abandonné
+			if currentTokens+u.tokens > maxTokens {
+				current = nil
+				currentTokens = 0
+				overlapCount = 0
+			}
+		}
+		current = append(current, u)
+		currentTokens += u.tokens
+	}
+	flush()
+
+	return segments
 }
 
-func splitLargeElement(element parser.DocumentElement, maxTokens int) []Segment {
-    var segments []Segment
-    content := element.Content
-    for len(content) > 0 {
-        tokenCount := 0
-        var segmentBuilder strings.Builder
-        words := strings.Fields(content)
-
-        for _, word := range words {
-            wordTokens := tokenizer.CountTokens(word)
-            if tokenCount+wordTokens > maxTokens {
-                break
-            }
-            segmentBuilder.WriteString(word)
-            segmentBuilder.WriteString(" ")
-            tokenCount += wordTokens
-        }
-
-        segments = append(segments, Segment{
-            Content:  segmentBuilder.String(),
-            Metadata: map[string]string{"type": element.Type},
-        })
-
-        content = strings.TrimSpace(content[len(segmentBuilder.String()):])
-    }
-
-    return segments
+func newSegment(units []unit, separator string, overlapCount int) Segment {
+	texts := make([]string, len(units))
+	tokens := 0
+	for i, u := range units {
+		texts[i] = u.text
+		tokens += u.tokens
+	}
+
+	first := units[overlapCount]
+	last := units[len(units)-1]
+	metadata := map[string]string{
+		"start_token":    strconv.Itoa(first.offset),
+		"end_token":      strconv.Itoa(last.offset + last.tokens),
+		"overlap_tokens": strconv.Itoa(tokensOf(units[:overlapCount])),
+	}
+	if len(first.path) > 0 {
+		metadata["heading_path"] = strings.Join(first.path, " > ")
+	}
+	if first.page != "" {
+		metadata["page"] = first.page
+	}
+	if first.chapter != "" {
+		metadata["chapter"] = first.chapter
+	}
+	if first.start != "" {
+		metadata["start_time"] = first.start
+	}
+	if last.end != "" {
+		metadata["end_time"] = last.end
+	}
+
+	return Segment{
+		Content:  strings.Join(texts, separator),
+		Metadata: metadata,
+	}
+}
+
+func tokensOf(units []unit) int {
+	total := 0
+	for _, u := range units {
+		total += u.tokens
+	}
+	return total
+}
+
+// splitUnit coupe une unité après ses n premiers mots
+func splitUnit(u unit, n int) (unit, unit) {
+	words := strings.Fields(u.text)
+	head, rest := u, u
+	head.text = strings.Join(words[:n], " ")
+	head.tokens = n
+	rest.text = strings.Join(words[n:], " ")
+	rest.tokens = len(words) - n
+	rest.offset = u.offset + n
+	return head, rest
+}
+
+// finalize numérote les segments et enregistre la stratégie utilisée
+func finalize(segments []Segment, strategy string) []Segment {
+	for i := range segments {
+		if segments[i].Metadata == nil {
+			segments[i].Metadata = make(map[string]string)
+		}
+		segments[i].Metadata["strategy"] = strategy
+		segments[i].Metadata["index"] = strconv.Itoa(i + 1)
+		segments[i].Metadata["total"] = strconv.Itoa(len(segments))
+	}
+	return segments
 }
@@ -0,0 +1,185 @@
+package segmentation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chrlesur/json-ld-converter/internal/parser"
+)
+
+func testDocument() *parser.Document {
+	return &parser.Document{
+		Structure: []parser.DocumentElement{
+			{Type: "heading", Content: "Introduction", Attributes: map[string]string{"level": "1"}},
+			{Type: "paragraph", Content: "Première phrase de l'introduction. Deuxième phrase ici."},
+			{Type: "heading", Content: "Contexte", Attributes: map[string]string{"level": "2"}},
+			{Type: "paragraph", Content: strings.Repeat("mot ", 30)},
+			{Type: "heading", Content: "Conclusion", Attributes: map[string]string{"level": "1"}},
+			{Type: "paragraph", Content: "Fin du document."},
+		},
+	}
+}
+
+func TestNewSegmenterRejectsInvalidOptions(t *testing.T) {
+	if _, err := NewSegmenter("unknown", Options{MaxTokens: 10}); err == nil {
+		t.Error("Expected error for unknown strategy")
+	}
+	if _, err := NewSegmenter("sentence", Options{MaxTokens: 10, Overlap: 10}); err == nil {
+		t.Error("Expected error when overlap is not smaller than segment size")
+	}
+}
+
+func TestHeadingSegmenter(t *testing.T) {
+	s, err := NewSegmenter("heading", Options{MaxTokens: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	segments, err := s.Segment(context.Background(), testDocument())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Introduction, Contexte (32 tokens découpés en 2), Conclusion
+	if len(segments) != 4 {
+		t.Fatalf("Expected 4 segments, got %d", len(segments))
+	}
+	if segments[0].Metadata["heading_path"] != "Introduction" {
+		t.Errorf("Unexpected heading path: %q", segments[0].Metadata["heading_path"])
+	}
+	if segments[1].Metadata["heading_path"] != "Introduction > Contexte" {
+		t.Errorf("Unexpected heading path: %q", segments[1].Metadata["heading_path"])
+	}
+	if segments[3].Metadata["heading_path"] != "Conclusion" || segments[3].Metadata["index"] != "4" || segments[3].Metadata["total"] != "4" {
+		t.Errorf("Unexpected metadata for last segment: %v", segments[3].Metadata)
+	}
+	for _, seg := range segments {
+		if n := len(strings.Fields(seg.Content)); n > 20 {
+			t.Errorf("Segment exceeds max tokens: %d", n)
+		}
+	}
+}
+
+func TestHeadingSegmenterMergesSmallSections(t *testing.T) {
+	s, _ := NewSegmenter("heading", Options{MaxTokens: 50, TargetTokens: 50})
+	segments, _ := s.Segment(context.Background(), testDocument())
+	if len(segments) != 1 {
+		t.Errorf("Expected small sections to be merged into 1 segment, got %d", len(segments))
+	}
+}
+
+func TestSentenceSegmenterOverlap(t *testing.T) {
+	doc := &parser.Document{Structure: []parser.DocumentElement{
+		{Type: "paragraph", Content: "Un deux trois. Quatre cinq six. Sept huit neuf. Dix onze douze."},
+	}}
+	s, _ := NewSegmenter("sentence", Options{MaxTokens: 6, Overlap: 3})
+	segments, _ := s.Segment(context.Background(), doc)
+
+	if len(segments) != 3 {
+		t.Fatalf("Expected 3 segments, got %d: %+v", len(segments), segments)
+	}
+	if !strings.HasPrefix(segments[1].Content, "Quatre cinq six.") {
+		t.Errorf("Expected second segment to start with overlapping sentence, got %q", segments[1].Content)
+	}
+	if segments[1].Metadata["overlap_tokens"] != "3" {
+		t.Errorf("Expected 3 overlap tokens, got %s", segments[1].Metadata["overlap_tokens"])
+	}
+}
+
+func TestWindowSegmenter(t *testing.T) {
+	doc := &parser.Document{Content: strings.Repeat("a ", 25)}
+	s, _ := NewSegmenter("window", Options{MaxTokens: 10, Overlap: 2})
+	segments, _ := s.Segment(context.Background(), doc)
+
+	// Fenêtres : 0-10, 8-18, 16-25
+	if len(segments) != 3 {
+		t.Fatalf("Expected 3 windows, got %d", len(segments))
+	}
+	if segments[1].Metadata["start_token"] != "10" || segments[2].Metadata["end_token"] != "25" {
+		t.Errorf("Unexpected window positions: %v / %v", segments[1].Metadata, segments[2].Metadata)
+	}
+}
+
+func TestPageSegmenter(t *testing.T) {
+	doc := &parser.Document{Structure: []parser.DocumentElement{
+		{Type: "page", Content: "Page un."},
+		{Type: "page", Content: "Page deux."},
+	}}
+	s, _ := NewSegmenter("page", Options{MaxTokens: 100})
+	segments, _ := s.Segment(context.Background(), doc)
+	if len(segments) != 2 || segments[1].Metadata["page"] != "2" {
+		t.Errorf("Expected one segment per page, got %+v", segments)
+	}
+}
+
+func TestChapterMetadata(t *testing.T) {
+	doc := &parser.Document{Structure: []parser.DocumentElement{
+		{Type: "chapter", Attributes: map[string]string{"title": "Prologue"}, Children: []parser.DocumentElement{
+			{Type: "paragraph", Content: "Au commencement était le Verbe."},
+		}},
+		{Type: "chapter", Attributes: map[string]string{"title": "Cana"}, Children: []parser.DocumentElement{
+			{Type: "paragraph", Content: "Il y eut des noces."},
+		}},
+	}}
+	s, _ := NewSegmenter("heading", Options{MaxTokens: 100})
+	segments, _ := s.Segment(context.Background(), doc)
+	if len(segments) != 2 || segments[0].Metadata["chapter"] != "Prologue" || segments[1].Metadata["chapter"] != "Cana" {
+		t.Errorf("Expected one segment per chapter, got %+v", segments)
+	}
+}
+
+func TestCueTimestamps(t *testing.T) {
+	doc := &parser.Document{Structure: []parser.DocumentElement{
+		{Type: "cue", Content: "Un deux trois.", Attributes: map[string]string{"start": "00:00:01.000", "end": "00:00:02.000"}},
+		{Type: "cue", Content: "Quatre cinq six.", Attributes: map[string]string{"start": "00:00:02.000", "end": "00:00:04.000"}},
+		{Type: "cue", Content: "Sept huit neuf.", Attributes: map[string]string{"start": "00:00:04.000", "end": "00:00:07.000"}},
+	}}
+	segments, _ := SegmentDocument(doc, 6)
+	if len(segments) != 2 {
+		t.Fatalf("Expected 2 segments, got %d", len(segments))
+	}
+	if segments[0].Metadata["start_time"] != "00:00:01.000" || segments[0].Metadata["end_time"] != "00:00:04.000" || segments[1].Metadata["start_time"] != "00:00:04.000" {
+		t.Errorf("Unexpected timestamps: %v / %v", segments[0].Metadata, segments[1].Metadata)
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	sentences := splitSentences("Il est 3.5 fois plus grand. « Vraiment ? » Oui ! 2024 fut une année.")
+	expected := []string{"Il est 3.5 fois plus grand.", "« Vraiment ? »", "Oui !", "2024 fut une année."}
+	if len(sentences) != len(expected) {
+		t.Fatalf("Expected %d sentences, got %d: %q", len(expected), len(sentences), sentences)
+	}
+	for i := range expected {
+		if sentences[i] != expected[i] {
+			t.Errorf("Sentence %d: expected %q, got %q", i, expected[i], sentences[i])
+		}
+	}
+}
+
+func TestSemanticSegmenterSplitsAtTopicChange(t *testing.T) {
+	var content []string
+	for i := 0; i < 6; i++ {
+		content = append(content, "Le tribunal applique la jurisprudence de la cour de cassation.")
+	}
+	for i := 0; i < 6; i++ {
+		content = append(content, "Le jardinier arrose les tomates et les courgettes du potager.")
+	}
+	doc := &parser.Document{Structure: []parser.DocumentElement{
+		{Type: "paragraph", Content: strings.Join(content, " ")},
+	}}
+
+	s, _ := NewSegmenter("semantic", Options{MaxTokens: 200})
+	segments, err := s.Segment(context.Background(), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("Expected a cut at the topic change, got %d segments", len(segments))
+	}
+	if strings.Contains(segments[0].Content, "jardinier") || strings.Contains(segments[1].Content, "tribunal") {
+		t.Errorf("Cut is not at the topic boundary: %q", segments[0].Content)
+	}
+	if segments[1].Metadata["strategy"] != "semantic" || segments[1].Metadata["boundary_similarity"] == "" {
+		t.Errorf("Unexpected metadata: %v", segments[1].Metadata)
+	}
+}
@@ -0,0 +1,166 @@
+package segmentation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/chrlesur/json-ld-converter/internal/logger"
+	"github.com/chrlesur/json-ld-converter/internal/parser"
+)
+
+// semanticWindow est le nombre de phrases comparées de part et d'autre d'une frontière
+const semanticWindow = 3
+
+// SemanticSegmenter coupe le texte aux changements de sujet : les phrases sont
+// projetées par l'Embedder et une frontière est placée aux minima locaux de la
+// similarité entre les phrases qui précèdent et celles qui suivent, lorsqu'elle
+// chute sous le seuil. Lorsqu'un segment atteint la taille maximale, il est
+// coupé à la frontière la moins similaire qu'il contient.
+type SemanticSegmenter struct {
+	opts Options
+}
+
+func (s *SemanticSegmenter) Segment(ctx context.Context, doc *parser.Document) ([]Segment, error) {
+	units := sentenceUnits(doc)
+	if len(units) < 2 {
+		return finalize(pack(units, s.opts.MaxTokens, 0, " "), "semantic"), nil
+	}
+
+	vectors, err := s.embed(ctx, units)
+	if err != nil {
+		return nil, err
+	}
+
+	similarities := boundarySimilarities(vectors, semanticWindow)
+	threshold := s.opts.SimilarityThreshold
+	if threshold <= 0 {
+		threshold = adaptiveThreshold(similarities)
+	}
+	logger.Debug(fmt.Sprintf("Semantic segmentation of %d sentences with similarity threshold %.3f", len(units), threshold))
+
+	minTokens := s.opts.MaxTokens / 4
+	var segments []Segment
+	emit := func(from, to int) {
+		group := pack(units[from:to], s.opts.MaxTokens, 0, " ")
+		if from > 0 {
+			for i := range group {
+				group[i].Metadata["boundary_similarity"] = strconv.FormatFloat(similarities[from], 'f', 3, 64)
+			}
+		}
+		segments = append(segments, group...)
+	}
+
+	start, tokens := 0, 0
+	for i, u := range units {
+		switch {
+		case i > start && tokens+u.tokens > s.opts.MaxTokens:
+			// Segment plein : coupure à la frontière la moins similaire
+			cut := i
+			for j := i - 1; j > start; j-- {
+				if tokensOf(units[start:j]) < minTokens {
+					break
+				}
+				if similarities[j] < similarities[cut] {
+					cut = j
+				}
+			}
+			emit(start, cut)
+			start = cut
+			tokens = tokensOf(units[cut:i])
+		case i > start && similarities[i] < threshold && isLocalMinimum(similarities, i) && tokens >= minTokens:
+			emit(start, i)
+			start = i
+			tokens = 0
+		}
+		tokens += u.tokens
+	}
+	emit(start, len(units))
+
+	return finalize(segments, "semantic"), nil
+}
+
+// embed calcule les vecteurs des phrases, avec repli sur TF-IDF si l'Embedder
+// configuré échoue (moteur injoignable par exemple)
+func (s *SemanticSegmenter) embed(ctx context.Context, units []unit) ([][]float64, error) {
+	texts := make([]string, len(units))
+	for i, u := range units {
+		texts[i] = u.text
+	}
+
+	if s.opts.Embedder != nil {
+		vectors, err := s.opts.Embedder.Embed(ctx, texts)
+		if err == nil && len(vectors) == len(texts) {
+			return vectors, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		logger.Warning(fmt.Sprintf("Embedding engine failed (%v), falling back to local TF-IDF", err))
+	}
+	return NewTFIDFEmbedder().Embed(ctx, texts)
+}
+
+// boundarySimilarities calcule, pour chaque frontière i (entre les phrases i-1 et i),
+// la similarité cosinus entre la moyenne des window phrases précédentes et celle
+// des window phrases suivantes. L'indice 0 n'est pas une frontière.
+func boundarySimilarities(vectors [][]float64, window int) []float64 {
+	similarities := make([]float64, len(vectors))
+	similarities[0] = 1
+	for i := 1; i < len(vectors); i++ {
+		before := mean(vectors[max(0, i-window):i])
+		after := mean(vectors[i:min(len(vectors), i+window)])
+		similarities[i] = cosine(before, after)
+	}
+	return similarities
+}
+
+// isLocalMinimum indique si la frontière i est moins similaire que ses voisines
+func isLocalMinimum(similarities []float64, i int) bool {
+	if i > 1 && similarities[i-1] < similarities[i] {
+		return false
+	}
+	return i+1 >= len(similarities) || similarities[i] <= similarities[i+1]
+}
+
+// adaptiveThreshold place le seuil à un écart-type sous la similarité moyenne
+func adaptiveThreshold(similarities []float64) float64 {
+	values := similarities[1:]
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	avg := sum / float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - avg) * (v - avg)
+	}
+	return avg - math.Sqrt(variance/float64(len(values)))
+}
+
+func mean(vectors [][]float64) []float64 {
+	result := make([]float64, len(vectors[0]))
+	for _, v := range vectors {
+		for i, x := range v {
+			result[i] += x
+		}
+	}
+	for i := range result {
+		result[i] /= float64(len(vectors))
+	}
+	return result
+}
+
+func cosine(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,85 @@
+package segmentation
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/chrlesur/json-ld-converter/internal/parser"
+	"github.com/chrlesur/json-ld-converter/pkg/tokenizer"
+)
+
+// SentenceSegmenter regroupe des phrases entières jusqu'à la taille maximale ;
+// les dernières phrases d'un segment (au plus Overlap tokens) sont reprises au
+// début du segment suivant.
+type SentenceSegmenter struct {
+	opts Options
+}
+
+func (s *SentenceSegmenter) Segment(ctx context.Context, doc *parser.Document) ([]Segment, error) {
+	units := sentenceUnits(doc)
+	return finalize(pack(units, s.opts.MaxTokens, s.opts.Overlap, " "), "sentence"), nil
+}
+
+// sentenceUnits découpe chaque bloc du document en phrases
+func sentenceUnits(doc *parser.Document) []unit {
+	var units []unit
+	for _, block := range blocks(doc) {
+		offset := block.offset
+		for _, sentence := range splitSentences(block.text) {
+			tokens := tokenizer.CountTokens(sentence)
+			units = append(units, unit{
+				text:    sentence,
+				path:    block.path,
+				page:    block.page,
+				chapter: block.chapter,
+				start:   block.start,
+				end:     block.end,
+				tokens:  tokens,
+				offset:  offset,
+			})
+			offset += tokens
+		}
+	}
+	return units
+}
+
+// splitSentences coupe un texte après une ponctuation finale suivie d'une espace et
+// d'une majuscule, d'un chiffre ou d'un guillemet ouvrant
+func splitSentences(text string) []string {
+	var sentences []string
+	runes := []rune(text)
+	start := 0
+
+	for i := 0; i < len(runes); i++ {
+		if !strings.ContainsRune(".!?…", runes[i]) {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && strings.ContainsRune(".!?…»\")", runes[j]) {
+			j++
+		}
+		// Guillemet fermant précédé d'une espace (typographie française)
+		if l := j; l+1 < len(runes) && unicode.IsSpace(runes[l]) && runes[l+1] == '»' {
+			j = l + 2
+		}
+		k := j
+		for k < len(runes) && unicode.IsSpace(runes[k]) {
+			k++
+		}
+		if k == j || k == len(runes) {
+			continue
+		}
+		if next := runes[k]; unicode.IsUpper(next) || unicode.IsDigit(next) || strings.ContainsRune("«\"—-(", next) {
+			if sentence := strings.TrimSpace(string(runes[start:j])); sentence != "" {
+				sentences = append(sentences, sentence)
+			}
+			start = k
+			i = k - 1
+		}
+	}
+	if sentence := strings.TrimSpace(string(runes[start:])); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
@@ -0,0 +1,78 @@
+package segmentation
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// tfidfDimensions est la taille des vecteurs TF-IDF (les termes sont hachés)
+const tfidfDimensions = 1024
+
+// stopWords sont ignorés par le calcul TF-IDF
+var stopWords = map[string]bool{
+	"les": true, "des": true, "une": true, "est": true, "que": true, "qui": true, "dans": true,
+	"par": true, "pour": true, "pas": true, "sur": true, "plus": true, "avec": true, "son": true,
+	"ses": true, "aux": true, "ont": true, "été": true, "mais": true, "ce": true, "cette": true,
+	"elle": true, "nous": true, "vous": true, "ils": true, "sont": true, "fait": true, "tout": true,
+	"the": true, "and": true, "for": true, "that": true, "with": true, "this": true, "are": true,
+	"was": true, "from": true, "have": true, "not": true, "but": true, "they": true, "his": true,
+}
+
+// TFIDFEmbedder calcule localement des vecteurs TF-IDF, sans modèle externe.
+// L'IDF est calculé sur l'ensemble des textes d'un même appel.
+type TFIDFEmbedder struct{}
+
+func NewTFIDFEmbedder() *TFIDFEmbedder {
+	return &TFIDFEmbedder{}
+}
+
+func (e *TFIDFEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	termCounts := make([]map[int]float64, len(texts))
+	documentFrequency := make(map[int]int)
+
+	for i, text := range texts {
+		counts := make(map[int]float64)
+		for _, term := range terms(text) {
+			counts[hashTerm(term)]++
+		}
+		for bucket := range counts {
+			documentFrequency[bucket]++
+		}
+		termCounts[i] = counts
+	}
+
+	n := float64(len(texts))
+	vectors := make([][]float64, len(texts))
+	for i, counts := range termCounts {
+		vector := make([]float64, tfidfDimensions)
+		for bucket, count := range counts {
+			idf := math.Log(n/float64(documentFrequency[bucket])) + 1
+			vector[bucket] = (1 + math.Log(count)) * idf
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+// terms extrait les mots significatifs d'un texte, en minuscules
+func terms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	result := words[:0]
+	for _, w := range words {
+		if len([]rune(w)) > 2 && !stopWords[w] {
+			result = append(result, w)
+		}
+	}
+	return result
+}
+
+func hashTerm(term string) int {
+	h := fnv.New32a()
+	h.Write([]byte(term))
+	return int(h.Sum32() % tfidfDimensions)
+}
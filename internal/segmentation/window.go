@@ -0,0 +1,33 @@
+package segmentation
+
+import (
+	"context"
+	"strings"
+
+	"github.com/chrlesur/json-ld-converter/internal/parser"
+)
+
+// WindowSegmenter découpe le texte en fenêtres fixes de MaxTokens tokens, chaque
+// fenêtre reprenant les Overlap derniers tokens de la précédente.
+type WindowSegmenter struct {
+	opts Options
+}
+
+func (s *WindowSegmenter) Segment(ctx context.Context, doc *parser.Document) ([]Segment, error) {
+	var units []unit
+	for _, block := range blocks(doc) {
+		for i, word := range strings.Fields(block.text) {
+			units = append(units, unit{
+				text:    word,
+				path:    block.path,
+				page:    block.page,
+				chapter: block.chapter,
+				start:   block.start,
+				end:     block.end,
+				tokens:  1,
+				offset:  block.offset + i,
+			})
+		}
+	}
+	return finalize(pack(units, s.opts.MaxTokens, s.opts.Overlap, " "), "window"), nil
+}
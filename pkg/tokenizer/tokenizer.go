@@ -3,14 +3,27 @@ package tokenizer
 import (
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 func CountTokens(text string) int {
 	return len(strings.Fields(text))
 }
 
+// EstimateTokens donne une estimation prudente du nombre de tokens BPE d'un texte,
+// à utiliser pour comparer un prompt aux limites d'un modèle. CountTokens compte
+// des mots et sous-estime nettement les tokens réellement facturés.
+func EstimateTokens(text string) int {
+	byWords := CountTokens(text) * 4 / 3
+	byChars := utf8.RuneCountInString(text) / 4
+	if byChars > byWords {
+		return byChars
+	}
+	return byWords
+}
+
 func SplitIntoTokens(text string) []string {
 	return strings.FieldsFunc(text, func(r rune) bool {
 		return unicode.IsSpace(r) || unicode.IsPunct(r)
 	})
-}
\ No newline at end of file
+}